@@ -0,0 +1,261 @@
+// Package certgen creates PKCS#10 certificate signing requests and
+// self-signed certificates for a crypto.Signer, such as an
+// enclavekey.Key, whose private key never leaves the Secure Enclave.
+//
+// The package is pure Go, so requests and certificates can be created
+// and verified with software keys on any platform.
+package certgen
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// DefaultValidity is the validity of a self-signed
+// certificate if Template.NotAfter is not set.
+const DefaultValidity = 365 * 24 * time.Hour
+
+// Template describes the subject and extensions of a
+// certificate signing request or self-signed certificate.
+type Template struct {
+	Subject pkix.Name
+
+	// DNSNames, EmailAddresses, IPAddresses and URIs
+	// are the subject alternative names.
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// KeyUsage and ExtKeyUsage are omitted if they are zero.
+	KeyUsage    x509.KeyUsage
+	ExtKeyUsage []x509.ExtKeyUsage
+
+	// ExtraExtensions are added to the request or certificate as they
+	// are. They override extensions with the same OID, such as those
+	// created from KeyUsage, ExtKeyUsage and the subject alternative names.
+	ExtraExtensions []pkix.Extension
+
+	// NotBefore and NotAfter are the validity period of a
+	// self-signed certificate. They default to the current time
+	// and DefaultValidity after NotBefore. They are not part of a
+	// request: the certificate authority chooses the validity.
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// IsCA marks a self-signed certificate as a certificate
+	// authority, such as the root of a development CA.
+	IsCA bool
+
+	// MaxPathLen and MaxPathLenZero limit the intermediate
+	// certificates below a CA, as in x509.Certificate.
+	MaxPathLen     int
+	MaxPathLenZero bool
+}
+
+var (
+	oidExtensionKeyUsage    = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+)
+
+// extKeyUsageOIDs maps extended key usages to their OIDs,
+// as defined by RFC 5280 section 4.2.1.12.
+var extKeyUsageOIDs = map[x509.ExtKeyUsage]asn1.ObjectIdentifier{
+	x509.ExtKeyUsageAny:             {2, 5, 29, 37, 0},
+	x509.ExtKeyUsageServerAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 1},
+	x509.ExtKeyUsageClientAuth:      {1, 3, 6, 1, 5, 5, 7, 3, 2},
+	x509.ExtKeyUsageCodeSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 3},
+	x509.ExtKeyUsageEmailProtection: {1, 3, 6, 1, 5, 5, 7, 3, 4},
+	x509.ExtKeyUsageTimeStamping:    {1, 3, 6, 1, 5, 5, 7, 3, 8},
+	x509.ExtKeyUsageOCSPSigning:     {1, 3, 6, 1, 5, 5, 7, 3, 9},
+}
+
+// CreateCertificateRequest returns a certificate signing request for
+// the public key of signer, signed by it. The request is parsed and
+// its signature checked, so that a signer returning signatures in an
+// encoding other than ASN.1 DER is reported here rather than by the CA.
+//
+// Unlike crypto/x509, the key usages of the template are requested
+// with extensions, as certificate authorities such as step-ca and
+// Vault can be configured to honour them.
+func CreateCertificateRequest(signer crypto.Signer, t *Template) (*x509.CertificateRequest, error) {
+	extensions, err := requestedExtensions(t)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:         t.Subject,
+		DNSNames:        t.DNSNames,
+		EmailAddresses:  t.EmailAddresses,
+		IPAddresses:     t.IPAddresses,
+		URIs:            t.URIs,
+		ExtraExtensions: extensions,
+	}, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("checking the signature of the certificate request: %w", err)
+	}
+	return csr, nil
+}
+
+// CreateSelfSigned returns a certificate for the public key of signer,
+// signed by it, with a random serial number and a subject key ID
+// computed as RFC 5280 section 4.2.1.2 describes.
+func CreateSelfSigned(signer crypto.Signer, t *Template) (*x509.Certificate, error) {
+	return createSelfSigned(rand.Reader, time.Now(), signer, t)
+}
+
+func createSelfSigned(rand io.Reader, now time.Time, signer crypto.Signer, t *Template) (*x509.Certificate, error) {
+	pub := signer.Public()
+	keyID, err := pubkey.ApplicationLabel(pub)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := RandomSerial(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := t.NotBefore
+	if notBefore.IsZero() {
+		notBefore = now
+	}
+	notAfter := t.NotAfter
+	if notAfter.IsZero() {
+		notAfter = notBefore.Add(DefaultValidity)
+	}
+	if !notAfter.After(notBefore) {
+		return nil, errors.New("certificate expires before it becomes valid")
+	}
+
+	for _, u := range t.ExtKeyUsage {
+		if _, ok := extKeyUsageOIDs[u]; !ok {
+			return nil, fmt.Errorf("unsupported extended key usage %d", u)
+		}
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               t.Subject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              t.KeyUsage,
+		ExtKeyUsage:           t.ExtKeyUsage,
+		DNSNames:              t.DNSNames,
+		EmailAddresses:        t.EmailAddresses,
+		IPAddresses:           t.IPAddresses,
+		URIs:                  t.URIs,
+		ExtraExtensions:       t.ExtraExtensions,
+		BasicConstraintsValid: true,
+		IsCA:                  t.IsCA,
+		MaxPathLen:            t.MaxPathLen,
+		MaxPathLenZero:        t.MaxPathLenZero,
+		// the SHA-1 hash of the subjectPublicKey bit string, which
+		// holds the same encoding of the key as its application label.
+		SubjectKeyId: keyID,
+	}
+
+	// crypto/x509 checks the signature of the certificate it creates.
+	der, err := x509.CreateCertificate(rand, template, template, pub, signer)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// requestedExtensions returns the extensions of a certificate signing
+// request created from t, other than the subject alternative names.
+func requestedExtensions(t *Template) ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+
+	if t.KeyUsage != 0 {
+		ext, err := marshalKeyUsage(t.KeyUsage)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, ext)
+	}
+
+	if len(t.ExtKeyUsage) > 0 {
+		var oids []asn1.ObjectIdentifier
+		for _, u := range t.ExtKeyUsage {
+			oid, ok := extKeyUsageOIDs[u]
+			if !ok {
+				return nil, fmt.Errorf("unsupported extended key usage %d", u)
+			}
+			oids = append(oids, oid)
+		}
+		value, err := asn1.Marshal(oids)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionExtKeyUsage, Value: value})
+	}
+
+	for _, extra := range t.ExtraExtensions {
+		extensions = removeExtension(extensions, extra.Id)
+	}
+	return append(extensions, t.ExtraExtensions...), nil
+}
+
+// marshalKeyUsage returns the critical key usage extension, a bit
+// string in which bit 0, digitalSignature, is the most significant.
+func marshalKeyUsage(ku x509.KeyUsage) (pkix.Extension, error) {
+	var b [2]byte
+	bitLength := 0
+	for i := 0; i < 9; i++ {
+		if ku&(1<<i) != 0 {
+			b[i/8] |= 0x80 >> (i % 8)
+			bitLength = i + 1
+		}
+	}
+	value, err := asn1.Marshal(asn1.BitString{Bytes: b[:(bitLength+7)/8], BitLength: bitLength})
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value}, nil
+}
+
+func removeExtension(extensions []pkix.Extension, id asn1.ObjectIdentifier) []pkix.Extension {
+	var out []pkix.Extension
+	for _, ext := range extensions {
+		if !ext.Id.Equal(id) {
+			out = append(out, ext)
+		}
+	}
+	return out
+}
+
+// RandomSerial returns a random positive 128-bit serial number
+// read from rand, as recommended for certificates by RFC 5280.
+func RandomSerial(rand io.Reader) (*big.Int, error) {
+	b := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(rand, b); err != nil {
+			return nil, err
+		}
+		if serial := new(big.Int).SetBytes(b); serial.Sign() > 0 {
+			return serial, nil
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package certgen
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/common-fate/go-apple-security/ecdsasig"
+)
+
+func newSigners(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"P-256": p256, "P-384": p384, "RSA": rsaKey}
+}
+
+func testTemplate(t *testing.T) *Template {
+	t.Helper()
+	spiffe, err := url.Parse("spiffe://example.com/device/1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Template{
+		Subject:        pkix.Name{CommonName: "device-1234", Organization: []string{"Example"}},
+		DNSNames:       []string{"device-1234.example.com"},
+		EmailAddresses: []string{"alice@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1").To4()},
+		URIs:           []*url.URL{spiffe},
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+}
+
+func extension(extensions []pkix.Extension, id asn1.ObjectIdentifier) *pkix.Extension {
+	for _, ext := range extensions {
+		if ext.Id.Equal(id) {
+			return &ext
+		}
+	}
+	return nil
+}
+
+func TestCreateCertificateRequest(t *testing.T) {
+	tmpl := testTemplate(t)
+
+	for name, signer := range newSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			csr, err := CreateCertificateRequest(signer, tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if csr.Subject.CommonName != tmpl.Subject.CommonName {
+				t.Errorf("CommonName = %q", csr.Subject.CommonName)
+			}
+			if !reflect.DeepEqual(csr.DNSNames, tmpl.DNSNames) ||
+				!reflect.DeepEqual(csr.EmailAddresses, tmpl.EmailAddresses) ||
+				!reflect.DeepEqual(csr.IPAddresses, tmpl.IPAddresses) ||
+				len(csr.URIs) != 1 || csr.URIs[0].String() != tmpl.URIs[0].String() {
+				t.Errorf("subject alternative names do not match the template")
+			}
+			if !reflect.DeepEqual(csr.PublicKey, signer.Public()) {
+				t.Errorf("PublicKey does not match the signer")
+			}
+
+			// the requested key usages are encoded as crypto/x509 encodes them in certificates.
+			cert, err := CreateSelfSigned(signer, tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []asn1.ObjectIdentifier{oidExtensionKeyUsage, oidExtensionExtKeyUsage} {
+				got, want := extension(csr.Extensions, id), extension(cert.Extensions, id)
+				if got == nil || want == nil {
+					t.Fatalf("extension %s is missing", id)
+				}
+				if got.Critical != want.Critical || !bytes.Equal(got.Value, want.Value) {
+					t.Errorf("extension %s = %+v, want %+v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCertificateRequest_ExtraExtensions(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	override := pkix.Extension{Id: oidExtensionKeyUsage, Value: []byte{0x03, 0x02, 0x07, 0x80}}
+	custom := pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55555, 1}, Value: []byte{0x05, 0x00}}
+
+	tmpl := &Template{
+		Subject:         pkix.Name{CommonName: "device"},
+		KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtraExtensions: []pkix.Extension{override, custom},
+	}
+	csr, err := CreateCertificateRequest(key, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keyUsages int
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oidExtensionKeyUsage) {
+			keyUsages++
+		}
+	}
+	if keyUsages != 1 {
+		t.Errorf("got %d key usage extensions, want 1", keyUsages)
+	}
+	if got := extension(csr.Extensions, oidExtensionKeyUsage); got == nil || !reflect.DeepEqual(*got, override) {
+		t.Errorf("key usage extension = %+v, want %+v", got, override)
+	}
+	if got := extension(csr.Extensions, custom.Id); got == nil || !reflect.DeepEqual(*got, custom) {
+		t.Errorf("custom extension = %+v, want %+v", got, custom)
+	}
+}
+
+// p1363Signer returns IEEE P1363 signatures, like an
+// enclavekey.Key with its SignatureEncoding set to SignatureP1363.
+type p1363Signer struct{ *ecdsa.PrivateKey }
+
+func (s p1363Signer) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	der, err := s.PrivateKey.Sign(rand, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ecdsasig.ToP1363(der, s.Curve, nil)
+}
+
+func TestCreate_Errors(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		signer crypto.Signer
+		tmpl   *Template
+	}{
+		{name: "P1363 signatures", signer: p1363Signer{key}, tmpl: &Template{}},
+		{name: "unsupported extended key usage", signer: key, tmpl: &Template{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageMicrosoftKernelCodeSigning}}},
+		{name: "expires before valid", signer: key, tmpl: &Template{NotBefore: now, NotAfter: now.Add(-time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CreateSelfSigned(tt.signer, tt.tmpl); err == nil {
+				t.Error("CreateSelfSigned() succeeded, want an error")
+			}
+			if tt.tmpl.NotAfter.IsZero() {
+				if _, err := CreateCertificateRequest(tt.signer, tt.tmpl); err == nil {
+					t.Error("CreateCertificateRequest() succeeded, want an error")
+				}
+			}
+		})
+	}
+}
+
+func TestCreateSelfSigned(t *testing.T) {
+	tmpl := testTemplate(t)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, signer := range newSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			cert, err := createSelfSigned(rand.Reader, now, signer, tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+				t.Errorf("CheckSignature() error = %v", err)
+			}
+			if !cert.NotBefore.Equal(now) || !cert.NotAfter.Equal(now.Add(DefaultValidity)) {
+				t.Errorf("validity = %s to %s", cert.NotBefore, cert.NotAfter)
+			}
+			if cert.IsCA || !cert.BasicConstraintsValid {
+				t.Errorf("IsCA = %v, BasicConstraintsValid = %v", cert.IsCA, cert.BasicConstraintsValid)
+			}
+			if cert.KeyUsage != tmpl.KeyUsage || !reflect.DeepEqual(cert.ExtKeyUsage, tmpl.ExtKeyUsage) {
+				t.Errorf("KeyUsage = %v, ExtKeyUsage = %v", cert.KeyUsage, cert.ExtKeyUsage)
+			}
+			if cert.SerialNumber.Sign() <= 0 || cert.SerialNumber.BitLen() > 128 {
+				t.Errorf("SerialNumber = %s", cert.SerialNumber)
+			}
+
+			// RFC 5280 section 4.2.1.2, method 1.
+			var spki struct {
+				Algorithm pkix.AlgorithmIdentifier
+				PublicKey asn1.BitString
+			}
+			if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+				t.Fatal(err)
+			}
+			if want := sha1.Sum(spki.PublicKey.Bytes); !bytes.Equal(cert.SubjectKeyId, want[:]) {
+				t.Errorf("SubjectKeyId = %x, want %x", cert.SubjectKeyId, want)
+			}
+
+			roots := x509.NewCertPool()
+			roots.AddCert(cert)
+			_, err = cert.Verify(x509.VerifyOptions{
+				Roots:       roots,
+				DNSName:     "device-1234.example.com",
+				CurrentTime: now.Add(time.Hour),
+				KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			})
+			if err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateSelfSigned_CA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.AddDate(10, 0, 0)
+
+	cert, err := CreateSelfSigned(key, &Template{
+		Subject:   pkix.Name{CommonName: "Development CA"},
+		KeyUsage:  x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+		IsCA:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.IsCA || !cert.NotBefore.Equal(notBefore) || !cert.NotAfter.Equal(notAfter) {
+		t.Errorf("IsCA = %v, validity = %s to %s", cert.IsCA, cert.NotBefore, cert.NotAfter)
+	}
+}
+
+func TestRandomSerial(t *testing.T) {
+	tests := []struct {
+		name    string
+		rand    io.Reader
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "random",
+			rand: bytes.NewReader(bytes.Repeat([]byte{0x01}, 16)),
+			want: "1334440654591915542993625911497130241",
+		},
+		{
+			// a zero serial is not positive, so another is read.
+			name: "zero",
+			rand: bytes.NewReader(append(make([]byte, 16), bytes.Repeat([]byte{0xff}, 16)...)),
+			want: "340282366920938463463374607431768211455",
+		},
+		{
+			name:    "short",
+			rand:    bytes.NewReader(make([]byte, 8)),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RandomSerial(tt.rand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RandomSerial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("RandomSerial() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package applesecurity
+
+import "fmt"
+
+// CFError is an error reported by the Security framework as a CFError,
+// such as a failure to sign, decrypt or create a key.
+//
+// It unwraps to Err, so errors.Is(err, ErrItemNotFound) works as it does
+// for result codes returned directly, and to the error underlying it.
+type CFError struct {
+	// Domain is the error domain, such as "NSOSStatusErrorDomain".
+	Domain string
+	Code   int
+
+	// Description and FailureReason are the localised
+	// messages of the error. FailureReason may be empty.
+	Description   string
+	FailureReason string
+
+	// Err is the error for Code in Domain: an [Error] for the OSStatus
+	// domain, or a LocalAuthentication error from the localauth package.
+	// It is nil for other domains.
+	Err error
+
+	// Underlying is the error which caused this one, if the
+	// framework reported it under kCFErrorUnderlyingErrorKey.
+	Underlying error
+}
+
+func (e *CFError) Error() string {
+	msg := e.Description
+	if msg == "" {
+		msg = fmt.Sprintf("%s error %d", e.Domain, e.Code)
+	}
+	if e.FailureReason != "" {
+		msg += ": " + e.FailureReason
+	}
+	return msg
+}
+
+func (e *CFError) Unwrap() []error {
+	var errs []error
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	if e.Underlying != nil {
+		errs = append(errs, e.Underlying)
+	}
+	return errs
+}
@@ -0,0 +1,77 @@
+package applesecurity
+
+import (
+	"errors"
+
+	"github.com/common-fate/go-apple-security/localauth"
+)
+
+// Result codes used to classify errors, which have no exported sentinels.
+const (
+	errInteractionRequired = Error(-25315)
+	errInDarkWake          = Error(-25320)
+	errRestrictedAPI       = Error(-34020)
+	errInternalComponent   = Error(-2070)
+	errServiceNotAvailable = Error(-67585)
+)
+
+// IsNotFound reports whether err is caused by an item
+// which does not exist, [ErrItemNotFound].
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrItemNotFound)
+}
+
+// IsLockedDevice reports whether err is caused by the keychain being
+// unavailable while the device is locked, [ErrInteractionNotAllowed].
+// This happens to items which are only accessible when unlocked, and
+// the operation will succeed once the user unlocks the device.
+func IsLockedDevice(err error) bool {
+	return errors.Is(err, ErrInteractionNotAllowed)
+}
+
+// IsUserActionRequired reports whether err can only be resolved by the
+// user, for example by unlocking the device, entering their password
+// after biometry is locked out, or enrolling a fingerprint.
+func IsUserActionRequired(err error) bool {
+	for _, target := range []error{
+		ErrInteractionNotAllowed,
+		errInteractionRequired,
+		localauth.ErrNotInteractive,
+		localauth.ErrBiometryLockout,
+		localauth.ErrBiometryNotEnrolled,
+		localauth.ErrPasscodeNotSet,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEntitlementProblem reports whether err is caused by the binary
+// lacking the entitlements or code signature required by the
+// operation, such as the keychain-access-groups entitlement for the
+// data protection keychain or for an access group.
+func IsEntitlementProblem(err error) bool {
+	return errors.Is(err, ErrMissingEntitlement) || errors.Is(err, errRestrictedAPI)
+}
+
+// IsTransient reports whether err is caused by a condition which is
+// expected to clear without changes to the program, such as a locked
+// device or a busy securityd, so that the operation may be retried.
+//
+// See [RetryPolicy].
+func IsTransient(err error) bool {
+	for _, target := range []error{
+		ErrInteractionNotAllowed,
+		ErrNotAvailable,
+		errInDarkWake,
+		errInternalComponent,
+		errServiceNotAvailable,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+// Program devca is a certificate authority for local development, like
+// mkcert, whose root key lives in the Secure Enclave and never touches
+// disk.
+//
+// Usage:
+//
+//	devca init [-tag com.example.devca] [-name "Development CA"] [-user-presence]
+//	devca root [-o root.pem]
+//	devca issue [-validity 24h] [-client] localhost 127.0.0.1 "*.dev.test"
+//	devca log
+//
+// init creates the root key in the Secure Enclave and stores the root
+// certificate in the keychain, paired with it. root writes the root
+// certificate, to install in trust stores, for example with
+//
+//	security add-trusted-cert -r trustRoot -k ~/Library/Keychains/login.keychain-db root.pem
+//
+// issue writes a certificate and its private key to the current
+// directory, and records the certificate in an issuance log in the
+// user's configuration directory, which log prints.
+//
+// Like the tests, the binary must be codesigned with entitlements
+// allowing it to access the keychain.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/common-fate/go-apple-security/devca"
+	"github.com/common-fate/go-apple-security/enclavekey"
+	"github.com/common-fate/go-apple-security/identity"
+)
+
+const defaultTag = "com.common-fate.devca"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("devca: ")
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "init":
+		err = initCA(os.Args[2:])
+	case "root":
+		err = root(os.Args[2:])
+	case "issue":
+		err = issue(os.Args[2:])
+	case "log":
+		err = printLog(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: devca init|root|issue|log [flags] [hosts]")
+	os.Exit(2)
+}
+
+func initCA(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	tag := flags.String("tag", defaultTag, "tag of the root key")
+	name := flags.String("name", "Development CA", "common name of the root certificate")
+	userPresence := flags.Bool("user-presence", false, "require biometry or the passcode to issue certificates")
+	_ = flags.Parse(args)
+
+	existing, err := enclavekey.List(enclavekey.ListInput{Tag: *tag})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("a root key with the tag %s already exists", *tag)
+	}
+
+	key, err := enclavekey.Create(enclavekey.CreateInput{Tag: *tag, Label: *name, UserPresence: *userPresence})
+	if err != nil {
+		return err
+	}
+	key.LAContext = &enclavekey.LAContext{LocalizedReason: "create the development CA"}
+
+	cert, err := devca.NewRoot(key, *name)
+	if err == nil {
+		_, err = identity.AddCertificate(identity.AddCertificateInput{Certificate: cert, Label: *name})
+	}
+	if err != nil {
+		// don't leave a root key behind that 'devca init' would refuse to replace.
+		if _, delErr := enclavekey.Delete(enclavekey.DeleteInput{ApplicationLabel: key.ApplicationLabel}); delErr != nil {
+			return fmt.Errorf("%w (and removing the root key failed: %v)", err, delErr)
+		}
+		return err
+	}
+
+	fmt.Printf("created %s; run 'devca root -o root.pem' and add root.pem to your trust stores\n", *name)
+	return nil
+}
+
+func root(args []string) error {
+	flags := flag.NewFlagSet("root", flag.ExitOnError)
+	tag := flags.String("tag", defaultTag, "tag of the root key")
+	out := flags.String("o", "", "file to write the root certificate to (default: stdout)")
+	_ = flags.Parse(args)
+
+	ca, err := openCA(*tag)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(ca.RootPEM())
+		return err
+	}
+	return os.WriteFile(*out, ca.RootPEM(), 0644)
+}
+
+func issue(args []string) error {
+	flags := flag.NewFlagSet("issue", flag.ExitOnError)
+	tag := flags.String("tag", defaultTag, "tag of the root key")
+	validity := flags.Duration("validity", devca.DefaultLeafValidity, "validity of the certificate")
+	client := flags.Bool("client", false, "allow client authentication")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		return errors.New("at least one host is required")
+	}
+
+	ca, err := openCA(*tag)
+	if err != nil {
+		return err
+	}
+	leaf, err := ca.Issue(devca.IssueInput{Hosts: flags.Args(), Validity: *validity, ClientAuth: *client})
+	if err != nil {
+		return err
+	}
+	keyPEM, err := leaf.PrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+
+	name := fileName(flags.Args())
+	if err := os.WriteFile(name+".pem", leaf.CertificatePEM(), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name+"-key.pem", keyPEM, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s.pem and %s-key.pem, valid until %s\n", name, name, leaf.Certificate.NotAfter.Local().Format(time.RFC1123))
+	return nil
+}
+
+func printLog(args []string) error {
+	flags := flag.NewFlagSet("log", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	l, err := issuanceLog()
+	if err != nil {
+		return err
+	}
+	records, err := l.Records()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		fmt.Printf("%s %s %s %s\n", r.IssuedAt.Local().Format(time.RFC3339), r.Serial, r.NotAfter.Local().Format(time.RFC3339), strings.Join(r.Hosts, ","))
+	}
+	return nil
+}
+
+// openCA returns the CA whose root key has the tag, with
+// its root certificate from the keychain.
+func openCA(tag string) (*devca.CA, error) {
+	key, err := enclavekey.Get(enclavekey.GetInput{Tag: tag})
+	if err != nil {
+		return nil, fmt.Errorf("finding the root key, created by 'devca init': %w", err)
+	}
+	key.LAContext = &enclavekey.LAContext{LocalizedReason: "issue a development certificate"}
+
+	identities, err := identity.List(identity.ListInput{PublicKeyHash: key.ApplicationLabel})
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, errors.New("the root certificate is not in the keychain")
+	}
+
+	l, err := issuanceLog()
+	if err != nil {
+		return nil, err
+	}
+	return devca.Open(key, identities[0].Certificate, l)
+}
+
+// issuanceLog returns the log in the user's configuration directory.
+func issuanceLog() (*devca.FileLog, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, "devca")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &devca.FileLog{Path: filepath.Join(dir, "issued.jsonl")}, nil
+}
+
+// fileName returns the name of the files of a certificate for hosts,
+// such as "localhost+2" for three hosts beginning with localhost.
+func fileName(hosts []string) string {
+	name := strings.NewReplacer("*", "_wildcard", ":", "_", "/", "_", "@", "_at_").Replace(hosts[0])
+	if len(hosts) > 1 {
+		name += fmt.Sprintf("+%d", len(hosts)-1)
+	}
+	return name
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func copyFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// archive writes the directory dir to w as a tar archive,
+// with entries named relative to the parent of dir.
+func archive(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	parent := filepath.Dir(dir)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return fmt.Errorf("%s: only regular files and directories can be encrypted", path)
+		}
+		name, err := filepath.Rel(parent, path)
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return copyFile(tw, path)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extract extracts the tar archive read from r into dir. Entries are
+// extracted into a staging directory, and moved into dir only once
+// the whole archive has been decrypted and authenticated. Extracting
+// fails without replacing anything if an entry already exists in dir,
+// but as the entries are moved one at a time, those moved before a
+// failure are left in dir.
+func extract(r io.Reader, dir string) error {
+	staging, err := os.MkdirTemp(dir, ".enclave-crypt-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	var top []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q is outside the directory", hdr.Name)
+		}
+		path := filepath.Join(staging, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("archive entry %q is not a regular file or directory", hdr.Name)
+		}
+
+		if first := strings.SplitN(name, string(filepath.Separator), 2)[0]; !contains(top, first) {
+			top = append(top, first)
+		}
+	}
+
+	// the tar reader stops at the end of the archive, so read the rest
+	// of r to authenticate the last chunk, which follows it if it was
+	// padded, before moving anything into dir.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	for _, name := range top {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("%s already exists", filepath.Join(dir, name))
+		}
+	}
+	for _, name := range top {
+		if err := move(filepath.Join(staging, name), filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move moves the file or directory src to dst, failing if dst exists,
+// even if it was created after extract checked for it: files are linked
+// into dst and directories created with os.Mkdir, which both fail if
+// dst exists. The originals are left in the staging directory.
+func move(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		err = os.Link(src, dst)
+	} else {
+		err = os.Mkdir(dst, 0700)
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if err != nil || !info.IsDir() {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := move(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/filecrypt"
+)
+
+// encryptDir encrypts the directory dir to key as a tar archive followed
+// by padding, as written by tar tools padding archives to whole records.
+func encryptDir(t *testing.T, dir string, padding int, key crypto.Decrypter) (data []byte, size int) {
+	t.Helper()
+	var plain bytes.Buffer
+	if err := archive(&plain, dir); err != nil {
+		t.Fatal(err)
+	}
+	plain.Write(make([]byte, padding))
+
+	var b bytes.Buffer
+	w, err := filecrypt.NewWriter(rand.Reader, &b, []crypto.PublicKey{key.Public()}, &filecrypt.Options{ContentType: filecrypt.ContentTypeTar})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plain.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes(), plain.Len()
+}
+
+func extractData(data []byte, key crypto.Decrypter, dir string) error {
+	r, err := filecrypt.NewReader(bytes.NewReader(data), key)
+	if err != nil {
+		return err
+	}
+	return extract(r, dir)
+}
+
+func TestExtract(t *testing.T) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := ecies.NewDecrypter(priv)
+
+	src := filepath.Join(t.TempDir(), "secrets")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the padding ends the archive before the last chunk, so that
+	// the last chunk is only authenticated if extract reads past
+	// the end of the archive.
+	data, size := encryptDir(t, src, 2*filecrypt.ChunkSize, key)
+	last := size%filecrypt.ChunkSize + 16
+	if size%filecrypt.ChunkSize == 0 {
+		last = filecrypt.ChunkSize + 16
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := extractData(data, key, dir); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join(dir, "secrets", "sub", "a.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "hello" {
+			t.Errorf("extracted %q, want %q", got, "hello")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		dir := t.TempDir()
+		err := extractData(data[:len(data)-last], key, dir)
+		if !errors.Is(err, filecrypt.ErrTruncated) {
+			t.Errorf("error = %v, want filecrypt.ErrTruncated", err)
+		}
+		if _, err := os.Lstat(filepath.Join(dir, "secrets")); err == nil {
+			t.Error("truncated archive was extracted")
+		}
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, "secrets"), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := extractData(data, key, dir); err == nil {
+			t.Error("extracting over an existing directory succeeded")
+		}
+	})
+}
+
+func TestMove_Exists(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "file"), []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(src, "dir"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"file", "dir"} {
+		if err := os.WriteFile(filepath.Join(dst, name), []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := move(filepath.Join(src, name), filepath.Join(dst, name)); err == nil {
+			t.Errorf("moving %s over an existing file succeeded", name)
+		}
+		if got, _ := os.ReadFile(filepath.Join(dst, name)); string(got) != "old" {
+			t.Errorf("%s was replaced", name)
+		}
+	}
+}
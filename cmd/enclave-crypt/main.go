@@ -0,0 +1,292 @@
+// Program enclave-crypt encrypts files and directories to Secure Enclave
+// keys, so that they can only be decrypted on the devices holding them.
+//
+// Usage:
+//
+//	enclave-crypt keygen -tag com.example.files [-user-presence]
+//	enclave-crypt encrypt -tag com.example.files [-r teammate.pub] [-o out.enc] path
+//	enclave-crypt decrypt [-o out] path.enc
+//	enclave-crypt recipients path.enc
+//
+// Files are encrypted to the enclave keys with the tags given by -tag,
+// and to the public keys in the files given by -r, which may be in any
+// form accepted by pubkey.Parse, such as the OpenSSH public key printed
+// by keygen. Directories are encrypted as a tar archive and extracted
+// when they are decrypted. Decrypting finds the enclave key of one of
+// the recipients in the keychain, prompting for user presence if the
+// key was created with -user-presence.
+//
+// Like the tests, the binary must be codesigned with entitlements
+// allowing it to access the keychain.
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/enclavekey"
+	"github.com/common-fate/go-apple-security/filecrypt"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// stringsFlag is a flag which may be given more than once.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, ",") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("enclave-crypt: ")
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "encrypt":
+		err = encrypt(os.Args[2:])
+	case "decrypt":
+		err = decrypt(os.Args[2:])
+	case "recipients":
+		err = recipients(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: enclave-crypt keygen|encrypt|decrypt|recipients [flags] [path]")
+	os.Exit(2)
+}
+
+func keygen(args []string) error {
+	flags := flag.NewFlagSet("keygen", flag.ExitOnError)
+	tag := flags.String("tag", "", "tag of the new key (required)")
+	label := flags.String("label", "", "label of the new key")
+	userPresence := flags.Bool("user-presence", false, "require biometry or the passcode to decrypt")
+	_ = flags.Parse(args)
+	if *tag == "" {
+		return errors.New("-tag is required")
+	}
+
+	k, err := enclavekey.Create(enclavekey.CreateInput{Tag: *tag, Label: *label, UserPresence: *userPresence})
+	if err != nil {
+		return err
+	}
+	line, err := k.AuthorizedKey(*tag)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(line)
+	return err
+}
+
+func encrypt(args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	var tags, keyFiles stringsFlag
+	flags.Var(&tags, "tag", "tag of an enclave key to encrypt to (repeatable)")
+	flags.Var(&keyFiles, "r", "file holding a public key to encrypt to (repeatable)")
+	out := flags.String("o", "", "output file (default: path with .enc appended)")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("encrypt needs exactly one path")
+	}
+	path := filepath.Clean(flags.Arg(0))
+
+	var recipients []crypto.PublicKey
+	for _, tag := range tags {
+		k, err := enclavekey.Get(enclavekey.GetInput{Tag: tag})
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, k.PublicKey)
+	}
+	for _, name := range keyFiles {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		pub, err := pubkey.Parse(data)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+		recipients = append(recipients, pub)
+	}
+	if len(recipients) == 0 {
+		return errors.New("at least one -tag or -r recipient is required")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var opts filecrypt.Options
+	if info.IsDir() {
+		opts.ContentType = filecrypt.ContentTypeTar
+	}
+	if *out == "" {
+		*out = path + ".enc"
+	}
+
+	return writeAtomically(*out, func(f *os.File) error {
+		w, err := filecrypt.NewWriter(rand.Reader, f, recipients, &opts)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			err = archive(w, path)
+		} else {
+			err = copyFile(w, path)
+		}
+		if err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+func decrypt(args []string) error {
+	flags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	out := flags.String("o", "", "output file, or directory to extract into (default: path without .enc, or the current directory)")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("decrypt needs exactly one path")
+	}
+	path := flags.Arg(0)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h, err := filecrypt.ReadHeader(f)
+	if err != nil {
+		return err
+	}
+	key, err := findKey(h, path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r, err := filecrypt.NewReader(f, key)
+	if err != nil {
+		return err
+	}
+
+	if r.Header.ContentType == filecrypt.ContentTypeTar {
+		if *out == "" {
+			*out = "."
+		}
+		return extract(r, *out)
+	}
+
+	if *out == "" {
+		if !strings.HasSuffix(path, ".enc") {
+			return errors.New("-o is required when the path does not end in .enc")
+		}
+		*out = strings.TrimSuffix(path, ".enc")
+	}
+	return writeAtomically(*out, func(f *os.File) error {
+		_, err := io.Copy(f, r)
+		return err
+	})
+}
+
+func recipients(args []string) error {
+	flags := flag.NewFlagSet("recipients", flag.ExitOnError)
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("recipients needs exactly one path")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h, err := filecrypt.ReadHeader(f)
+	if err != nil {
+		return err
+	}
+	for _, r := range h.Recipients() {
+		pub, err := pubkey.ParseJWK(r.PublicKey)
+		if err != nil {
+			return err
+		}
+		fp, err := pubkey.Fingerprint(pub)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", r.KeyID, fp)
+	}
+	return nil
+}
+
+// findKey returns the enclave key of the first recipient
+// of the file found in the keychain.
+func findKey(h *filecrypt.Header, path string) (*enclavekey.Key, error) {
+	for _, r := range h.Recipients() {
+		pub, err := pubkey.ParseJWK(r.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		label, err := pubkey.ApplicationLabel(pub)
+		if err != nil {
+			// not an enclave key.
+			continue
+		}
+		k, err := enclavekey.Get(enclavekey.GetInput{ApplicationLabel: label})
+		if errors.Is(err, applesecurity.ErrItemNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		k.LAContext = &enclavekey.LAContext{LocalizedReason: "decrypt " + filepath.Base(path)}
+		return k, nil
+	}
+	return nil, errors.New("no enclave key on this device can decrypt the file")
+}
+
+// writeAtomically calls write with a temporary file, renaming
+// it to name if write succeeds and removing it otherwise, so
+// that a failed decryption leaves no partial plaintext.
+func writeAtomically(name string, write func(f *os.File) error) error {
+	if _, err := os.Lstat(name); err == nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
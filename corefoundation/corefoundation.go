@@ -15,6 +15,7 @@ import (
 const (
 	nilCFData   C.CFDataRef   = 0
 	nilCFString C.CFStringRef = 0
+	nilCFNumber C.CFNumberRef = 0
 )
 
 type TypeRef = C.CFTypeRef
@@ -22,6 +23,8 @@ type ArrayRef = C.CFArrayRef
 type StringRef = C.CFStringRef
 type DataRef = C.CFDataRef
 type DictionaryRef = C.CFDictionaryRef
+type NumberRef = C.CFNumberRef
+type ErrorRef = C.CFErrorRef
 
 type Dictionary = map[TypeRef]TypeRef
 type PointerDictionary = map[TypeRef]unsafe.Pointer
@@ -83,6 +86,16 @@ func NewCFString(s string) (C.CFStringRef, error) {
 	return ref, nil
 }
 
+func NewCFNumber(n int) (C.CFNumberRef, error) {
+	v := C.SInt64(n)
+
+	ref := C.CFNumberCreate(C.kCFAllocatorDefault, C.kCFNumberSInt64Type, unsafe.Pointer(&v))
+	if ref == nilCFNumber {
+		return ref, fmt.Errorf("error creating CFNumber")
+	}
+	return ref, nil
+}
+
 // CFArrayToArray converts a CFArrayRef to an array of CFTypes.
 func CFArrayToArray(cfArray ArrayRef) (a []TypeRef) {
 	count := C.CFArrayGetCount(cfArray)
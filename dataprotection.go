@@ -0,0 +1,47 @@
+package applesecurity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+
+// probeDataProtectionKeychain searches the data protection keychain
+// for an item which does not exist, returning the resulting status.
+static OSStatus probeDataProtectionKeychain() {
+	const void *keys[] = {
+		kSecClass,
+		kSecUseDataProtectionKeychain,
+		kSecAttrService,
+		kSecMatchLimit,
+	};
+	const void *values[] = {
+		kSecClassGenericPassword,
+		kCFBooleanTrue,
+		CFSTR("go-apple-security.data-protection-probe"),
+		kSecMatchLimitOne,
+	};
+	CFDictionaryRef query = CFDictionaryCreate(kCFAllocatorDefault, keys, values, 4,
+		&kCFTypeDictionaryKeyCallBacks, &kCFTypeDictionaryValueCallBacks);
+	OSStatus status = SecItemCopyMatching(query, NULL);
+	CFRelease(query);
+	return status;
+}
+*/
+import "C"
+
+import "sync"
+
+var dataProtectionAvailable = sync.OnceValue(func() bool {
+	return C.probeDataProtectionKeychain() != C.errSecMissingEntitlement
+})
+
+// DataProtectionKeychainAvailable reports whether this process can use
+// the data protection keychain, which requires the binary to be
+// codesigned with keychain entitlements.
+//
+// The keychain is probed once, with a query for an item which does
+// not exist, and the result is cached for the life of the process.
+func DataProtectionKeychainAvailable() bool {
+	return dataProtectionAvailable()
+}
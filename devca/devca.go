@@ -0,0 +1,282 @@
+// Package devca is a certificate authority for local development, like
+// mkcert, whose private key can live in the Secure Enclave.
+//
+// The CA signs with any crypto.Signer, such as an enclavekey.Key, so
+// the root key never touches disk. It issues short-lived certificates
+// for localhost and development hostnames, recording each in an
+// issuance log. The root certificate can be installed in trust stores
+// with RootPEM.
+//
+// The package is pure Go, so it can be tested with software keys on
+// any platform. The devca command stores the root key in the Secure
+// Enclave and the root certificate in the keychain.
+package devca
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/common-fate/go-apple-security/certgen"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+const (
+	// RootValidity is the validity of a root certificate.
+	RootValidity = 10 * 365 * 24 * time.Hour
+
+	// DefaultLeafValidity is the validity of a leaf
+	// certificate if IssueInput.Validity is not set.
+	DefaultLeafValidity = 24 * time.Hour
+
+	// MaxLeafValidity bounds the validity of leaf certificates,
+	// which are meant to be reissued rather than renewed.
+	MaxLeafValidity = 30 * 24 * time.Hour
+
+	// clockSkew backdates certificates, so that they are valid
+	// on machines whose clocks are slightly behind.
+	clockSkew = 5 * time.Minute
+)
+
+// CA is a development certificate authority.
+type CA struct {
+	// Signer is the private key of the CA,
+	// such as an enclavekey.Key.
+	Signer crypto.Signer
+	// Root is the root certificate of the CA.
+	Root *x509.Certificate
+	// Log records the certificates issued. It is optional.
+	Log Log
+}
+
+// NewRoot returns a root certificate for signer, with the name
+// as its common name, valid for RootValidity. Its path length
+// is zero, so it can only issue leaf certificates. The name must
+// not be longer than 64 bytes.
+func NewRoot(signer crypto.Signer, name string) (*x509.Certificate, error) {
+	if len(name) > maxCommonName {
+		return nil, fmt.Errorf("the name must not be longer than %d bytes", maxCommonName)
+	}
+	now := time.Now()
+	return certgen.CreateSelfSigned(signer, &certgen.Template{
+		Subject:        pkix.Name{CommonName: name, Organization: []string{"Development CA"}},
+		KeyUsage:       x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		NotBefore:      now.Add(-clockSkew),
+		NotAfter:       now.Add(RootValidity),
+		IsCA:           true,
+		MaxPathLenZero: true,
+	})
+}
+
+// Open returns the CA with the root certificate, checking
+// that it is a CA certificate for the public key of signer.
+func Open(signer crypto.Signer, root *x509.Certificate, log Log) (*CA, error) {
+	if !root.IsCA {
+		return nil, errors.New("root certificate is not a CA certificate")
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(root.PublicKey) {
+		return nil, errors.New("root certificate does not match the public key of the signer")
+	}
+	return &CA{Signer: signer, Root: root, Log: log}, nil
+}
+
+// RootPEM returns the PEM encoded root certificate,
+// to install in trust stores.
+func (ca *CA) RootPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Root.Raw})
+}
+
+type IssueInput struct {
+	// Hosts are the names the certificate is valid for: DNS names,
+	// including wildcards such as "*.example.test", IP addresses,
+	// email addresses and URIs. The first DNS name or IP address
+	// which fits in a common name is the common name.
+	Hosts []string
+
+	// PublicKey is the public key to certify. If it is nil, a P-256
+	// key is generated and returned in Leaf.PrivateKey.
+	PublicKey crypto.PublicKey
+
+	// Validity defaults to DefaultLeafValidity,
+	// and must not exceed MaxLeafValidity.
+	Validity time.Duration
+
+	// ClientAuth allows the certificate to be used for client
+	// authentication as well as by servers.
+	ClientAuth bool
+}
+
+// Leaf is an issued certificate.
+type Leaf struct {
+	Certificate *x509.Certificate
+	// PrivateKey is set if IssueInput.PublicKey was nil.
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// CertificatePEM returns the PEM encoded certificate.
+func (l *Leaf) CertificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: l.Certificate.Raw})
+}
+
+// PrivateKeyPEM returns the PEM encoded PKCS#8 private key.
+func (l *Leaf) PrivateKeyPEM() ([]byte, error) {
+	if l.PrivateKey == nil {
+		return nil, errors.New("the private key of the certificate was not generated by the CA")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(l.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// Issue issues a leaf certificate, signed by the CA, and records it in
+// the log. The certificate is not returned if it cannot be recorded.
+func (ca *CA) Issue(input IssueInput) (*Leaf, error) {
+	return ca.issue(rand.Reader, time.Now(), input)
+}
+
+func (ca *CA) issue(rand io.Reader, now time.Time, input IssueInput) (*Leaf, error) {
+	if len(input.Hosts) == 0 {
+		return nil, errors.New("at least one host is required")
+	}
+	validity := input.Validity
+	if validity == 0 {
+		validity = DefaultLeafValidity
+	}
+	if validity < 0 || validity > MaxLeafValidity {
+		return nil, fmt.Errorf("validity must be between 0 and %s", MaxLeafValidity)
+	}
+
+	var leaf Leaf
+	pub := input.PublicKey
+	if pub == nil {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand)
+		if err != nil {
+			return nil, err
+		}
+		leaf.PrivateKey = key
+		pub = key.Public()
+	}
+	keyID, err := pubkey.ApplicationLabel(pub)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := certgen.RandomSerial(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        pkix.Name{Organization: []string{"Development certificate"}},
+		NotBefore:      now.Add(-clockSkew),
+		NotAfter:       now.Add(validity),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		SubjectKeyId:   keyID,
+		AuthorityKeyId: ca.Root.SubjectKeyId,
+	}
+	if _, ok := pub.(*ecdsa.PublicKey); !ok {
+		// RSA keys are used for key encipherment in TLS 1.2.
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	if input.ClientAuth {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+	if err := addHosts(template, input.Hosts); err != nil {
+		return nil, err
+	}
+	template.Subject.CommonName = commonName(input.Hosts)
+	if template.NotAfter.After(ca.Root.NotAfter) {
+		return nil, errors.New("the root certificate expires before the certificate would")
+	}
+
+	der, err := x509.CreateCertificate(rand, template, ca.Root, pub, ca.Signer)
+	if err != nil {
+		return nil, err
+	}
+	leaf.Certificate, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	if ca.Log != nil {
+		if err := ca.Log.Append(newRecord(leaf.Certificate, now)); err != nil {
+			return nil, fmt.Errorf("recording issued certificate: %w", err)
+		}
+	}
+	return &leaf, nil
+}
+
+// addHosts adds the hosts to the subject alternative names of template.
+func addHosts(template *x509.Certificate, hosts []string) error {
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if email, err := mail.ParseAddress(h); err == nil && email.Address == h {
+			template.EmailAddresses = append(template.EmailAddresses, h)
+		} else if u, err := url.Parse(h); err == nil && u.Scheme != "" && u.Host != "" {
+			template.URIs = append(template.URIs, u)
+		} else if validHostname(h) {
+			template.DNSNames = append(template.DNSNames, h)
+		} else {
+			return fmt.Errorf("%q is not a valid hostname, IP address, email address or URI", h)
+		}
+	}
+	return nil
+}
+
+// maxCommonName is the upper bound of a common name, ub-common-name
+// of RFC 5280.
+const maxCommonName = 64
+
+// commonName returns the first of the valid hosts which is a DNS name
+// or IP address no longer than maxCommonName, or "" if there is none.
+// Clients match the subject alternative names rather than the common
+// name, so the common name only describes the certificate.
+func commonName(hosts []string) string {
+	for _, h := range hosts {
+		if len(h) <= maxCommonName && (net.ParseIP(h) != nil || validHostname(h)) {
+			return h
+		}
+	}
+	return ""
+}
+
+// validHostname reports whether h is a DNS name, which may begin
+// with a wildcard label followed by at least two labels, so that
+// a wildcard cannot cover a whole top-level domain.
+func validHostname(h string) bool {
+	if len(h) == 0 || len(h) > 253 {
+		return false
+	}
+	labels := strings.Split(h, ".")
+	for i, label := range labels {
+		if i == 0 && label == "*" && len(labels) > 2 {
+			continue
+		}
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
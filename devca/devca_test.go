@@ -0,0 +1,234 @@
+package devca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCA(t *testing.T, log Log) *CA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewRoot(key, "devca test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := Open(key, root, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca
+}
+
+func TestNewRoot(t *testing.T) {
+	ca := newCA(t, nil)
+	if !ca.Root.IsCA || !ca.Root.MaxPathLenZero || ca.Root.MaxPathLen != 0 {
+		t.Errorf("IsCA = %v, MaxPathLen = %d", ca.Root.IsCA, ca.Root.MaxPathLen)
+	}
+	if ca.Root.KeyUsage&x509.KeyUsageCertSign == 0 {
+		t.Errorf("KeyUsage = %v, want certificate signing", ca.Root.KeyUsage)
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(other, ca.Root, nil); err == nil {
+		t.Error("Open() with the wrong signer succeeded")
+	}
+	if _, err := NewRoot(other, strings.Repeat("a", 65)); err == nil {
+		t.Error("NewRoot() with a name longer than 64 bytes succeeded")
+	}
+}
+
+func TestIssue(t *testing.T) {
+	log := &FileLog{Path: filepath.Join(t.TempDir(), "issued.jsonl")}
+	ca := newCA(t, log)
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca.RootPEM()) {
+		t.Fatal("RootPEM() is not a PEM encoded certificate")
+	}
+
+	hosts := []string{"localhost", "127.0.0.1", "::1", "*.dev.test", "alice@example.com", "spiffe://dev.test/api"}
+	now := time.Now().UTC().Truncate(time.Second)
+	leaf, err := ca.issue(rand.Reader, now, IssueInput{Hosts: hosts, Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := leaf.Certificate
+
+	if cert.Subject.CommonName != "localhost" {
+		t.Errorf("CommonName = %q", cert.Subject.CommonName)
+	}
+	if !cert.NotAfter.Equal(now.Add(time.Hour)) || cert.NotBefore.After(now) {
+		t.Errorf("validity = %s to %s", cert.NotBefore, cert.NotAfter)
+	}
+	if len(cert.DNSNames) != 2 || len(cert.IPAddresses) != 2 || len(cert.EmailAddresses) != 1 || len(cert.URIs) != 1 {
+		t.Errorf("subject alternative names = %v %v %v %v", cert.DNSNames, cert.IPAddresses, cert.EmailAddresses, cert.URIs)
+	}
+	if cert.IsCA {
+		t.Error("leaf certificate is a CA certificate")
+	}
+
+	for _, name := range []string{"localhost", "127.0.0.1", "api.dev.test"} {
+		_, err := cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: name, CurrentTime: now})
+		if err != nil {
+			t.Errorf("Verify(%s) error = %v", name, err)
+		}
+	}
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "localhost", CurrentTime: now.Add(2 * time.Hour)})
+	if err == nil {
+		t.Error("Verify() of an expired certificate succeeded")
+	}
+
+	records, err := log.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Record{
+		Serial:    fmt.Sprintf("%x", cert.SerialNumber),
+		Subject:   cert.Subject.String(),
+		Hosts:     []string{"localhost", "*.dev.test", "127.0.0.1", "::1", "alice@example.com", "spiffe://dev.test/api"},
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		IssuedAt:  now,
+	}
+	if len(records) != 1 || !reflect.DeepEqual(records[0], want) {
+		t.Errorf("Records() = %+v, want %+v", records, want)
+	}
+	if err := log.Append(want); err == nil {
+		t.Error("Append() of a duplicate serial number succeeded")
+	}
+}
+
+func TestIssue_Errors(t *testing.T) {
+	ca := newCA(t, nil)
+
+	tests := []struct {
+		name  string
+		input IssueInput
+	}{
+		{name: "no hosts", input: IssueInput{}},
+		{name: "invalid hostname", input: IssueInput{Hosts: []string{"local host"}}},
+		{name: "wildcard not first", input: IssueInput{Hosts: []string{"api.*.test"}}},
+		{name: "wildcard top-level domain", input: IssueInput{Hosts: []string{"*.test"}}},
+		{name: "wildcard only", input: IssueInput{Hosts: []string{"*"}}},
+		{name: "too long", input: IssueInput{Hosts: []string{"localhost"}, Validity: MaxLeafValidity + time.Second}},
+		{name: "negative validity", input: IssueInput{Hosts: []string{"localhost"}, Validity: -time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ca.Issue(tt.input); err == nil {
+				t.Error("Issue() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestIssue_CommonName(t *testing.T) {
+	ca := newCA(t, nil)
+	long := strings.Repeat("a", 60) + ".dev.test"
+
+	tests := []struct {
+		name  string
+		hosts []string
+		want  string
+	}{
+		{name: "dns", hosts: []string{"api.dev.test", "127.0.0.1"}, want: "api.dev.test"},
+		{name: "ip", hosts: []string{"127.0.0.1", "api.dev.test"}, want: "127.0.0.1"},
+		{name: "email first", hosts: []string{"alice@example.com", "api.dev.test"}, want: "api.dev.test"},
+		{name: "uri first", hosts: []string{"spiffe://dev.test/api", "::1"}, want: "::1"},
+		{name: "long dns", hosts: []string{long, "api.dev.test"}, want: "api.dev.test"},
+		{name: "no dns or ip", hosts: []string{"spiffe://dev.test/" + long}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf, err := ca.Issue(IssueInput{Hosts: tt.hosts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := leaf.Certificate.Subject.CommonName; got != tt.want {
+				t.Errorf("CommonName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type failingLog struct{}
+
+func (failingLog) Append(Record) error { return errors.New("disk full") }
+
+func TestIssue_LogFailure(t *testing.T) {
+	ca := newCA(t, failingLog{})
+	if leaf, err := ca.Issue(IssueInput{Hosts: []string{"localhost"}}); err == nil || leaf != nil {
+		t.Errorf("Issue() = %v, %v, want an error", leaf, err)
+	}
+}
+
+func TestIssue_PublicKey(t *testing.T) {
+	ca := newCA(t, nil)
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := ca.Issue(IssueInput{Hosts: []string{"localhost"}, PublicKey: key.Public(), ClientAuth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.PrivateKey != nil {
+		t.Error("a private key was generated for a given public key")
+	}
+	if !key.PublicKey.Equal(leaf.Certificate.PublicKey) {
+		t.Error("certificate is not for the given public key")
+	}
+	if !reflect.DeepEqual(leaf.Certificate.ExtKeyUsage, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}) {
+		t.Errorf("ExtKeyUsage = %v", leaf.Certificate.ExtKeyUsage)
+	}
+	if _, err := leaf.PrivateKeyPEM(); err == nil {
+		t.Error("PrivateKeyPEM() without a private key succeeded")
+	}
+}
+
+func TestIssue_TLS(t *testing.T) {
+	ca := newCA(t, nil)
+	leaf, err := ca.Issue(IssueInput{Hosts: []string{"localhost", "127.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := leaf.PrivateKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := tls.X509KeyPair(leaf.CertificatePEM(), keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.RootPEM())
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request error = %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,107 @@
+package devca
+
+import (
+	"bufio"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Log records the certificates issued by a CA.
+type Log interface {
+	Append(r Record) error
+}
+
+// Record is an entry in the issuance log.
+type Record struct {
+	// Serial is the hexadecimal serial number of the certificate.
+	Serial    string    `json:"serial"`
+	Subject   string    `json:"subject"`
+	Hosts     []string  `json:"hosts"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	IssuedAt  time.Time `json:"issued_at"`
+}
+
+func newRecord(cert *x509.Certificate, issuedAt time.Time) Record {
+	r := Record{
+		Serial:    fmt.Sprintf("%x", cert.SerialNumber),
+		Subject:   cert.Subject.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		IssuedAt:  issuedAt.UTC(),
+	}
+	r.Hosts = append(r.Hosts, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		r.Hosts = append(r.Hosts, ip.String())
+	}
+	r.Hosts = append(r.Hosts, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		r.Hosts = append(r.Hosts, u.String())
+	}
+	return r
+}
+
+// FileLog is a Log kept in a file, with a JSON record on each line.
+type FileLog struct {
+	Path string
+}
+
+// Append appends r to the log, creating the file if it does not exist.
+// It returns an error if a certificate with the same serial number has
+// already been issued.
+func (l *FileLog) Append(r Record) error {
+	records, err := l.Records()
+	if err != nil {
+		return err
+	}
+	for _, existing := range records {
+		if existing.Serial == r.Serial {
+			return fmt.Errorf("a certificate with serial number %s has already been issued", r.Serial)
+		}
+	}
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records returns the records in the log, oldest first.
+// It returns nil if the file does not exist.
+func (l *FileLog) Records() ([]Record, error) {
+	f, err := os.Open(l.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.Path, line, err)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,152 @@
+// Package ecdsasig converts ECDSA signatures between the ASN.1 DER
+// encoding of X9.62, returned by the Security framework and crypto/ecdsa,
+// and the fixed-width r || s encoding of IEEE P1363, used by JWS, COSE,
+// WebAuthn and many HSM APIs.
+package ecdsasig
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Options control the conversion of signatures.
+type Options struct {
+	// LowS replaces s with n - s when s is greater than half the order
+	// n of the curve. Both are valid signatures, but some verifiers
+	// only accept the lower, to prevent signatures being malleable.
+	LowS bool
+}
+
+type signature struct {
+	R, S *big.Int
+}
+
+// ParseDER parses an ASN.1 DER encoded signature. Parsing is strict:
+// the encoding must be canonical DER without trailing data, and r and
+// s must be in the range [1, n-1] for the order n of curve.
+func ParseDER(der []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	var sig signature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing DER signature: %w", err)
+	}
+	if len(rest) != 0 {
+		return nil, nil, errors.New("parsing DER signature: trailing data")
+	}
+
+	// encoding/asn1 accepts some BER encodings, so
+	// check that the signature encodes back to itself.
+	canonical, err := asn1.Marshal(sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(canonical, der) {
+		return nil, nil, errors.New("parsing DER signature: not canonical DER")
+	}
+
+	if err := checkScalars(sig.R, sig.S, curve); err != nil {
+		return nil, nil, err
+	}
+	return sig.R, sig.S, nil
+}
+
+// MarshalDER returns the ASN.1 DER encoding of the signature (r, s).
+func MarshalDER(r, s *big.Int) ([]byte, error) {
+	return asn1.Marshal(signature{R: r, S: s})
+}
+
+// ParseP1363 parses an IEEE P1363 signature, which must be exactly
+// twice the byte length of the order of curve.
+func ParseP1363(sig []byte, curve elliptic.Curve) (r, s *big.Int, err error) {
+	size := scalarSize(curve)
+	if len(sig) != 2*size {
+		return nil, nil, fmt.Errorf("parsing P1363 signature: got %d bytes, want %d for %s", len(sig), 2*size, curve.Params().Name)
+	}
+	r = new(big.Int).SetBytes(sig[:size])
+	s = new(big.Int).SetBytes(sig[size:])
+	if err := checkScalars(r, s, curve); err != nil {
+		return nil, nil, err
+	}
+	return r, s, nil
+}
+
+// MarshalP1363 returns the IEEE P1363 encoding of the signature
+// (r, s): both scalars, zero-padded to the byte length of the order
+// of curve.
+func MarshalP1363(r, s *big.Int, curve elliptic.Curve) ([]byte, error) {
+	if err := checkScalars(r, s, curve); err != nil {
+		return nil, err
+	}
+	size := scalarSize(curve)
+	sig := make([]byte, 2*size)
+	r.FillBytes(sig[:size])
+	s.FillBytes(sig[size:])
+	return sig, nil
+}
+
+// ToP1363 converts an ASN.1 DER signature made by a key on curve
+// to IEEE P1363. opts may be nil.
+func ToP1363(der []byte, curve elliptic.Curve, opts *Options) ([]byte, error) {
+	r, s, err := ParseDER(der, curve)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalP1363(r, opts.normalize(s, curve), curve)
+}
+
+// ToDER converts an IEEE P1363 signature made by a key on curve
+// to ASN.1 DER. opts may be nil.
+func ToDER(sig []byte, curve elliptic.Curve, opts *Options) ([]byte, error) {
+	r, s, err := ParseP1363(sig, curve)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalDER(r, opts.normalize(s, curve))
+}
+
+// NormalizeLowS returns der with s replaced by n - s if
+// it is greater than half the order n of curve.
+func NormalizeLowS(der []byte, curve elliptic.Curve) ([]byte, error) {
+	r, s, err := ParseDER(der, curve)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalDER(r, lowS(s, curve))
+}
+
+// IsLowS reports whether s is at most half the order of curve.
+func IsLowS(s *big.Int, curve elliptic.Curve) bool {
+	half := new(big.Int).Rsh(curve.Params().N, 1)
+	return s.Cmp(half) <= 0
+}
+
+func (o *Options) normalize(s *big.Int, curve elliptic.Curve) *big.Int {
+	if o == nil || !o.LowS {
+		return s
+	}
+	return lowS(s, curve)
+}
+
+func lowS(s *big.Int, curve elliptic.Curve) *big.Int {
+	if IsLowS(s, curve) {
+		return s
+	}
+	return new(big.Int).Sub(curve.Params().N, s)
+}
+
+// scalarSize returns the byte length of the order of curve.
+func scalarSize(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 7) / 8
+}
+
+func checkScalars(r, s *big.Int, curve elliptic.Curve) error {
+	n := curve.Params().N
+	if r == nil || s == nil || r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return fmt.Errorf("signature values are out of range for %s", curve.Params().Name)
+	}
+	return nil
+}
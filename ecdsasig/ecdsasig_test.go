@@ -0,0 +1,158 @@
+package ecdsasig
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	digest := sha256.Sum256([]byte("hello"))
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			der, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p1363, err := ToP1363(der, curve, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := 2 * scalarSize(curve); len(p1363) != want {
+				t.Errorf("got %d bytes, want %d", len(p1363), want)
+			}
+
+			back, err := ToDER(p1363, curve, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(back, der) {
+				t.Errorf("ToDER(ToP1363()) = %x, want %x", back, der)
+			}
+
+			low, err := ToP1363(der, curve, &Options{LowS: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, s, err := ParseP1363(low, curve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsLowS(s, curve) {
+				t.Errorf("LowS option returned a high s")
+			}
+			lowDER, err := ToDER(low, curve, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], lowDER) {
+				t.Errorf("low-S signature does not verify")
+			}
+		})
+	}
+}
+
+func TestNormalizeLowS(t *testing.T) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+	digest := sha256.Sum256([]byte("hello"))
+
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := ParseDER(der, curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// make s high, which is still a valid signature.
+	if IsLowS(s, curve) {
+		s = new(big.Int).Sub(n, s)
+	}
+	high, err := MarshalDER(r, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], high) {
+		t.Fatal("high-S signature does not verify")
+	}
+
+	low, err := NormalizeLowS(high, curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, gotS, err := ParseDER(low, curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsLowS(gotS, curve) {
+		t.Errorf("NormalizeLowS() returned a high s")
+	}
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], low) {
+		t.Errorf("normalized signature does not verify")
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	valid, err := MarshalDER(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outOfRange, err := MarshalDER(big.NewInt(1), n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derTests := []struct {
+		name string
+		der  []byte
+	}{
+		{name: "empty", der: nil},
+		{name: "trailing_data", der: append(append([]byte{}, valid...), 0)},
+		// the same signature with a long form length.
+		{name: "long_form_length", der: []byte{0x30, 0x81, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x02}},
+		{name: "negative", der: []byte{0x30, 0x06, 0x02, 0x01, 0xff, 0x02, 0x01, 0x02}},
+		{name: "zero", der: []byte{0x30, 0x06, 0x02, 0x01, 0x00, 0x02, 0x01, 0x02}},
+		{name: "out_of_range", der: outOfRange},
+	}
+	for _, tt := range derTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseDER(tt.der, curve); err == nil {
+				t.Errorf("ParseDER(%x) succeeded", tt.der)
+			}
+		})
+	}
+
+	p1363Tests := []struct {
+		name string
+		sig  []byte
+	}{
+		{name: "short", sig: make([]byte, 63)},
+		{name: "p384_length", sig: make([]byte, 96)},
+		{name: "zero", sig: make([]byte, 64)},
+	}
+	for _, tt := range p1363Tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ToDER(tt.sig, curve, nil); err == nil {
+				t.Errorf("ToDER() of %d bytes succeeded", len(tt.sig))
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package ecies implements the elliptic curve integrated encryption
+// scheme of the Security framework algorithm
+// kSecKeyAlgorithmECIESEncryptionCofactorVariableIVX963SHA256AESGCM.
+//
+// It is pure Go, so that data can be encrypted on any platform to the
+// public key of a Secure Enclave key, which alone can decrypt it with
+// enclavekey.Key.Decrypt.
+//
+// A ciphertext is the ephemeral public key as an uncompressed X9.63
+// point, followed by the AES-GCM ciphertext and its 16 byte tag. The
+// AES key and 16 byte IV are derived from the ECDH shared secret with
+// the ANSI X9.63 KDF and SHA-256, using the ephemeral public key as
+// the shared info. AES-128 is used with P-256 keys and AES-256 with
+// P-384 and P-521 keys.
+package ecies
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/x963kdf"
+)
+
+const (
+	ivSize  = 16
+	tagSize = 16
+)
+
+// Encrypt encrypts plaintext to pub, which must be an *ecdh.PublicKey
+// or *ecdsa.PublicKey on P-256, P-384 or P-521, reading the ephemeral
+// key from rand.
+func Encrypt(rand io.Reader, pub crypto.PublicKey, plaintext []byte) ([]byte, error) {
+	recipient, err := ecdhPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := recipient.Curve().GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(ephemeral, recipient, plaintext)
+}
+
+// Decrypt decrypts a ciphertext encrypted to the public key of priv.
+func Decrypt(priv *ecdh.PrivateKey, ciphertext []byte) ([]byte, error) {
+	pointSize, err := pointSize(priv.Curve())
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < pointSize+tagSize {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	ephemeral, err := priv.Curve().NewPublicKey(ciphertext[:pointSize])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+	secret, err := priv.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(priv.Curve(), secret, ciphertext[:pointSize])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, aead.iv, ciphertext[pointSize:], nil)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+func encrypt(ephemeral *ecdh.PrivateKey, recipient *ecdh.PublicKey, plaintext []byte) ([]byte, error) {
+	secret, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, err
+	}
+	point := ephemeral.PublicKey().Bytes()
+	aead, err := newAEAD(recipient.Curve(), secret, point)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(point, aead.iv, plaintext, nil), nil
+}
+
+type aeadWithIV struct {
+	cipher.AEAD
+	iv []byte
+}
+
+// newAEAD derives the AES-GCM key and IV from the shared secret.
+func newAEAD(curve ecdh.Curve, secret, ephemeralPoint []byte) (*aeadWithIV, error) {
+	keySize := 32
+	if curve == ecdh.P256() {
+		keySize = 16
+	}
+
+	keyIV, err := x963kdf.Key(crypto.SHA256, secret, ephemeralPoint, keySize+ivSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(keyIV[:keySize])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, ivSize)
+	if err != nil {
+		return nil, err
+	}
+	return &aeadWithIV{AEAD: aead, iv: keyIV[keySize:]}, nil
+}
+
+// pointSize returns the length of an uncompressed point on curve.
+func pointSize(curve ecdh.Curve) (int, error) {
+	switch curve {
+	case ecdh.P256():
+		return 65, nil
+	case ecdh.P384():
+		return 97, nil
+	case ecdh.P521():
+		return 133, nil
+	}
+	return 0, fmt.Errorf("unsupported curve %v", curve)
+}
+
+func ecdhPublicKey(pub crypto.PublicKey) (*ecdh.PublicKey, error) {
+	var key *ecdh.PublicKey
+	switch k := pub.(type) {
+	case *ecdh.PublicKey:
+		key = k
+	case *ecdsa.PublicKey:
+		var err error
+		if key, err = k.ECDH(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if _, err := pointSize(key.Curve()); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// softwareKey is a crypto.Decrypter holding a software private key.
+type softwareKey struct {
+	priv *ecdh.PrivateKey
+}
+
+// NewDecrypter returns a crypto.Decrypter which decrypts with the
+// software key priv, as enclavekey.Key does with a Secure Enclave key.
+func NewDecrypter(priv *ecdh.PrivateKey) crypto.Decrypter {
+	return softwareKey{priv: priv}
+}
+
+func (k softwareKey) Public() crypto.PublicKey { return k.priv.PublicKey() }
+
+func (k softwareKey) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if opts != nil {
+		return nil, fmt.Errorf("unsupported decrypter options %T", opts)
+	}
+	return Decrypt(k.priv, ciphertext)
+}
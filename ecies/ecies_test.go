@@ -0,0 +1,137 @@
+package ecies
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// vector pins the ciphertext layout. Its interoperability with the
+// Security framework is tested by enclavekey.TestKey_Decrypt.
+var vector = struct {
+	recipient, ephemeral, plaintext, ciphertext string
+}{
+	recipient:  "665d0698dbc8fb95afc25c3a4d9cf280d87a585b7999243ca6008fd03258975f",
+	ephemeral:  "8341425cafede9d24b0599aefdfdeff1c1526ed75b07217eb99bf8c0b7498b81",
+	plaintext:  "hello, enclave",
+	ciphertext: "049a781ca6d055a7f30d0c9ff87936c739f6816ef5f5e72b4b946404b0a1a83b2ac19f842945ea2bf65aa1649b2b02ff79854c8d5ecfcd403862e8a97ea66c71c1100c70d0eaac20ba1b25f9baeb493e5d380d486a575461a00203563dffa9",
+}
+
+func TestVector(t *testing.T) {
+	recipientBytes, _ := hex.DecodeString(vector.recipient)
+	ephemeralBytes, _ := hex.DecodeString(vector.ephemeral)
+	want, _ := hex.DecodeString(vector.ciphertext)
+
+	recipient, err := ecdh.P256().NewPrivateKey(recipientBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ephemeral, err := ecdh.P256().NewPrivateKey(ephemeralBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := encrypt(ephemeral, recipient.PublicKey(), []byte(vector.plaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encrypt() = %x, want %x", got, want)
+	}
+	if !bytes.Equal(got[:65], ephemeral.PublicKey().Bytes()) {
+		t.Errorf("ciphertext does not start with the ephemeral public key")
+	}
+	if len(got) != 65+len(vector.plaintext)+16 {
+		t.Errorf("got %d bytes of ciphertext", len(got))
+	}
+
+	plaintext, err := Decrypt(recipient, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != vector.plaintext {
+		t.Errorf("Decrypt() = %q, want %q", plaintext, vector.plaintext)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, curve := range []ecdh.Curve{ecdh.P256(), ecdh.P384(), ecdh.P521()} {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			priv, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, plaintext := range [][]byte{nil, []byte("secret"), bytes.Repeat([]byte{1}, 1000)} {
+				ciphertext, err := Encrypt(rand.Reader, priv.PublicKey(), plaintext)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := Decrypt(priv, ciphertext)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, plaintext) {
+					t.Errorf("Decrypt() = %x, want %x", got, plaintext)
+				}
+			}
+		})
+	}
+}
+
+func TestDecrypt_Invalid(t *testing.T) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := Encrypt(rand.Reader, priv.PublicKey(), []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name       string
+		priv       *ecdh.PrivateKey
+		ciphertext []byte
+	}{
+		{name: "tampered", priv: priv, ciphertext: tampered},
+		{name: "truncated", priv: priv, ciphertext: ciphertext[:70]},
+		{name: "wrong_key", priv: other, ciphertext: ciphertext},
+		{name: "bad_point", priv: priv, ciphertext: append(make([]byte, 65), ciphertext[65:]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(tt.priv, tt.ciphertext); err == nil {
+				t.Errorf("Decrypt() succeeded")
+			}
+		})
+	}
+}
+
+func TestNewDecrypter(t *testing.T) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecrypter(priv)
+	ciphertext, err := Encrypt(rand.Reader, d.Public(), []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.Decrypt(nil, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("Decrypt() = %q, want %q", got, "secret")
+	}
+}
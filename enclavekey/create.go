@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"unsafe"
 
+	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 type CreateInput struct {
@@ -29,10 +31,23 @@ type CreateInput struct {
 	Tag string
 
 	Label string
+
+	// AccessGroup corresponds to kSecAttrAccessGroup. It must be
+	// listed in the keychain-access-groups entitlement of the binary.
+	// Keys in the Secure Enclave are never synchronised with iCloud Keychain.
+	AccessGroup string
 }
 
 // Create creates a new ECDSA P-256 key backed by the Secure Enclave.
 func Create(input CreateInput) (*Key, error) {
+	key, err := create(input)
+	if err != nil {
+		return nil, opError("Create", input.Tag, input.Label, input.AccessGroup, err)
+	}
+	return key, nil
+}
+
+func create(input CreateInput) (*Key, error) {
 	protection := C.kSecAttrAccessibleWhenUnlockedThisDeviceOnly
 	flags := C.kSecAccessControlPrivateKeyUsage
 
@@ -65,11 +80,19 @@ func Create(input CreateInput) (*Key, error) {
 	}
 	defer C.CFRelease(C.CFTypeRef(access))
 
-	privKeyAttrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
+	pm := corefoundation.Dictionary{
 		corefoundation.TypeRef(C.kSecAttrAccessControl):  corefoundation.TypeRef(access),
 		corefoundation.TypeRef(C.kSecAttrApplicationTag): corefoundation.TypeRef(cfTag),
 		corefoundation.TypeRef(C.kSecAttrIsPermanent):    corefoundation.TypeRef(C.kCFBooleanTrue),
-	})
+	}
+
+	release, err := itemattr.AddScope(pm, applesecurity.Scope{AccessGroup: input.AccessGroup}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	privKeyAttrs, err := corefoundation.NewCFDictionary(pm)
 	if err != nil {
 		return nil, err
 	}
@@ -117,17 +140,26 @@ func Create(input CreateInput) (*Key, error) {
 	defer C.CFRelease(C.CFTypeRef(keyAttrs))
 
 	publicKeyData := C.CFDataRef(C.CFDictionaryGetValue(keyAttrs, unsafe.Pointer(C.kSecValueData)))
+	if publicKeyData == nilCFData {
+		return nil, fmt.Errorf("cannot extract public key")
+	}
 
 	keyBytes := C.GoBytes(
 		unsafe.Pointer(C.CFDataGetBytePtr(publicKeyData)),
 		C.int(C.CFDataGetLength(publicKeyData)),
 	)
 
+	publicKey, err := rawToEcdsa(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+
 	key := Key{
-		PublicKey:        rawToEcdsa(keyBytes),
+		PublicKey:        publicKey,
 		ApplicationLabel: corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(keyAttrs), corefoundation.DataRef(C.kSecAttrApplicationLabel)),
 		Tag:              input.Tag,
 		Label:            input.Label,
+		AccessGroup:      input.AccessGroup,
 	}
 
 	return &key, nil
@@ -0,0 +1,59 @@
+package enclavekey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+// Decrypt decrypts ciphertext encrypted to the public key of the key
+// with kSecKeyAlgorithmECIESEncryptionCofactorVariableIVX963SHA256AESGCM,
+// such as by ecies.Encrypt, which encrypts on any platform.
+//
+// opts must be nil. Keys created with UserPresence prompt the user
+// to authenticate, as when signing.
+func (k *Key) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	plaintext, err := k.decrypt(ciphertext, opts)
+	if err != nil {
+		return nil, opError("Decrypt", k.Tag, k.Label, k.AccessGroup, err)
+	}
+	return plaintext, nil
+}
+
+func (k *Key) decrypt(ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if opts != nil {
+		return nil, fmt.Errorf("unsupported decrypter options %T", opts)
+	}
+
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	cfCiphertext, err := corefoundation.NewCFData(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfCiphertext))
+
+	var eref C.CFErrorRef
+	plaintext := C.SecKeyCreateDecryptedData(key, C.kSecKeyAlgorithmECIESEncryptionCofactorVariableIVX963SHA256AESGCM, C.CFDataRef(cfCiphertext), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(plaintext))
+
+	return corefoundation.CFDataToBytes(corefoundation.DataRef(plaintext)), nil
+}
@@ -0,0 +1,97 @@
+package enclavekey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/envelope"
+)
+
+func TestKey_Decrypt(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.decrypt"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+
+	var _ crypto.Decrypter = k
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "empty", plaintext: nil},
+		{name: "short", plaintext: []byte("hello, enclave")},
+		{name: "long", plaintext: bytes.Repeat([]byte("secret"), 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// encrypted by the pure Go encryptor, as on a server.
+			ciphertext, err := ecies.Encrypt(rand.Reader, k.PublicKey, tt.plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := k.Decrypt(nil, ciphertext, nil)
+			if err != nil {
+				t.Fatalf("Key.Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("Key.Decrypt() = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+
+	ciphertext, err := ecies.Encrypt(rand.Reader, k.PublicKey, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := k.Decrypt(nil, ciphertext, nil); err == nil {
+		t.Errorf("Key.Decrypt() of a tampered ciphertext succeeded")
+	}
+}
+
+func TestKey_Envelope(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.envelope"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+
+	// a teammate's device, with a software key.
+	teammate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := envelope.Seal(rand.Reader, []byte("secret"), teammate.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddRecipients(rand.Reader, ecies.NewDecrypter(teammate), k.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.Open(k)
+	if err != nil {
+		t.Fatalf("Envelope.Open() error = %v", err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("Envelope.Open() = %q, want %q", got, "secret")
+	}
+}
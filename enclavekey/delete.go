@@ -8,11 +8,33 @@ package enclavekey
 */
 import "C"
 
-import "github.com/common-fate/go-apple-security/corefoundation"
+import (
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
 
 type DeleteInput struct {
 	Tag   string
 	Label string
+
+	// AccessGroup restricts deletion to one keychain access group.
+	AccessGroup string
+
+	// ApplicationLabel selects exactly one key by its
+	// kSecAttrApplicationLabel, such as Key.ApplicationLabel or the
+	// result of pubkey.ApplicationLabel. If it is set, Tag and Label
+	// are ignored.
+	ApplicationLabel []byte
+
+	// Fingerprint selects exactly one key by the SHA-256 fingerprint of
+	// its public key, such as "SHA256:m54VEXjwa0KXFQ+kHMehe3Ry/bPm1FfHOwC8pz7oOkI"
+	// from Key.Fingerprint or ssh-keygen -l. If it is set, Tag, Label
+	// and ApplicationLabel are ignored.
+	Fingerprint string
+
+	// PersistentRef selects exactly one key. If it is
+	// set, the other criteria are ignored.
+	PersistentRef applesecurity.PersistentRef
 }
 
 // Delete keys in the keychain matching the criteria in DeleteInput.
@@ -22,28 +44,39 @@ type DeleteInput struct {
 // Returns a count of deleted keys. Returns ErrNotFound if no
 // keys were found matching the criteria.
 func Delete(input DeleteInput) (int, error) {
-	cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+	deleted, err := deleteKeys(input)
 	if err != nil {
-		return 0, err
-	}
-	defer C.CFRelease(C.CFTypeRef(cfTag))
-
-	m := corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):              corefoundation.TypeRef(C.kSecClassKey),
-		corefoundation.TypeRef(C.kSecAttrKeyType):        corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
-		corefoundation.TypeRef(C.kSecAttrApplicationTag): corefoundation.TypeRef(cfTag),
-		corefoundation.TypeRef(C.kSecAttrKeyClass):       corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+		return deleted, opError("Delete", input.Tag, input.Label, input.AccessGroup, err)
 	}
+	return deleted, nil
+}
 
-	if input.Label != "" {
-		cfLabel, err := corefoundation.NewCFString(input.Label)
+func deleteKeys(input DeleteInput) (int, error) {
+	if input.Fingerprint != "" && input.PersistentRef.IsZero() {
+		key, err := findByFingerprint(input.Fingerprint, input.AccessGroup)
 		if err != nil {
 			return 0, err
 		}
-		defer C.CFRelease(C.CFTypeRef(cfLabel))
+		input = DeleteInput{PersistentRef: key.PersistentRef}
+	}
+
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):        corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyType):  corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
+		corefoundation.TypeRef(C.kSecAttrKeyClass): corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+	}
 
-		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	release, err := addCriteria(m, criteria{
+		tag:              input.Tag,
+		label:            input.Label,
+		accessGroup:      input.AccessGroup,
+		applicationLabel: input.ApplicationLabel,
+		persistentRef:    input.PersistentRef,
+	})
+	if err != nil {
+		return 0, err
 	}
+	defer release()
 
 	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
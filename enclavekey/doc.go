@@ -1,3 +1,8 @@
 // Package enclavekey contains methods to
 // work with keys backed by the Secure Enclave.
+//
+// The package uses the Security framework, so it only builds on macOS,
+// and it has no software implementation of its keys for other
+// platforms. Code which only needs a crypto.Signer or crypto.Decrypter
+// can be tested with software keys instead.
 package enclavekey
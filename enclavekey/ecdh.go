@@ -0,0 +1,158 @@
+package enclavekey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+// ECDH performs an ECDH key agreement with peer, which must be a P-256
+// key, and returns the shared secret: the X coordinate of the shared
+// point, as returned by crypto/ecdh.PrivateKey.ECDH.
+//
+// The shared secret should be passed through a KDF before it is used as
+// a key, such as by DeriveKey.
+func (k *Key) ECDH(peer *ecdh.PublicKey) ([]byte, error) {
+	secret, err := k.keyExchange(peer, C.kSecKeyAlgorithmECDHKeyExchangeStandard, nil)
+	if err != nil {
+		return nil, opError("ECDH", k.Tag, k.Label, k.AccessGroup, err)
+	}
+	return secret, nil
+}
+
+// DeriveKey performs an ECDH key agreement with peer and derives a key of
+// length bytes from the shared secret with the ANSI X9.63 KDF, using hash
+// and the optional sharedInfo. The result matches x963kdf.Key applied to
+// the result of crypto/ecdh.PrivateKey.ECDH by the peer.
+//
+// hash must be SHA-1, SHA-224, SHA-256, SHA-384 or SHA-512.
+func (k *Key) DeriveKey(peer *ecdh.PublicKey, hash crypto.Hash, sharedInfo []byte, length int) ([]byte, error) {
+	key, err := k.deriveKey(peer, hash, sharedInfo, length)
+	if err != nil {
+		return nil, opError("DeriveKey", k.Tag, k.Label, k.AccessGroup, err)
+	}
+	return key, nil
+}
+
+func (k *Key) deriveKey(peer *ecdh.PublicKey, hash crypto.Hash, sharedInfo []byte, length int) ([]byte, error) {
+	// P-256 has a cofactor of 1, so the cofactor variants
+	// of these algorithms return the same keys.
+	var algorithm C.SecKeyAlgorithm
+	switch hash {
+	case crypto.SHA1:
+		algorithm = C.kSecKeyAlgorithmECDHKeyExchangeStandardX963SHA1
+	case crypto.SHA224:
+		algorithm = C.kSecKeyAlgorithmECDHKeyExchangeStandardX963SHA224
+	case crypto.SHA256:
+		algorithm = C.kSecKeyAlgorithmECDHKeyExchangeStandardX963SHA256
+	case crypto.SHA384:
+		algorithm = C.kSecKeyAlgorithmECDHKeyExchangeStandardX963SHA384
+	case crypto.SHA512:
+		algorithm = C.kSecKeyAlgorithmECDHKeyExchangeStandardX963SHA512
+	default:
+		return nil, fmt.Errorf("unsupported hash function %v", hash)
+	}
+	if length <= 0 {
+		return nil, errors.New("key length must be positive")
+	}
+
+	cfSize, err := corefoundation.NewCFNumber(length)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfSize))
+
+	parameters := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecKeyKeyExchangeParameterRequestedSize): corefoundation.TypeRef(cfSize),
+	}
+	if len(sharedInfo) > 0 {
+		cfSharedInfo, err := corefoundation.NewCFData(sharedInfo)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfSharedInfo))
+		parameters[corefoundation.TypeRef(C.kSecKeyKeyExchangeParameterSharedInfo)] = corefoundation.TypeRef(cfSharedInfo)
+	}
+
+	return k.keyExchange(peer, algorithm, parameters)
+}
+
+// keyExchange performs a key agreement with peer using algorithm
+// and the optional parameters.
+func (k *Key) keyExchange(peer *ecdh.PublicKey, algorithm C.SecKeyAlgorithm, parameters corefoundation.Dictionary) ([]byte, error) {
+	if peer == nil {
+		return nil, errors.New("peer public key is nil")
+	}
+	if peer.Curve() != ecdh.P256() {
+		return nil, fmt.Errorf("peer public key must be on P-256 but is on %v", peer.Curve())
+	}
+
+	peerKey, err := createPublicKey(peer.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(peerKey))
+
+	cfParameters, err := corefoundation.NewCFDictionary(parameters)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfParameters))
+
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	var eref C.CFErrorRef
+	result := C.SecKeyCopyKeyExchangeResult(key, algorithm, peerKey, C.CFDictionaryRef(cfParameters), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(result))
+
+	return corefoundation.CFDataToBytes(corefoundation.DataRef(result)), nil
+}
+
+// createPublicKey creates a SecKey from the X9.63 point of a P-256
+// public key. The caller must release it.
+func createPublicKey(point []byte) (C.SecKeyRef, error) {
+	cfPoint, err := corefoundation.NewCFData(point)
+	if err != nil {
+		return nilSecKey, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfPoint))
+
+	attrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrKeyType):  corefoundation.TypeRef(C.kSecAttrKeyTypeECSECPrimeRandom),
+		corefoundation.TypeRef(C.kSecAttrKeyClass): corefoundation.TypeRef(C.kSecAttrKeyClassPublic),
+	})
+	if err != nil {
+		return nilSecKey, err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	var eref C.CFErrorRef
+	key := C.SecKeyCreateWithData(C.CFDataRef(cfPoint), C.CFDictionaryRef(attrs), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nilSecKey, err
+	}
+	if key == nilSecKey {
+		return nilSecKey, errors.New("error creating peer public key")
+	}
+	return key, nil
+}
@@ -0,0 +1,142 @@
+package enclavekey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"errors"
+	"testing"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/hpke"
+	"github.com/common-fate/go-apple-security/x963kdf"
+)
+
+func TestKey_ECDH(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.ecdh"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+	enclavePub, err := k.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peer, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := peer.ECDH(enclavePub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := k.ECDH(peer.PublicKey())
+	if err != nil {
+		t.Fatalf("Key.ECDH() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Key.ECDH() = %x, want %x", got, want)
+	}
+
+	tests := []struct {
+		name       string
+		hash       crypto.Hash
+		sharedInfo []byte
+		length     int
+	}{
+		{name: "sha256", hash: crypto.SHA256, length: 32},
+		{name: "sha256_shared_info", hash: crypto.SHA256, sharedInfo: []byte("session"), length: 16},
+		{name: "sha384_multiple_blocks", hash: crypto.SHA384, length: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantKey, err := x963kdf.Key(tt.hash, want, tt.sharedInfo, tt.length)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotKey, err := k.DeriveKey(peer.PublicKey(), tt.hash, tt.sharedInfo, tt.length)
+			if err != nil {
+				t.Fatalf("Key.DeriveKey() error = %v", err)
+			}
+			if !bytes.Equal(gotKey, wantKey) {
+				t.Errorf("Key.DeriveKey() = %x, want %x", gotKey, wantKey)
+			}
+		})
+	}
+
+	other, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.ECDH(other.PublicKey()); err == nil {
+		t.Errorf("Key.ECDH() with an X25519 peer succeeded")
+	}
+}
+
+func TestKey_HPKE(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.hpke"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+	pkR, err := k.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	skS, err := hpke.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := []byte("device message")
+
+	for _, aead := range []hpke.AEAD{hpke.AES128GCM, hpke.ChaCha20Poly1305} {
+		t.Run(aead.String(), func(t *testing.T) {
+			enc, sender, err := hpke.NewSender(rand.Reader, pkR, aead, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct, err := sender.Seal(nil, []byte("base"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			recipient, err := hpke.NewRecipient(enc, k, aead, info)
+			if err != nil {
+				t.Fatalf("NewRecipient() error = %v", err)
+			}
+			if pt, err := recipient.Open(nil, ct); err != nil || string(pt) != "base" {
+				t.Errorf("Open() = %q, %v", pt, err)
+			}
+
+			enc, sender, err = hpke.NewAuthSender(rand.Reader, pkR, skS, aead, info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ct, err = sender.Seal(nil, []byte("auth"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			recipient, err = hpke.NewAuthRecipient(enc, k, skS.PublicKey(), aead, info)
+			if err != nil {
+				t.Fatalf("NewAuthRecipient() error = %v", err)
+			}
+			if pt, err := recipient.Open(nil, ct); err != nil || string(pt) != "auth" {
+				t.Errorf("Open() = %q, %v", pt, err)
+			}
+		})
+	}
+}
@@ -12,31 +12,27 @@ import (
 	"fmt"
 
 	applesecurity "github.com/common-fate/go-apple-security"
-)
-
-const (
-	nilCFError  C.CFErrorRef  = 0
-	nilCFString C.CFStringRef = 0
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/secerror"
 )
 
 func goError(e interface{}) error {
-	if e == nil {
-		return nil
-	}
-
 	switch v := e.(type) {
 	case C.OSStatus:
-		return applesecurity.ErrorFromCode(int(v))
-
+		return secerror.FromStatus(int32(v))
 	case C.CFErrorRef:
-		if v == nilCFError {
-			return nil
-		}
-
-		code := int(C.CFErrorGetCode(v))
-
-		return applesecurity.ErrorFromCode(code)
+		return secerror.FromCFError(corefoundation.ErrorRef(v))
 	}
-
 	return fmt.Errorf("unknown error type %T", e)
 }
+
+// opError annotates err with the operation and the key it failed on.
+func opError(op, tag, label, accessGroup string, err error) error {
+	return &applesecurity.OpError{
+		Op:          op,
+		Tag:         tag,
+		Label:       label,
+		AccessGroup: accessGroup,
+		Err:         err,
+	}
+}
@@ -0,0 +1,56 @@
+package enclavekey
+
+import (
+	"github.com/common-fate/go-apple-security/pubkey"
+	"github.com/common-fate/go-apple-security/verify"
+)
+
+// The private key never leaves the Secure Enclave, so these methods
+// export the public key, in the formats provided by the pubkey package.
+
+// PublicKeyPKIX returns the public key as PKIX, ASN.1 DER SubjectPublicKeyInfo.
+func (k *Key) PublicKeyPKIX() ([]byte, error) {
+	return pubkey.MarshalPKIX(k.PublicKey)
+}
+
+// PublicKeyPEM returns the public key as a PEM "PUBLIC KEY" block.
+func (k *Key) PublicKeyPEM() ([]byte, error) {
+	return pubkey.MarshalPEM(k.PublicKey)
+}
+
+// PublicKeyJWK returns the public key as a JSON Web Key,
+// with its key ID set to the JWK thumbprint.
+func (k *Key) PublicKeyJWK() ([]byte, error) {
+	return pubkey.MarshalJWK(k.PublicKey)
+}
+
+// Thumbprint returns the base64url encoded RFC 7638
+// SHA-256 JWK thumbprint of the public key.
+func (k *Key) Thumbprint() (string, error) {
+	return pubkey.Thumbprint(k.PublicKey)
+}
+
+// AuthorizedKey returns the public key as a line of an
+// OpenSSH authorized_keys file, with an optional comment.
+func (k *Key) AuthorizedKey(comment string) ([]byte, error) {
+	return pubkey.MarshalAuthorizedKey(k.PublicKey, comment)
+}
+
+// PublicKeyX963 returns the public key as the uncompressed X9.63
+// point used by the Security framework.
+func (k *Key) PublicKeyX963() ([]byte, error) {
+	return pubkey.MarshalX963(k.PublicKey)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the public key as
+// OpenSSH displays it, which selects the key in GetInput and DeleteInput.
+func (k *Key) Fingerprint() (string, error) {
+	return pubkey.Fingerprint(k.PublicKey)
+}
+
+// Verify checks that sig, DER or P1363 encoded, is a signature of digest
+// by the key. Only the public key is used, so the user is not prompted.
+// The hash function is inferred from the length of digest.
+func (k *Key) Verify(digest, sig []byte) error {
+	return verify.Verify(k.PublicKey, digest, sig, nil)
+}
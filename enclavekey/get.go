@@ -12,39 +12,68 @@ import (
 	"fmt"
 	"unsafe"
 
+	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
 )
 
 type GetInput struct {
 	Tag   string
 	Label string
+
+	// AccessGroup restricts the search to one keychain access group.
+	AccessGroup string
+
+	// ApplicationLabel selects exactly one key by its
+	// kSecAttrApplicationLabel, such as Key.ApplicationLabel or the
+	// result of pubkey.ApplicationLabel. If it is set, Tag and Label
+	// are ignored.
+	ApplicationLabel []byte
+
+	// Fingerprint selects exactly one key by the SHA-256 fingerprint of
+	// its public key, such as "SHA256:m54VEXjwa0KXFQ+kHMehe3Ry/bPm1FfHOwC8pz7oOkI"
+	// from Key.Fingerprint or ssh-keygen -l. If it is set, Tag, Label
+	// and ApplicationLabel are ignored.
+	Fingerprint string
+
+	// PersistentRef selects exactly one key. If it is
+	// set, the other criteria are ignored.
+	PersistentRef applesecurity.PersistentRef
 }
 
 func Get(input GetInput) (*Key, error) {
-	cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+	key, err := get(input)
 	if err != nil {
-		return nil, err
+		return nil, opError("Get", input.Tag, input.Label, input.AccessGroup, err)
+	}
+	return key, nil
+}
+
+func get(input GetInput) (*Key, error) {
+	if input.Fingerprint != "" && input.PersistentRef.IsZero() {
+		return findByFingerprint(input.Fingerprint, input.AccessGroup)
 	}
-	defer C.CFRelease(C.CFTypeRef(cfTag))
 
 	m := corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):              corefoundation.TypeRef(C.kSecClassKey),
-		corefoundation.TypeRef(C.kSecAttrKeyType):        corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
-		corefoundation.TypeRef(C.kSecAttrApplicationTag): corefoundation.TypeRef(cfTag),
-		corefoundation.TypeRef(C.kSecAttrKeyClass):       corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
-		corefoundation.TypeRef(C.kSecReturnRef):          corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecMatchLimit):         corefoundation.TypeRef(C.kSecMatchLimitOne),
+		corefoundation.TypeRef(C.kSecClass):               corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyType):         corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
+		corefoundation.TypeRef(C.kSecAttrKeyClass):        corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+		corefoundation.TypeRef(C.kSecReturnRef):           corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit):          corefoundation.TypeRef(C.kSecMatchLimitOne),
 	}
 
-	if input.Label != "" {
-		cfLabel, err := corefoundation.NewCFString(input.Label)
-		if err != nil {
-			return nil, err
-		}
-		defer C.CFRelease(C.CFTypeRef(cfLabel))
-
-		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	release, err := addCriteria(m, criteria{
+		tag:              input.Tag,
+		label:            input.Label,
+		accessGroup:      input.AccessGroup,
+		applicationLabel: input.ApplicationLabel,
+		persistentRef:    input.PersistentRef,
+	})
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
@@ -52,24 +81,18 @@ func Get(input GetInput) (*Key, error) {
 	}
 	defer C.CFRelease(C.CFTypeRef(query))
 
-	var key C.CFTypeRef
-	status := C.SecItemCopyMatching(C.CFDictionaryRef(query), &key)
+	var item C.CFTypeRef
+	status := C.SecItemCopyMatching(C.CFDictionaryRef(query), &item)
 	if err := goError(status); err != nil {
 		return nil, err
 	}
+	defer C.CFRelease(item)
 
-	pubkey, err := extractPubKey(C.SecKeyRef(key))
+	result, err := convertResult(C.CFDictionaryRef(item))
 	if err != nil {
 		return nil, err
 	}
 
-	result := Key{
-		PublicKey:        rawToEcdsa(pubkey.Key),
-		ApplicationLabel: pubkey.ApplicationLabel,
-		Tag:              input.Tag,
-		Label:            input.Label,
-	}
-
 	return &result, nil
 }
 
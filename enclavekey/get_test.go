@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/pubkey"
 )
 
 func TestGet(t *testing.T) {
@@ -76,3 +77,133 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGet_PersistentRef(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.persistentref"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	// tags are not unique, create two keys sharing one.
+	for i := 0; i < 2; i++ {
+		if _, err := Create(CreateInput{Tag: tag}); err != nil {
+			t.Fatalf("error creating key: %v", err)
+		}
+	}
+
+	keys, err := List(ListInput{Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("wanted 2 keys but got %v", len(keys))
+	}
+
+	for _, key := range keys {
+		got, err := Get(GetInput{PersistentRef: key.PersistentRef})
+		if err != nil {
+			t.Fatalf("Get() by reference error = %v", err)
+		}
+		if !bytes.Equal(got.ApplicationLabel, key.ApplicationLabel) {
+			t.Errorf("got ApplicationLabel = %x, want = %x", got.ApplicationLabel, key.ApplicationLabel)
+		}
+	}
+
+	if err := Update(UpdateInput{PersistentRef: keys[0].PersistentRef, Label: "renamed"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := Get(GetInput{PersistentRef: keys[0].PersistentRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "renamed" {
+		t.Errorf("got Label = %q, want %q", got.Label, "renamed")
+	}
+
+	deleted, err := Delete(DeleteInput{PersistentRef: keys[0].PersistentRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 key deleted but got %v", deleted)
+	}
+
+	if _, err := Get(GetInput{PersistentRef: keys[1].PersistentRef}); err != nil {
+		t.Errorf("Get() of remaining key error = %v", err)
+	}
+}
+
+func TestGet_ApplicationLabelAndFingerprint(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.fingerprint"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	// tags are not unique, create two keys sharing one.
+	var keys []*Key
+	for i := 0; i < 2; i++ {
+		key, err := Create(CreateInput{Tag: tag})
+		if err != nil {
+			t.Fatalf("error creating key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	for _, key := range keys {
+		// the label computed in Go matches the one assigned by the keychain.
+		label, err := pubkey.ApplicationLabel(key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(label, key.ApplicationLabel) {
+			t.Errorf("pubkey.ApplicationLabel() = %x, want = %x", label, key.ApplicationLabel)
+		}
+
+		got, err := Get(GetInput{ApplicationLabel: label})
+		if err != nil {
+			t.Fatalf("Get() by ApplicationLabel error = %v", err)
+		}
+		if !got.PublicKey.Equal(key.PublicKey) {
+			t.Errorf("Get() by ApplicationLabel returned another key")
+		}
+
+		fingerprint, err := key.Fingerprint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = Get(GetInput{Fingerprint: fingerprint})
+		if err != nil {
+			t.Fatalf("Get() by Fingerprint error = %v", err)
+		}
+		if !got.PublicKey.Equal(key.PublicKey) {
+			t.Errorf("Get() by Fingerprint returned another key")
+		}
+	}
+
+	fingerprint, err := keys[0].Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := Delete(DeleteInput{Fingerprint: fingerprint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 key deleted but got %v", deleted)
+	}
+	if _, err := Get(GetInput{Fingerprint: fingerprint}); !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Errorf("Get() of deleted key error = %v, want ErrItemNotFound", err)
+	}
+
+	deleted, err = Delete(DeleteInput{ApplicationLabel: keys[1].ApplicationLabel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 key deleted but got %v", deleted)
+	}
+}
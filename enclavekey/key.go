@@ -12,6 +12,13 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/pubkey"
 )
 
 const (
@@ -25,24 +32,124 @@ const (
 // Key is a NIST P-256 elliptic curve key
 // backed by the secure enclave.
 type Key struct {
-	// ApplicationLabel is used to look up a key programmatically
-	// and is the hash of a key
+	// ApplicationLabel is used to look up a key programmatically.
+	// It is the SHA-1 hash of the X9.63 public key and, unlike the
+	// tag and label, identifies exactly one key.
 	ApplicationLabel []byte
 	PublicKey        *ecdsa.PublicKey
 	Tag              string
 	Label            string
+	// AccessGroup is the keychain access group of the key.
+	AccessGroup string
+	// PersistentRef refers to exactly this key. It is
+	// set on keys returned by Get and List.
+	PersistentRef applesecurity.PersistentRef
 	// LAContext is the authentication context
 	// to use when signing with this key.
 	LAContext *LAContext
+	// SignatureEncoding is the encoding of the signatures returned
+	// by Sign and SignMessage. Keep the default, SignatureDER, when
+	// passing the key to crypto/x509 or crypto/tls as a crypto.Signer.
+	SignatureEncoding SignatureEncoding
+}
+
+// rawToEcdsa parses the uncompressed X9.63 point of a P-256 public key,
+// returning an error if it is malformed or not on the curve.
+func rawToEcdsa(raw []byte) (*ecdsa.PublicKey, error) {
+	ecKey, err := pubkey.ParseX963(raw)
+	if err != nil {
+		return nil, err
+	}
+	if ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("expected a P-256 public key but got %s", ecKey.Curve.Params().Name)
+	}
+	return ecKey, nil
+}
+
+// criteria select the keys of a query.
+type criteria struct {
+	tag         string
+	label       string
+	accessGroup string
+
+	// applicationLabel selects one key by its kSecAttrApplicationLabel,
+	// instead of by tag and label.
+	applicationLabel []byte
+
+	// anyTag matches keys whatever their tag, rather
+	// than only those with an empty one.
+	anyTag bool
+
+	persistentRef applesecurity.PersistentRef
+}
+
+// addCriteria adds the attributes selecting keys to the query m: the
+// persistent reference if it is set, or else the application label or
+// the tag and label, and the access group. The caller must call release
+// once m is no longer used.
+func addCriteria(m corefoundation.Dictionary, c criteria) (release func(), err error) {
+	if !c.persistentRef.IsZero() {
+		return itemattr.AddPersistentRef(m, c.persistentRef, applesecurity.DataProtectionKeychain)
+	}
+
+	var releases []func()
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+
+	switch {
+	case len(c.applicationLabel) > 0:
+		cfAppLabel, err := corefoundation.NewCFData(c.applicationLabel)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, func() { C.CFRelease(C.CFTypeRef(cfAppLabel)) })
+		m[corefoundation.TypeRef(C.kSecAttrApplicationLabel)] = corefoundation.TypeRef(cfAppLabel)
+
+	case c.anyTag:
+		// leave out the tag attribute to match any.
+
+	default:
+		cfTag, err := corefoundation.NewCFData([]byte(c.tag))
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, func() { C.CFRelease(C.CFTypeRef(cfTag)) })
+		m[corefoundation.TypeRef(C.kSecAttrApplicationTag)] = corefoundation.TypeRef(cfTag)
+	}
+
+	if c.label != "" && len(c.applicationLabel) == 0 {
+		cfLabel, err := corefoundation.NewCFString(c.label)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, func() { C.CFRelease(C.CFTypeRef(cfLabel)) })
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	releaseScope, err := itemattr.AddScope(m, applesecurity.Scope{AccessGroup: c.accessGroup}, true)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	releases = append(releases, releaseScope)
+
+	return release, nil
 }
 
-// rawToEcdsa turns an ASN.1 encoded byte stream to an ecdsa public key
-// It is assumed that the curve of the key is P-256
-func rawToEcdsa(raw []byte) *ecdsa.PublicKey {
-	ecKey := new(ecdsa.PublicKey)
-	ecKey.Curve = elliptic.P256()
-	ecKey.X, ecKey.Y = elliptic.Unmarshal(ecKey.Curve, raw)
-	return ecKey
+// applicationLabel returns the application label of the key,
+// computing it from the public key if it is not set.
+func (k *Key) applicationLabel() ([]byte, error) {
+	if len(k.ApplicationLabel) > 0 {
+		return k.ApplicationLabel, nil
+	}
+	if k.PublicKey == nil {
+		return nil, errors.New("key has neither an ApplicationLabel nor a PublicKey")
+	}
+	return pubkey.ApplicationLabel(k.PublicKey)
 }
 
 // Public returns the public key of this key
@@ -15,42 +15,50 @@ import (
 
 	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/pubkey"
 )
 
 type ListInput struct {
 	Tag   string
 	Label string
+
+	// AccessGroup restricts the search to one keychain access group.
+	AccessGroup string
 }
 
 // List keys matching the criteria specified in ListInput.
 //
 // Returns nil if no keys are found.
 func List(input ListInput) ([]Key, error) {
-	cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+	keys, err := list(input)
 	if err != nil {
-		return nil, err
+		return nil, opError("List", input.Tag, input.Label, input.AccessGroup, err)
 	}
-	defer C.CFRelease(C.CFTypeRef(cfTag))
+	return keys, nil
+}
+
+func list(input ListInput) ([]Key, error) {
+	return find(criteria{tag: input.Tag, label: input.Label, accessGroup: input.AccessGroup})
+}
 
+// find returns the keys matching c, or nil if there are none.
+func find(c criteria) ([]Key, error) {
 	m := corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):              corefoundation.TypeRef(C.kSecClassKey),
-		corefoundation.TypeRef(C.kSecAttrKeyType):        corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
-		corefoundation.TypeRef(C.kSecAttrApplicationTag): corefoundation.TypeRef(cfTag),
-		corefoundation.TypeRef(C.kSecAttrKeyClass):       corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
-		corefoundation.TypeRef(C.kSecReturnRef):          corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecMatchLimit):         corefoundation.TypeRef(C.kSecMatchLimitAll),
-		corefoundation.TypeRef(C.kSecReturnAttributes):   corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecClass):               corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyType):         corefoundation.TypeRef(C.kSecAttrKeyTypeEC),
+		corefoundation.TypeRef(C.kSecAttrKeyClass):        corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+		corefoundation.TypeRef(C.kSecReturnRef):           corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit):          corefoundation.TypeRef(C.kSecMatchLimitAll),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
 	}
 
-	if input.Label != "" {
-		cfLabel, err := corefoundation.NewCFString(input.Label)
-		if err != nil {
-			return nil, err
-		}
-		defer C.CFRelease(C.CFTypeRef(cfLabel))
-
-		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	release, err := addCriteria(m, c)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
@@ -94,9 +102,27 @@ func List(input ListInput) ([]Key, error) {
 	return results, nil
 }
 
+// findByFingerprint returns the key in accessGroup, or in any access
+// group if it is empty, whose public key has the SHA-256 fingerprint.
+//
+// The fingerprint is not stored with the key, so all
+// keys are listed to compare their fingerprints.
+func findByFingerprint(fingerprint, accessGroup string) (*Key, error) {
+	keys, err := find(criteria{accessGroup: accessGroup, anyTag: true})
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		if pubkey.FingerprintMatches(keys[i].PublicKey, fingerprint) {
+			return &keys[i], nil
+		}
+	}
+	return nil, applesecurity.ErrItemNotFound
+}
+
 func convertResult(d C.CFDictionaryRef) (Key, error) {
 	keyRef := C.SecKeyRef(C.CFDictionaryGetValue(d, unsafe.Pointer(C.CFStringRef(C.kSecValueRef))))
-	pubkey, err := extractPubKey(keyRef)
+	pub, err := extractPubKey(keyRef)
 	if err != nil {
 		return Key{}, err
 	}
@@ -105,8 +131,13 @@ func convertResult(d C.CFDictionaryRef) (Key, error) {
 	result.Label = corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(d), corefoundation.StringRef(C.kSecAttrLabel))
 	result.Tag = string(corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(d), corefoundation.DataRef(C.kSecAttrApplicationTag)))
 	result.ApplicationLabel = corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(d), corefoundation.DataRef(C.kSecAttrApplicationLabel))
+	result.AccessGroup = corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(d), corefoundation.StringRef(C.kSecAttrAccessGroup))
+	result.PersistentRef = itemattr.PersistentRef(corefoundation.DictionaryRef(d))
 
-	result.PublicKey = rawToEcdsa(pubkey.Key)
+	result.PublicKey, err = rawToEcdsa(pub.Key)
+	if err != nil {
+		return Key{}, err
+	}
 
 	return result, nil
 }
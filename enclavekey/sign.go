@@ -23,22 +23,149 @@ import "C"
 
 import (
 	"crypto"
+	"crypto/elliptic"
 	"errors"
+	"fmt"
 	"io"
 	"unsafe"
 
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/ecdsasig"
+	"github.com/common-fate/go-apple-security/internal/sigopts"
 )
 
-func (k *Key) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+// SignatureEncoding is the encoding of ECDSA signatures.
+type SignatureEncoding int
+
+const (
+	// SignatureDER is the ASN.1 DER encoding of X9.62,
+	// expected by crypto/ecdsa and crypto/x509.
+	SignatureDER SignatureEncoding = iota
+	// SignatureP1363 is the fixed-width r || s encoding of
+	// IEEE P1363, used by JWS, COSE and WebAuthn.
+	SignatureP1363
+)
+
+// Sign signs digest with the key, which must be the hash of a message
+// by the hash function of opts: SHA-256, SHA-384 or SHA-512. Nil opts
+// mean SHA-256. The signature is encoded as set by SignatureEncoding.
+//
+// Keys created with UserPresence prompt the user to authenticate. If they
+// do not, the error wraps a LAError from the localauth package,
+// such as localauth.ErrUserCancel or localauth.ErrBiometryLockout.
+func (k *Key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	sig, err := k.sign(digest, opts)
+	if err != nil {
+		return nil, opError("Sign", k.Tag, k.Label, k.AccessGroup, err)
+	}
+	return sig, nil
+}
+
+// SignMessage hashes message with hash, which must be SHA-256, SHA-384
+// or SHA-512, and signs it with the key. The message is hashed by the
+// Security framework. The signature is encoded as set by SignatureEncoding.
+func (k *Key) SignMessage(message []byte, hash crypto.Hash) ([]byte, error) {
+	sig, err := k.signMessage(message, hash)
+	if err != nil {
+		return nil, opError("SignMessage", k.Tag, k.Label, k.AccessGroup, err)
+	}
+	return sig, nil
+}
+
+func (k *Key) sign(digest []byte, opts crypto.SignerOpts) ([]byte, error) {
 	if len(digest) == 0 {
 		return nil, errors.New("digest was empty")
 	}
 
-	appLabel, err := corefoundation.NewCFData(k.ApplicationLabel)
+	hash, err := sigopts.Digest(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var algorithm C.SecKeyAlgorithm
+	switch hash {
+	case crypto.SHA256:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureDigestX962SHA256
+	case crypto.SHA384:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureDigestX962SHA384
+	case crypto.SHA512:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureDigestX962SHA512
+	}
+
+	return k.createSignature(algorithm, digest)
+}
+
+// encode converts a DER signature from the Security
+// framework to the SignatureEncoding of the key.
+func (k *Key) encode(der []byte) ([]byte, error) {
+	switch k.SignatureEncoding {
+	case SignatureDER:
+		return der, nil
+	case SignatureP1363:
+		return ecdsasig.ToP1363(der, elliptic.P256(), nil)
+	}
+	return nil, fmt.Errorf("unknown signature encoding %d", k.SignatureEncoding)
+}
+
+func (k *Key) signMessage(message []byte, hash crypto.Hash) ([]byte, error) {
+	if err := sigopts.Message(hash); err != nil {
+		return nil, err
+	}
+
+	var algorithm C.SecKeyAlgorithm
+	switch hash {
+	case crypto.SHA256:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureMessageX962SHA256
+	case crypto.SHA384:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureMessageX962SHA384
+	case crypto.SHA512:
+		algorithm = C.kSecKeyAlgorithmECDSASignatureMessageX962SHA512
+	}
+
+	return k.createSignature(algorithm, message)
+}
+
+// createSignature looks up the private key and signs data with
+// algorithm, returning the signature in the SignatureEncoding of k.
+func (k *Key) createSignature(algorithm C.SecKeyAlgorithm, data []byte) ([]byte, error) {
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	cfData, err := corefoundation.NewCFData(data)
 	if err != nil {
 		return nil, err
 	}
+	defer C.CFRelease(C.CFTypeRef(cfData))
+
+	var eref C.CFErrorRef
+	signature := C.SecKeyCreateSignature(key, algorithm, C.CFDataRef(cfData), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(signature))
+
+	return k.encode(C.GoBytes(
+		unsafe.Pointer(C.CFDataGetBytePtr(signature)),
+		C.int(C.CFDataGetLength(signature)),
+	))
+}
+
+// copyPrivateKey looks up the private key by its application label,
+// using the LAContext of k if it is set. The caller must release it.
+func (k *Key) copyPrivateKey() (C.SecKeyRef, error) {
+	applicationLabel, err := k.applicationLabel()
+	if err != nil {
+		return nilSecKey, err
+	}
+
+	appLabel, err := corefoundation.NewCFData(applicationLabel)
+	if err != nil {
+		return nilSecKey, err
+	}
 	defer C.CFRelease(C.CFTypeRef(appLabel))
 
 	m := corefoundation.PointerDictionary{
@@ -62,32 +189,14 @@ func (k *Key) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, err
 
 	query, err := corefoundation.NewPointerDictionary(m)
 	if err != nil {
-		return nil, err
+		return nilSecKey, err
 	}
 	defer C.CFRelease(C.CFTypeRef(query))
 
 	var key C.CFTypeRef
 	status := C.SecItemCopyMatching(C.CFDictionaryRef(query), &key)
 	if err := goError(status); err != nil {
-		return nil, err
-	}
-	defer C.CFRelease(C.CFTypeRef(key))
-
-	cfDigest, err := corefoundation.NewCFData(digest)
-	if err != nil {
-		return nil, err
-	}
-	defer C.CFRelease(C.CFTypeRef(cfDigest))
-
-	var eref C.CFErrorRef
-	signature := C.SecKeyCreateSignature(C.SecKeyRef(key), C.kSecKeyAlgorithmECDSASignatureDigestX962SHA256, C.CFDataRef(cfDigest), &eref)
-	if err := goError(eref); err != nil {
-		return nil, err
+		return nilSecKey, err
 	}
-	defer C.CFRelease(C.CFTypeRef(signature))
-
-	return C.GoBytes(
-		unsafe.Pointer(C.CFDataGetBytePtr(signature)),
-		C.int(C.CFDataGetLength(signature)),
-	), nil
+	return C.SecKeyRef(key), nil
 }
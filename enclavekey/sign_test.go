@@ -1,13 +1,18 @@
 package enclavekey
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/sha256"
+	_ "crypto/sha512"
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/ecdsasig"
+	"github.com/common-fate/go-apple-security/jose"
 )
 
 func TestKey_Sign(t *testing.T) {
@@ -119,3 +124,126 @@ func TestKey_Sign_Interactive(t *testing.T) {
 		t.Errorf("invalid signature")
 	}
 }
+
+func TestKey_Sign_Hashes(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.sign_hashes"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+
+	message := []byte("hello")
+	tests := []struct {
+		name    string
+		hash    crypto.Hash
+		opts    crypto.SignerOpts
+		wantErr bool
+	}{
+		{name: "sha256", hash: crypto.SHA256, opts: crypto.SHA256},
+		{name: "sha384", hash: crypto.SHA384, opts: crypto.SHA384},
+		{name: "sha512", hash: crypto.SHA512, opts: crypto.SHA512},
+		{name: "mismatch", hash: crypto.SHA256, opts: crypto.SHA512, wantErr: true},
+		{name: "sha1", hash: crypto.SHA1, opts: crypto.SHA1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.hash.New()
+			h.Write(message)
+			digest := h.Sum(nil)
+
+			sig, err := k.Sign(nil, digest, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Key.Sign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !ecdsa.VerifyASN1(k.PublicKey, digest, sig) {
+				t.Errorf("invalid signature from Sign")
+			}
+
+			sig, err = k.SignMessage(message, tt.hash)
+			if (err != nil) != (tt.hash == crypto.SHA1) {
+				t.Fatalf("Key.SignMessage() error = %v", err)
+			}
+			if err == nil && !ecdsa.VerifyASN1(k.PublicKey, digest, sig) {
+				t.Errorf("invalid signature from SignMessage")
+			}
+		})
+	}
+}
+
+func TestKey_Sign_P1363(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.sign_p1363"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+	k.SignatureEncoding = SignatureP1363
+
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := k.Sign(nil, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 64 {
+		t.Fatalf("got a %d byte signature, want 64", len(sig))
+	}
+
+	if err := k.Verify(digest[:], sig); err != nil {
+		t.Errorf("Key.Verify() error = %v", err)
+	}
+
+	der, err := ecdsasig.ToDER(sig, k.PublicKey.Curve, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(k.PublicKey, digest[:], der) {
+		t.Errorf("invalid signature")
+	}
+}
+
+func TestKey_JWS(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.jws"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+	k, err := Create(CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatalf("error creating key: %v", err)
+	}
+
+	for _, encoding := range []SignatureEncoding{SignatureDER, SignatureP1363} {
+		k.SignatureEncoding = encoding
+
+		s, err := jose.NewSigner(k, &jose.SignerOptions{P1363: encoding == SignatureP1363})
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := s.ClientAssertion("cli", "https://auth.example.com/oauth2/token", time.Minute)
+		if err != nil {
+			t.Fatalf("ClientAssertion() error = %v", err)
+		}
+		var claims jose.Claims
+		h, err := jose.VerifyJWT(token, k.PublicKey, &claims)
+		if err != nil {
+			t.Fatalf("VerifyJWT() error = %v", err)
+		}
+		thumbprint, err := k.Thumbprint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.KeyID != thumbprint {
+			t.Errorf("kid = %q, want %q", h.KeyID, thumbprint)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+// Package envelope encrypts a secret to several recipients, any of whom
+// can decrypt it, such as the Secure Enclave keys of a team's devices.
+//
+// The secret is encrypted with a random data key using AES-256-GCM, and
+// the data key is wrapped to each recipient with ECIES, as decrypted by
+// enclavekey.Key. An envelope is self-describing JSON, listing each
+// recipient by the RFC 7638 thumbprint and JWK of its public key, so
+// recipients can be added and removed by anyone able to open it. The
+// list of recipients is authenticated as additional data of the AEAD,
+// so changes by anyone unable to open the envelope are detected.
+//
+// The package is pure Go: envelopes can be created and opened with
+// software keys, using ecies.NewDecrypter, on any platform.
+package envelope
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// Version is the version of envelopes written by this package.
+const Version = 1
+
+// dataKeySize is the length of the AES-256 data key.
+const dataKeySize = 32
+
+// additionalDataPrefix binds the ciphertext to the envelope format.
+const additionalDataPrefix = "go-apple-security envelope v1"
+
+// ErrNotRecipient is returned when opening an envelope
+// with a key which is not one of its recipients.
+var ErrNotRecipient = errors.New("key is not a recipient of the envelope")
+
+// Envelope is a secret encrypted to one or more recipients.
+type Envelope struct {
+	Version    int         `json:"version"`
+	Recipients []Recipient `json:"recipients"`
+	Nonce      []byte      `json:"nonce"`
+	Ciphertext []byte      `json:"ciphertext"`
+}
+
+// Recipient is a public key to which the data key of an envelope is wrapped.
+type Recipient struct {
+	// KeyID is the RFC 7638 JWK thumbprint of the public key.
+	KeyID string `json:"kid"`
+	// PublicKey is the public key as a JWK, so that
+	// the data key can be wrapped to it again.
+	PublicKey json.RawMessage `json:"jwk"`
+	// WrappedKey is the data key, encrypted to the public key with ECIES.
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// Seal encrypts secret to the recipients, which must be P-256, P-384 or
+// P-521 public keys, such as those parsed by pubkey.Parse from the JWK
+// or OpenSSH form of an enclave key.
+func Seal(rand io.Reader, secret []byte, recipients ...crypto.PublicKey) (*Envelope, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("an envelope needs at least one recipient")
+	}
+	e := &Envelope{Version: Version}
+	if err := e.seal(rand, secret, recipients); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Parse parses an envelope marshalled by Marshal.
+func Parse(data []byte) (*Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("parsing envelope: %w", err)
+	}
+	if e.Version != Version {
+		return nil, fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+	if len(e.Recipients) == 0 {
+		return nil, errors.New("envelope has no recipients")
+	}
+	for i, r := range e.Recipients {
+		if _, err := r.publicKey(); err != nil {
+			return nil, err
+		}
+		if containsRecipient(e.Recipients[:i], r.KeyID) {
+			return nil, fmt.Errorf("recipient %s is listed twice", r.KeyID)
+		}
+	}
+	return &e, nil
+}
+
+// Marshal returns the JSON encoding of the envelope.
+func (e *Envelope) Marshal() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
+
+// Open decrypts the secret with key, which must be the private key of
+// one of the recipients, such as an enclavekey.Key or ecies.NewDecrypter.
+//
+// Open fails if the list of recipients was changed other than by
+// AddRecipients or RemoveRecipients.
+func (e *Envelope) Open(key crypto.Decrypter) ([]byte, error) {
+	dataKey, err := e.unwrap(key)
+	if err != nil {
+		return nil, err
+	}
+	return e.decrypt(dataKey)
+}
+
+// AddRecipients wraps the data key to more recipients. key
+// must be the private key of one of the existing recipients.
+//
+// The secret is encrypted again with the same data key and a new
+// nonce, authenticating the new list of recipients.
+func (e *Envelope) AddRecipients(rand io.Reader, key crypto.Decrypter, recipients ...crypto.PublicKey) error {
+	dataKey, err := e.unwrap(key)
+	if err != nil {
+		return err
+	}
+	secret, err := e.decrypt(dataKey)
+	if err != nil {
+		return err
+	}
+
+	wrapped := append([]Recipient(nil), e.Recipients...)
+	for _, pub := range recipients {
+		r, err := wrap(rand, dataKey, pub)
+		if err != nil {
+			return err
+		}
+		if !containsRecipient(wrapped, r.KeyID) {
+			wrapped = append(wrapped, r)
+		}
+	}
+
+	return e.encrypt(rand, dataKey, secret, wrapped)
+}
+
+// RemoveRecipients removes the recipients with the key IDs. The secret
+// is encrypted again with a new data key, wrapped to the remaining
+// recipients, so that removed recipients cannot open later versions of
+// the envelope with a data key they kept. They may have kept the secret
+// itself, so it should be rotated too.
+//
+// key must be the private key of one of the recipients.
+func (e *Envelope) RemoveRecipients(rand io.Reader, key crypto.Decrypter, keyIDs ...string) error {
+	secret, err := e.Open(key)
+	if err != nil {
+		return err
+	}
+
+	var remaining []crypto.PublicKey
+	for _, r := range e.Recipients {
+		if contains(keyIDs, r.KeyID) {
+			continue
+		}
+		pub, err := r.publicKey()
+		if err != nil {
+			return err
+		}
+		remaining = append(remaining, pub)
+	}
+	if len(remaining) == 0 {
+		return errors.New("cannot remove every recipient of an envelope")
+	}
+	if len(remaining) == len(e.Recipients) {
+		return errors.New("no recipients with the key IDs were found")
+	}
+
+	return e.seal(rand, secret, remaining)
+}
+
+// KeyID returns the ID of pub in the recipients of an envelope.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	ecPub, err := ecdsaPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return pubkey.Thumbprint(ecPub)
+}
+
+// seal encrypts secret with a new data key wrapped to recipients,
+// replacing the contents of e.
+func (e *Envelope) seal(rand io.Reader, secret []byte, recipients []crypto.PublicKey) error {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand, dataKey); err != nil {
+		return err
+	}
+
+	var wrapped []Recipient
+	for _, pub := range recipients {
+		r, err := wrap(rand, dataKey, pub)
+		if err != nil {
+			return err
+		}
+		if !containsRecipient(wrapped, r.KeyID) {
+			wrapped = append(wrapped, r)
+		}
+	}
+
+	return e.encrypt(rand, dataKey, secret, wrapped)
+}
+
+// encrypt encrypts secret with dataKey and a new nonce, authenticating
+// the recipients, and replaces the contents of e.
+func (e *Envelope) encrypt(rand io.Reader, dataKey, secret []byte, recipients []Recipient) error {
+	ad, err := additionalData(recipients)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return err
+	}
+
+	e.Recipients = recipients
+	e.Nonce = nonce
+	e.Ciphertext = aead.Seal(nil, nonce, secret, ad)
+	return nil
+}
+
+// decrypt decrypts the secret with dataKey, checking the recipients.
+func (e *Envelope) decrypt(dataKey []byte) ([]byte, error) {
+	ad, err := additionalData(e.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, errors.New("envelope has an invalid nonce")
+	}
+	return aead.Open(nil, e.Nonce, e.Ciphertext, ad)
+}
+
+// unwrap returns the data key, decrypted by key.
+func (e *Envelope) unwrap(key crypto.Decrypter) ([]byte, error) {
+	kid, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	i := e.index(kid)
+	if i < 0 {
+		return nil, ErrNotRecipient
+	}
+	dataKey, err := key.Decrypt(nil, e.Recipients[i].WrappedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping data key: %w", err)
+	}
+	if len(dataKey) != dataKeySize {
+		return nil, errors.New("unwrapped data key has the wrong length")
+	}
+	return dataKey, nil
+}
+
+// publicKey returns the public key of the recipient, checking that
+// KeyID is its thumbprint, so that the data key is never wrapped to a
+// key other than the one the recipient is listed as.
+func (r *Recipient) publicKey() (*ecdsa.PublicKey, error) {
+	pub, err := pubkey.ParseJWK(r.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key of recipient %s: %w", r.KeyID, err)
+	}
+	ecPub, err := ecdsaPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("recipient %s: %w", r.KeyID, err)
+	}
+	kid, err := pubkey.Thumbprint(ecPub)
+	if err != nil {
+		return nil, err
+	}
+	if kid != r.KeyID {
+		return nil, fmt.Errorf("recipient %s: key ID is not the thumbprint of its public key", r.KeyID)
+	}
+	return ecPub, nil
+}
+
+// additionalData returns the additional data of the AEAD, binding the
+// ciphertext to the envelope format and to the key ID and canonical
+// JWK of each recipient, in order.
+func additionalData(recipients []Recipient) ([]byte, error) {
+	ad := []byte(additionalDataPrefix)
+	for _, r := range recipients {
+		pub, err := r.publicKey()
+		if err != nil {
+			return nil, err
+		}
+		jwk, err := pubkey.MarshalJWK(pub)
+		if err != nil {
+			return nil, err
+		}
+		ad = appendField(ad, []byte(r.KeyID))
+		ad = appendField(ad, jwk)
+	}
+	return ad, nil
+}
+
+// appendField appends field to b, prefixed with its length.
+func appendField(b, field []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(field)))
+	return append(b, field...)
+}
+
+func (e *Envelope) index(kid string) int {
+	for i, r := range e.Recipients {
+		if r.KeyID == kid {
+			return i
+		}
+	}
+	return -1
+}
+
+// wrap encrypts the data key to pub.
+func wrap(rand io.Reader, dataKey []byte, pub crypto.PublicKey) (Recipient, error) {
+	ecPub, err := ecdsaPublicKey(pub)
+	if err != nil {
+		return Recipient{}, err
+	}
+	jwk, err := pubkey.NewJWK(ecPub)
+	if err != nil {
+		return Recipient{}, err
+	}
+	jwkJSON, err := json.Marshal(jwk)
+	if err != nil {
+		return Recipient{}, err
+	}
+	wrapped, err := ecies.Encrypt(rand, ecPub, dataKey)
+	if err != nil {
+		return Recipient{}, err
+	}
+	return Recipient{KeyID: jwk.Kid, PublicKey: jwkJSON, WrappedKey: wrapped}, nil
+}
+
+// ecdsaPublicKey converts an elliptic curve public key to ECDSA, the
+// type used by the pubkey package, so that software keys from
+// crypto/ecdh have the same key ID as the enclave key they stand in for.
+func ecdsaPublicKey(pub crypto.PublicKey) (*ecdsa.PublicKey, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		return k, nil
+	case *ecdh.PublicKey:
+		return pubkey.ParseX963(k.Bytes())
+	}
+	return nil, fmt.Errorf("unsupported recipient public key type %T: envelopes need elliptic curve keys", pub)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRecipient(recipients []Recipient, kid string) bool {
+	for _, r := range recipients {
+		if r.KeyID == kid {
+			return true
+		}
+	}
+	return false
+}
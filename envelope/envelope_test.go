@@ -0,0 +1,380 @@
+package envelope
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+func newKey(t *testing.T, curve ecdh.Curve) crypto.Decrypter {
+	t.Helper()
+	priv, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ecies.NewDecrypter(priv)
+}
+
+// exported returns the public key of key as a teammate would receive
+// it, exported from enclavekey as a JWK or OpenSSH authorized key.
+func exported(t *testing.T, key crypto.Decrypter, ssh bool) crypto.PublicKey {
+	t.Helper()
+	pub, err := ecdsaPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data []byte
+	if ssh {
+		data, err = pubkey.MarshalAuthorizedKey(pub, "teammate")
+	} else {
+		data, err = pubkey.MarshalJWK(pub)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := pubkey.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestSeal(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	bob := newKey(t, ecdh.P384())
+	carol := newKey(t, ecdh.P521())
+	mallory := newKey(t, ecdh.P256())
+	secret := []byte("correct horse battery staple")
+
+	e, err := Seal(rand.Reader, secret, exported(t, alice, false), exported(t, bob, true), carol.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := e.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Recipients) != 3 {
+		t.Fatalf("got %d recipients, want 3", len(parsed.Recipients))
+	}
+
+	for name, key := range map[string]crypto.Decrypter{"alice": alice, "bob": bob, "carol": carol} {
+		got, err := parsed.Open(key)
+		if err != nil {
+			t.Errorf("%s: Open() error = %v", name, err)
+			continue
+		}
+		if string(got) != string(secret) {
+			t.Errorf("%s: Open() = %q, want %q", name, got, secret)
+		}
+	}
+
+	if _, err := parsed.Open(mallory); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("Open() by a non-recipient error = %v, want ErrNotRecipient", err)
+	}
+}
+
+func TestSeal_Errors(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		recipients []crypto.PublicKey
+	}{
+		{name: "no recipients"},
+		{name: "RSA", recipients: []crypto.PublicKey{&rsaKey.PublicKey}},
+		{name: "X25519", recipients: []crypto.PublicKey{x25519.PublicKey()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Seal(rand.Reader, []byte("secret"), tt.recipients...); err == nil {
+				t.Error("Seal() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestSeal_DuplicateRecipients(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	e, err := Seal(rand.Reader, []byte("secret"), alice.Public(), exported(t, alice, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Recipients) != 1 {
+		t.Errorf("got %d recipients, want 1", len(e.Recipients))
+	}
+}
+
+func TestAddRecipients(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	bob := newKey(t, ecdh.P256())
+	secret := []byte("secret")
+
+	e, err := Seal(rand.Reader, secret, alice.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceWrapped := e.Recipients[0].WrappedKey
+
+	if err := e.AddRecipients(rand.Reader, bob, exported(t, bob, false)); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("AddRecipients() by a non-recipient error = %v, want ErrNotRecipient", err)
+	}
+	if err := e.AddRecipients(rand.Reader, alice, exported(t, bob, false), alice.Public()); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Recipients) != 2 {
+		t.Errorf("got %d recipients, want 2", len(e.Recipients))
+	}
+	if string(e.Recipients[0].WrappedKey) != string(aliceWrapped) {
+		t.Error("AddRecipients() changed the data key of an existing recipient")
+	}
+
+	got, err := e.Open(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(secret) {
+		t.Errorf("Open() = %q, want %q", got, secret)
+	}
+}
+
+func TestRemoveRecipients(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	bob := newKey(t, ecdh.P256())
+	secret := []byte("secret")
+
+	e, err := Seal(rand.Reader, secret, alice.Public(), bob.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := *e
+	old.Recipients = append([]Recipient(nil), e.Recipients...)
+
+	bobID, err := KeyID(bob.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliceID, err := KeyID(alice.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.RemoveRecipients(rand.Reader, alice, "unknown"); err == nil {
+		t.Error("RemoveRecipients() of an unknown key ID succeeded")
+	}
+	if err := e.RemoveRecipients(rand.Reader, alice, aliceID, bobID); err == nil {
+		t.Error("RemoveRecipients() of every recipient succeeded")
+	}
+	if err := e.RemoveRecipients(rand.Reader, alice, bobID); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Recipients) != 1 || e.Recipients[0].KeyID != aliceID {
+		t.Fatalf("got recipients %+v, want only alice", e.Recipients)
+	}
+	if _, err := e.Open(bob); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("Open() by a removed recipient error = %v, want ErrNotRecipient", err)
+	}
+	got, err := e.Open(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(secret) {
+		t.Errorf("Open() = %q, want %q", got, secret)
+	}
+
+	// The data key was rotated, so the key bob unwrapped
+	// from the old envelope cannot open the new one.
+	oldKey, err := old.unwrap(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := newAEAD(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad, err := additionalData(e.Recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := aead.Open(nil, e.Nonce, e.Ciphertext, ad); err == nil {
+		t.Error("the old data key opened the envelope after RemoveRecipients()")
+	}
+}
+
+func TestOpen_TamperedRecipients(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	bob := newKey(t, ecdh.P256())
+	carol := newKey(t, ecdh.P384())
+	mallory := newKey(t, ecdh.P256())
+
+	// mallory wraps a data key of their own, as they cannot unwrap the real one.
+	forged, err := Seal(rand.Reader, []byte("forged"), mallory.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	malloryRecipient := forged.Recipients[0]
+
+	tests := []struct {
+		name   string
+		tamper func(recipients []Recipient) []Recipient
+	}{
+		{
+			name: "reordered",
+			tamper: func(r []Recipient) []Recipient {
+				return []Recipient{r[1], r[0], r[2]}
+			},
+		},
+		{
+			name: "removed",
+			tamper: func(r []Recipient) []Recipient {
+				return r[:2]
+			},
+		},
+		{
+			name: "added",
+			tamper: func(r []Recipient) []Recipient {
+				return append(r, malloryRecipient)
+			},
+		},
+		{
+			name: "replaced",
+			tamper: func(r []Recipient) []Recipient {
+				return []Recipient{r[0], malloryRecipient, r[2]}
+			},
+		},
+		{
+			// bob's wrapped key is listed under mallory's ID and JWK.
+			name: "edited",
+			tamper: func(r []Recipient) []Recipient {
+				r[1].KeyID = malloryRecipient.KeyID
+				r[1].PublicKey = malloryRecipient.PublicKey
+				return r
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Seal(rand.Reader, []byte("secret"), alice.Public(), bob.Public(), carol.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Recipients = tt.tamper(append([]Recipient(nil), e.Recipients...))
+
+			data, err := e.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, err := parsed.Open(alice); err == nil {
+				t.Error("Open() of an envelope with tampered recipients succeeded")
+			}
+			if err := parsed.AddRecipients(rand.Reader, alice, mallory.Public()); err == nil {
+				t.Error("AddRecipients() to an envelope with tampered recipients succeeded")
+			}
+		})
+	}
+}
+
+func TestParse_SubstitutedPublicKey(t *testing.T) {
+	alice := newKey(t, ecdh.P256())
+	bob := newKey(t, ecdh.P256())
+	mallory := newKey(t, ecdh.P256())
+
+	malloryJWK, err := pubkey.MarshalJWK(exported(t, mallory, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Seal(rand.Reader, []byte("secret"), alice.Public(), bob.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bob keeps their key ID but is listed with mallory's public key,
+	// so that the data key would be wrapped to mallory by RemoveRecipients.
+	e.Recipients[1].PublicKey = malloryJWK
+	aliceID := e.Recipients[0].KeyID
+
+	data, err := e.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(data); err == nil {
+		t.Error("Parse() succeeded, want an error")
+	}
+
+	if _, err := e.Open(alice); err == nil {
+		t.Error("Open() succeeded, want an error")
+	}
+	if err := e.AddRecipients(rand.Reader, alice, mallory.Public()); err == nil {
+		t.Error("AddRecipients() succeeded, want an error")
+	}
+	if err := e.RemoveRecipients(rand.Reader, alice, aliceID); err == nil {
+		t.Error("RemoveRecipients() succeeded, want an error")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not JSON", data: "SEAL"},
+		{name: "unsupported version", data: `{"version":2,"recipients":[{"kid":"a"}]}`},
+		{name: "no recipients", data: `{"version":1,"recipients":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("Parse() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaPub, err := ecdsaPublicKey(priv.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ecdsaPub.Curve != elliptic.P256() {
+		t.Fatalf("got curve %s", ecdsaPub.Curve.Params().Name)
+	}
+
+	fromECDH, err := KeyID(priv.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := pubkey.Thumbprint(ecdsaPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromECDH != want {
+		t.Errorf("KeyID() = %s, want the JWK thumbprint %s", fromECDH, want)
+	}
+}
@@ -8,7 +8,13 @@ package applesecurity
 */
 import "C"
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/osstatus"
+)
 
 // Error defines keychain errors
 type Error int
@@ -71,58 +77,56 @@ func ErrorFromCode(errCode int) error {
 	return Error(errCode)
 }
 
-func (k Error) Error() (msg string) {
-	// SecCopyErrorMessageString is only available on OSX, so derive manually.
-	// Messages derived from `$ security error $errcode`.
-	switch k {
-	case ErrUnimplemented:
-		msg = "function or operation not implemented"
-	case ErrParam:
-		msg = "one or more parameters passed to the function were not valid"
-	case ErrAllocate:
-		msg = "failed to allocate memory"
-	case ErrNotAvailable:
-		msg = "no keychain is available. You may need to restart your computer"
-	case ErrAuthFailed:
-		msg = "the user name or passphrase you entered is not correct"
-	case ErrDuplicateItem:
-		msg = "the specified item already exists in the keychain"
-	case ErrItemNotFound:
-		msg = "the specified item could not be found in the keychain"
-	case ErrInteractionNotAllowed:
-		msg = "user interaction is not allowed"
-	case ErrDecode:
-		msg = "unable to decode the provided data"
-	case ErrNoSuchKeychain:
-		msg = "the specified keychain could not be found"
-	case ErrNoAccessForItem:
-		msg = "the specified item has no access control"
-	case ErrReadOnly:
-		msg = "read-only error"
-	case ErrReadonlyAttribute:
-		msg = "the attribute is read-only"
-	case ErrInvalidKeychain:
-		msg = "the keychain is not valid"
-	case ErrDuplicateKeyChain:
-		msg = "a keychain with the same name already exists"
-	case ErrWrongVersion:
-		msg = "the version is incorrect"
-	case ErrInvalidItemRef:
-		msg = "the item reference is invalid"
-	case ErrInvalidSearchRef:
-		msg = "the search reference is invalid"
-	case ErrDataNotAvailable:
-		msg = "the data is not available"
-	case ErrDataNotModifiable:
-		msg = "the data is not modifiable"
-	case ErrInvalidOwnerEdit:
-		msg = "an invalid attempt to change the owner of an item"
-	case ErrUserCanceled:
-		msg = "user canceled the operation"
-	case ErrMissingEntitlement:
-		msg = "a required entitlement is missing: ensure that your binary has been properly codesigned and has entitlements allowing keychain access"
-	default:
+// Name returns the symbolic name of the result code,
+// such as "errSecItemNotFound", or "" if it is unknown.
+func (k Error) Name() string {
+	info, _ := osstatus.Lookup(int32(k))
+	return info.Name
+}
+
+// Category returns the part of the Security framework the result code belongs to.
+func (k Error) Category() osstatus.Category {
+	info, _ := osstatus.Lookup(int32(k))
+	return info.Category
+}
+
+func (k Error) Error() string {
+	info, _ := osstatus.Lookup(int32(k))
+
+	// prefer the localised message of the running system,
+	// falling back to the message from the SDK headers.
+	msg := systemMessage(k)
+	if msg == "" {
+		msg = info.Message
+	}
+	if msg == "" {
 		msg = "keychain error"
 	}
-	return fmt.Sprintf("%s (%d)", msg, k)
+	msg = strings.TrimSuffix(msg, ".")
+
+	if k == ErrMissingEntitlement {
+		msg += ": ensure that your binary has been properly codesigned and has entitlements allowing keychain access, or use the legacy keychain"
+	}
+
+	if info.Name == "" {
+		return fmt.Sprintf("%s (%d)", msg, k)
+	}
+	return fmt.Sprintf("%s (%s %d)", msg, info.Name, k)
+}
+
+// systemMessage returns the message for k from SecCopyErrorMessageString,
+// or "" if the system has none.
+func systemMessage(k Error) string {
+	s := C.SecCopyErrorMessageString(C.OSStatus(k), nil)
+	if s == 0 {
+		return ""
+	}
+	defer C.CFRelease(C.CFTypeRef(s))
+
+	msg := corefoundation.CFStringToString(corefoundation.StringRef(s))
+	// unknown codes are described as "OSStatus <code>".
+	if strings.HasPrefix(msg, "OSStatus ") {
+		return ""
+	}
+	return msg
 }
@@ -0,0 +1,239 @@
+// Package filecrypt encrypts files to one or more recipients, such as
+// the Secure Enclave keys of a device, so that they cannot be read off
+// the machine.
+//
+// A file begins with a header holding a random file key, sealed to the
+// recipients as an envelope.Envelope. The header is authenticated with
+// a MAC derived from the file key, so it cannot be changed without the
+// change being detected. The plaintext follows as a stream of chunks
+// encrypted with AES-256-GCM, each with a nonce made from its index and
+// a flag marking the last chunk, so chunks cannot be reordered, dropped
+// or truncated without the reader returning an error.
+//
+// Files of any size are encrypted and decrypted in constant memory.
+// The package is pure Go: decrypting with an enclavekey.Key requires
+// the Secure Enclave, and user presence if the key was created with it,
+// but files can be encrypted to exported public keys on any platform.
+package filecrypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/envelope"
+	"golang.org/x/crypto/hkdf"
+)
+
+// magic begins every encrypted file.
+const magic = "go-apple-security/filecrypt/v1\n"
+
+const (
+	fileKeySize = 32
+	saltSize    = 16
+	macSize     = sha256.Size
+
+	// maxHeaderSize bounds the header, so that a corrupt
+	// length cannot make the reader allocate without limit.
+	maxHeaderSize = 1 << 20
+)
+
+// ContentTypeTar is the content type of a tar archive,
+// used by the enclave-crypt command for directories.
+const ContentTypeTar = "application/x-tar"
+
+var (
+	// ErrNotEncrypted is returned when reading
+	// data which does not begin with a header.
+	ErrNotEncrypted = errors.New("data is not an encrypted file")
+
+	// ErrTruncated is returned when the
+	// last chunk of a file is missing.
+	ErrTruncated = errors.New("encrypted file is truncated")
+)
+
+// Header is the header of an encrypted file.
+type Header struct {
+	// ContentType optionally describes the plaintext, such as ContentTypeTar.
+	ContentType string `json:"content_type,omitempty"`
+	// Salt is used to derive the key encrypting
+	// the chunks from the file key.
+	Salt []byte `json:"salt"`
+	// FileKey is the file key, sealed to the recipients.
+	FileKey *envelope.Envelope `json:"file_key"`
+}
+
+// Recipients returns the recipients which can decrypt the file.
+func (h *Header) Recipients() []envelope.Recipient {
+	return h.FileKey.Recipients
+}
+
+// Options configures the encryption of a file.
+type Options struct {
+	// ContentType is stored in the header of the file.
+	ContentType string
+}
+
+// NewWriter writes the header of an encrypted file to dst, returning a
+// writer encrypting the plaintext to dst. It must be closed to write
+// the last chunk. recipients must be P-256, P-384 or P-521 public keys,
+// such as the PublicKey of an enclavekey.Key. opts may be nil.
+func NewWriter(rand io.Reader, dst io.Writer, recipients []crypto.PublicKey, opts *Options) (*Writer, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	if _, err := io.ReadFull(rand, fileKey); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, err
+	}
+	sealed, err := envelope.Seal(rand, fileKey, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := marshalHeader(&Header{ContentType: opts.ContentType, Salt: salt, FileKey: sealed})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(headerMAC(fileKey, header)); err != nil {
+		return nil, err
+	}
+
+	aead, err := payloadAEAD(fileKey, salt)
+	if err != nil {
+		return nil, err
+	}
+	return newWriter(dst, aead), nil
+}
+
+// NewReader reads and authenticates the header of an encrypted file
+// from src, returning a reader decrypting the plaintext. key must be
+// the private key of one of the recipients, such as an enclavekey.Key.
+//
+// The reader returns an error if any chunk fails authentication, and
+// ErrTruncated if src ends before the last chunk. Data returned before
+// an error is authentic, but may be incomplete, so callers writing the
+// plaintext somewhere should discard it if reading fails.
+func NewReader(src io.Reader, key crypto.Decrypter) (*Reader, error) {
+	h, raw, err := readHeader(src)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := h.FileKey.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileKey) != fileKeySize {
+		return nil, errors.New("file key has the wrong length")
+	}
+
+	mac := make([]byte, macSize)
+	if _, err := io.ReadFull(src, mac); err != nil {
+		return nil, ErrTruncated
+	}
+	if !hmac.Equal(mac, headerMAC(fileKey, raw)) {
+		return nil, errors.New("header failed authentication")
+	}
+
+	aead, err := payloadAEAD(fileKey, h.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return newReader(src, aead, h), nil
+}
+
+// ReadHeader reads the header of an encrypted file from src, to list
+// its recipients without decrypting it. The header is not authenticated.
+func ReadHeader(src io.Reader) (*Header, error) {
+	h, _, err := readHeader(src)
+	return h, err
+}
+
+// marshalHeader returns the encoded header:
+// the magic string, the length of the JSON header as a big-endian
+// uint32, and the JSON header.
+func marshalHeader(h *Header) ([]byte, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString(magic)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes(), nil
+}
+
+// readHeader reads the header from src,
+// returning it and its encoding.
+func readHeader(src io.Reader) (*Header, []byte, error) {
+	prefix := make([]byte, len(magic)+4)
+	if _, err := io.ReadFull(src, prefix); err != nil || string(prefix[:len(magic)]) != magic {
+		return nil, nil, ErrNotEncrypted
+	}
+	n := binary.BigEndian.Uint32(prefix[len(magic):])
+	if n > maxHeaderSize {
+		return nil, nil, fmt.Errorf("header of %d bytes is too large", n)
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(src, data); err != nil {
+		return nil, nil, ErrTruncated
+	}
+	var h Header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, nil, fmt.Errorf("parsing header: %w", err)
+	}
+	if h.FileKey == nil || len(h.FileKey.Recipients) == 0 {
+		return nil, nil, errors.New("header has no recipients")
+	}
+	if h.FileKey.Version != envelope.Version {
+		return nil, nil, fmt.Errorf("unsupported envelope version %d", h.FileKey.Version)
+	}
+	return &h, append(prefix, data...), nil
+}
+
+// headerMAC returns the HMAC-SHA256 of the encoded header.
+func headerMAC(fileKey, header []byte) []byte {
+	mac := hmac.New(sha256.New, deriveKey(fileKey, nil, "header"))
+	mac.Write(header)
+	return mac.Sum(nil)
+}
+
+// payloadAEAD returns the AES-256-GCM cipher encrypting the chunks.
+func payloadAEAD(fileKey, salt []byte) (cipher.AEAD, error) {
+	if len(salt) != saltSize {
+		return nil, errors.New("header has an invalid salt")
+	}
+	block, err := aes.NewCipher(deriveKey(fileKey, salt, "payload"))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives a 32-byte key for one purpose from the file key.
+func deriveKey(fileKey, salt []byte, info string) []byte {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, fileKey, salt, []byte(info)), key); err != nil {
+		panic(err)
+	}
+	return key
+}
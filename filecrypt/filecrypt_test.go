@@ -0,0 +1,245 @@
+package filecrypt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/envelope"
+)
+
+func newKey(t *testing.T) crypto.Decrypter {
+	t.Helper()
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ecies.NewDecrypter(priv)
+}
+
+func encrypt(t *testing.T, plaintext []byte, opts *Options, keys ...crypto.Decrypter) []byte {
+	t.Helper()
+	var recipients []crypto.PublicKey
+	for _, k := range keys {
+		recipients = append(recipients, k.Public())
+	}
+	var b bytes.Buffer
+	w, err := NewWriter(rand.Reader, &b, recipients, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func decrypt(data []byte, key crypto.Decrypter) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// headerSize returns the length of the header and its MAC.
+func headerSize(t *testing.T, data []byte) int {
+	t.Helper()
+	_, raw, err := readHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(raw) + macSize
+}
+
+func TestRoundTrip(t *testing.T) {
+	alice, bob := newKey(t), newKey(t)
+
+	sizes := []int{0, 1, 1000, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3 * ChunkSize, 3*ChunkSize + 17}
+	for _, size := range sizes {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			plaintext := make([]byte, size)
+			if _, err := rand.Read(plaintext); err != nil {
+				t.Fatal(err)
+			}
+			data := encrypt(t, plaintext, nil, alice, bob)
+
+			chunks := (size + ChunkSize - 1) / ChunkSize
+			if chunks == 0 {
+				chunks = 1
+			}
+			if got, want := len(data)-headerSize(t, data), size+chunks*16; got != want {
+				t.Errorf("got %d bytes of chunks, want %d", got, want)
+			}
+
+			for name, key := range map[string]crypto.Decrypter{"alice": alice, "bob": bob} {
+				got, err := decrypt(data, key)
+				if err != nil {
+					t.Fatalf("%s: error = %v", name, err)
+				}
+				if !bytes.Equal(got, plaintext) {
+					t.Errorf("%s: decrypted %d bytes, not the plaintext", name, len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestWriter_SmallWrites(t *testing.T) {
+	key := newKey(t)
+	plaintext := bytes.Repeat([]byte("0123456789"), ChunkSize/5)
+
+	var b bytes.Buffer
+	w, err := NewWriter(rand.Reader, &b, []crypto.PublicKey{key.Public()}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(plaintext); i += 7 {
+		if _, err := w.Write(plaintext[i:min(i+7, len(plaintext))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("more")); err == nil {
+		t.Error("Write() after Close() succeeded")
+	}
+
+	got, err := decrypt(b.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("decrypted data does not match the plaintext")
+	}
+}
+
+func TestReader_Tampering(t *testing.T) {
+	key := newKey(t)
+	plaintext := bytes.Repeat([]byte("secret"), ChunkSize/2)
+	data := encrypt(t, plaintext, &Options{ContentType: "text/plain"}, key)
+	start := headerSize(t, data)
+	chunk := ChunkSize + 16
+
+	tests := []struct {
+		name    string
+		modify  func(data []byte) []byte
+		wantErr error
+	}{
+		{
+			name:    "truncated at a chunk boundary",
+			modify:  func(data []byte) []byte { return data[:start+2*chunk] },
+			wantErr: ErrTruncated,
+		},
+		{
+			name:   "truncated within a chunk",
+			modify: func(data []byte) []byte { return data[:len(data)-1] },
+		},
+		{
+			name:    "no chunks",
+			modify:  func(data []byte) []byte { return data[:start] },
+			wantErr: ErrTruncated,
+		},
+		{
+			name:   "trailing data",
+			modify: func(data []byte) []byte { return append(data, 0) },
+		},
+		{
+			name: "chunks swapped",
+			modify: func(data []byte) []byte {
+				out := append([]byte(nil), data[:start]...)
+				out = append(out, data[start+chunk:start+2*chunk]...)
+				out = append(out, data[start:start+chunk]...)
+				return append(out, data[start+2*chunk:]...)
+			},
+		},
+		{
+			name: "chunk modified",
+			modify: func(data []byte) []byte {
+				data[start+chunk+100] ^= 1
+				return data
+			},
+		},
+		{
+			name: "header modified",
+			modify: func(data []byte) []byte {
+				return bytes.Replace(data, []byte("text/plain"), []byte("text/html!"), 1)
+			},
+		},
+		{
+			name: "MAC modified",
+			modify: func(data []byte) []byte {
+				data[start-1] ^= 1
+				return data
+			},
+		},
+		{
+			name:    "not encrypted",
+			modify:  func(data []byte) []byte { return []byte("hello") },
+			wantErr: ErrNotEncrypted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := tt.modify(append([]byte(nil), data...))
+			_, err := decrypt(modified, key)
+			if err == nil {
+				t.Fatal("decrypting succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReader_NotRecipient(t *testing.T) {
+	data := encrypt(t, []byte("secret"), nil, newKey(t))
+	if _, err := decrypt(data, newKey(t)); !errors.Is(err, envelope.ErrNotRecipient) {
+		t.Errorf("error = %v, want envelope.ErrNotRecipient", err)
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	alice, bob := newKey(t), newKey(t)
+	data := encrypt(t, []byte("secret"), &Options{ContentType: ContentTypeTar}, alice, bob)
+
+	h, err := ReadHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ContentType != ContentTypeTar {
+		t.Errorf("ContentType = %q, want %q", h.ContentType, ContentTypeTar)
+	}
+	var got []string
+	for _, r := range h.Recipients() {
+		got = append(got, r.KeyID)
+	}
+	for i, key := range []crypto.Decrypter{alice, bob} {
+		want, err := envelope.KeyID(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[i] != want {
+			t.Errorf("recipients = %v, want %s at %d", got, want, i)
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader(data), bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Header.ContentType != ContentTypeTar {
+		t.Errorf("Reader.Header.ContentType = %q, want %q", r.Header.ContentType, ContentTypeTar)
+	}
+}
@@ -0,0 +1,173 @@
+package filecrypt
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ChunkSize is the size of the plaintext of every chunk but the last,
+// which is shorter or, only if the plaintext is empty, empty.
+const ChunkSize = 64 * 1024
+
+var errClosed = errors.New("write to closed filecrypt.Writer")
+
+// chunkNonce returns the nonce of the chunk with the index: the index as
+// a big-endian integer, followed by 1 for the last chunk and 0 otherwise.
+func chunkNonce(index uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Writer encrypts a stream of chunks. It is returned by NewWriter.
+type Writer struct {
+	dst   io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	err   error
+}
+
+func newWriter(dst io.Writer, aead cipher.AEAD) *Writer {
+	return &Writer{dst: dst, aead: aead, buf: make([]byte, 0, ChunkSize)}
+}
+
+// Write encrypts p. Chunks are written to the destination
+// once they are full, and the last chunk by Close.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	var n int
+	for len(p) > 0 {
+		// a full chunk is only written once more data follows,
+		// as the last chunk must be written with the last flag.
+		if len(w.buf) == ChunkSize {
+			if err := w.flush(false); err != nil {
+				w.err = err
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close writes the last chunk. It does not close the destination.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		if w.err == errClosed {
+			return nil
+		}
+		return w.err
+	}
+	if err := w.flush(true); err != nil {
+		w.err = err
+		return err
+	}
+	w.err = errClosed
+	return nil
+}
+
+func (w *Writer) flush(last bool) error {
+	if w.index == math.MaxUint64 {
+		return errors.New("too many chunks")
+	}
+	out := w.aead.Seal(nil, chunkNonce(w.index, last), w.buf, nil)
+	if _, err := w.dst.Write(out); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	w.index++
+	return nil
+}
+
+// Reader decrypts a stream of chunks. It is returned by NewReader.
+type Reader struct {
+	// Header is the authenticated header of the file.
+	Header *Header
+
+	src   io.Reader
+	aead  cipher.AEAD
+	buf   []byte
+	out   []byte
+	plain []byte
+	index uint64
+	done  bool
+	err   error
+}
+
+func newReader(src io.Reader, aead cipher.AEAD, h *Header) *Reader {
+	return &Reader{Header: h, src: src, aead: aead, buf: make([]byte, ChunkSize+aead.Overhead()), out: make([]byte, 0, ChunkSize)}
+}
+
+// Read decrypts the next chunks into p. It returns io.EOF only after
+// the last chunk has been read and authenticated.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk.
+func (r *Reader) next() error {
+	n, err := io.ReadFull(r.src, r.buf)
+	switch {
+	case err == io.EOF:
+		return ErrTruncated
+	case err == io.ErrUnexpectedEOF:
+		// a short chunk can only be the last.
+		return r.open(r.buf[:n], true)
+	case err != nil:
+		return err
+	}
+
+	// a full chunk is the last if it was sealed as the last.
+	if err := r.open(r.buf, false); err == nil {
+		return nil
+	}
+	if err := r.open(r.buf, true); err != nil {
+		return err
+	}
+	if n, _ := io.ReadFull(r.src, make([]byte, 1)); n != 0 {
+		r.plain = nil
+		return errors.New("data follows the last chunk")
+	}
+	return nil
+}
+
+func (r *Reader) open(chunk []byte, last bool) error {
+	// decrypt to out rather than in place, as a failed
+	// Open clears its output, and a full chunk may be
+	// opened again with the last flag.
+	plain, err := r.aead.Open(r.out[:0], chunkNonce(r.index, last), chunk, nil)
+	if err != nil {
+		return fmt.Errorf("chunk %d failed authentication", r.index)
+	}
+	if last && len(plain) == 0 && r.index > 0 {
+		return errors.New("last chunk is empty")
+	}
+	r.plain = plain
+	r.index++
+	r.done = last
+	return nil
+}
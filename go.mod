@@ -1,3 +1,7 @@
 module github.com/common-fate/go-apple-security
 
 go 1.22.1
+
+require golang.org/x/crypto v0.33.0
+
+require golang.org/x/sys v0.30.0 // indirect
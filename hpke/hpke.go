@@ -0,0 +1,248 @@
+// Package hpke implements Hybrid Public Key Encryption (RFC 9180) with
+// DHKEM(P-256, HKDF-SHA256), HKDF-SHA256 and either AES-128-GCM or
+// ChaCha20-Poly1305, in the base and auth modes.
+//
+// Recipients and authenticating senders take a PrivateKey, so their key
+// agreement can run in the Secure Enclave with an enclavekey.Key, while
+// the other party uses software keys on any platform. Unlike ECIES, the
+// messages interoperate with any HPKE implementation.
+package hpke
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AEAD identifies the authenticated encryption algorithm of a suite.
+type AEAD uint16
+
+const (
+	// AES128GCM is AES-128-GCM.
+	AES128GCM AEAD = 0x0001
+	// ChaCha20Poly1305 is ChaCha20-Poly1305.
+	ChaCha20Poly1305 AEAD = 0x0003
+)
+
+func (a AEAD) String() string {
+	switch a {
+	case AES128GCM:
+		return "AES-128-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	}
+	return fmt.Sprintf("AEAD(%#04x)", uint16(a))
+}
+
+// keySize returns Nk, the length of the key of the AEAD.
+func (a AEAD) keySize() (int, error) {
+	switch a {
+	case AES128GCM:
+		return 16, nil
+	case ChaCha20Poly1305:
+		return chacha20poly1305.KeySize, nil
+	}
+	return 0, fmt.Errorf("unsupported AEAD %v", a)
+}
+
+func (a AEAD) new(key []byte) (cipher.AEAD, error) {
+	switch a {
+	case AES128GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, fmt.Errorf("unsupported AEAD %v", a)
+}
+
+// The modes of RFC 9180 section 5.
+const (
+	modeBase byte = 0x00
+	modeAuth byte = 0x02
+)
+
+// nn is the length of the nonce of both AEADs.
+const nn = 12
+
+// context is the encryption context of RFC 9180 section 5.2.
+type context struct {
+	aead           cipher.AEAD
+	baseNonce      []byte
+	exporterSecret []byte
+	seq            uint64
+	suiteID        []byte
+}
+
+// keySchedule is KeyScheduleS and KeyScheduleR of RFC 9180
+// section 5.1, without a pre-shared key.
+func keySchedule(mode byte, aead AEAD, sharedSecret, info []byte) (*context, error) {
+	nk, err := aead.keySize()
+	if err != nil {
+		return nil, err
+	}
+	suiteID := []byte{'H', 'P', 'K', 'E', kemID >> 8, kemID & 0xff, kdfID >> 8, kdfID & 0xff, byte(aead >> 8), byte(aead)}
+
+	pskIDHash := labeledExtract(suiteID, nil, "psk_id_hash", nil)
+	infoHash := labeledExtract(suiteID, nil, "info_hash", info)
+	keyScheduleContext := append(append([]byte{mode}, pskIDHash...), infoHash...)
+
+	secret := labeledExtract(suiteID, sharedSecret, "secret", nil)
+	key := labeledExpand(suiteID, secret, "key", keyScheduleContext, nk)
+
+	c, err := aead.new(key)
+	if err != nil {
+		return nil, err
+	}
+	return &context{
+		aead:           c,
+		baseNonce:      labeledExpand(suiteID, secret, "base_nonce", keyScheduleContext, nn),
+		exporterSecret: labeledExpand(suiteID, secret, "exp", keyScheduleContext, nh),
+		suiteID:        suiteID,
+	}, nil
+}
+
+// nonce returns the nonce for the current sequence number.
+func (c *context) nonce() []byte {
+	nonce := make([]byte, nn)
+	binary.BigEndian.PutUint64(nonce[nn-8:], c.seq)
+	for i := range nonce {
+		nonce[i] ^= c.baseNonce[i]
+	}
+	return nonce
+}
+
+// checkSeq returns an error if the sequence number cannot be
+// incremented after this message, which is the check made by
+// IncrementSeq in RFC 9180 section 5.2. It must be called before
+// the nonce is used, so that no message is sealed with a nonce
+// whose sequence number cannot be advanced past.
+func (c *context) checkSeq() error {
+	if c.seq == ^uint64(0) {
+		return errors.New("message limit reached")
+	}
+	return nil
+}
+
+// Export derives a secret of length bytes from the context,
+// bound to exporterContext, as specified by RFC 9180 section 5.3.
+func (c *context) Export(exporterContext []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*nh {
+		return nil, fmt.Errorf("invalid export length %d", length)
+	}
+	return labeledExpand(c.suiteID, c.exporterSecret, "sec", exporterContext, length), nil
+}
+
+// Sender encrypts a sequence of messages to a recipient.
+type Sender struct {
+	context
+}
+
+// NewSender sets up a base mode sender to the recipient public key pkR,
+// reading the ephemeral key from rand. It returns the encapsulated key
+// enc, which the recipient needs to decrypt the messages.
+func NewSender(rand io.Reader, pkR *ecdh.PublicKey, aead AEAD, info []byte) (enc []byte, s *Sender, err error) {
+	skE, err := GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newSender(skE, pkR, nil, aead, info)
+}
+
+// NewAuthSender sets up an auth mode sender, which proves to the
+// recipient that the messages were sent by the holder of skS.
+func NewAuthSender(rand io.Reader, pkR *ecdh.PublicKey, skS PrivateKey, aead AEAD, info []byte) (enc []byte, s *Sender, err error) {
+	if skS == nil {
+		return nil, nil, errors.New("sender private key is nil")
+	}
+	skE, err := GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newSender(skE, pkR, skS, aead, info)
+}
+
+func newSender(skE *ecdh.PrivateKey, pkR *ecdh.PublicKey, skS PrivateKey, aead AEAD, info []byte) ([]byte, *Sender, error) {
+	sharedSecret, enc, err := encap(skE, pkR, skS)
+	if err != nil {
+		return nil, nil, err
+	}
+	mode := modeBase
+	if skS != nil {
+		mode = modeAuth
+	}
+	c, err := keySchedule(mode, aead, sharedSecret, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, &Sender{context: *c}, nil
+}
+
+// Seal encrypts and authenticates plaintext, and authenticates aad.
+// Messages must be opened in the order they were sealed.
+func (s *Sender) Seal(aad, plaintext []byte) ([]byte, error) {
+	if err := s.checkSeq(); err != nil {
+		return nil, err
+	}
+	ciphertext := s.aead.Seal(nil, s.nonce(), plaintext, aad)
+	s.seq++
+	return ciphertext, nil
+}
+
+// Recipient decrypts a sequence of messages from a sender.
+type Recipient struct {
+	context
+}
+
+// NewRecipient sets up a base mode recipient of the encapsulated key
+// enc with its private key skR, which may be an enclavekey.Key.
+func NewRecipient(enc []byte, skR PrivateKey, aead AEAD, info []byte) (*Recipient, error) {
+	return newRecipient(enc, skR, nil, aead, info)
+}
+
+// NewAuthRecipient sets up an auth mode recipient, which only opens
+// messages sent by the holder of the private key of pkS.
+func NewAuthRecipient(enc []byte, skR PrivateKey, pkS *ecdh.PublicKey, aead AEAD, info []byte) (*Recipient, error) {
+	if pkS == nil {
+		return nil, errors.New("sender public key is nil")
+	}
+	return newRecipient(enc, skR, pkS, aead, info)
+}
+
+func newRecipient(enc []byte, skR PrivateKey, pkS *ecdh.PublicKey, aead AEAD, info []byte) (*Recipient, error) {
+	sharedSecret, err := decap(enc, skR, pkS)
+	if err != nil {
+		return nil, err
+	}
+	mode := modeBase
+	if pkS != nil {
+		mode = modeAuth
+	}
+	c, err := keySchedule(mode, aead, sharedSecret, info)
+	if err != nil {
+		return nil, err
+	}
+	return &Recipient{context: *c}, nil
+}
+
+// Open decrypts and authenticates ciphertext, and authenticates aad.
+func (r *Recipient) Open(aad, ciphertext []byte) ([]byte, error) {
+	if err := r.checkSeq(); err != nil {
+		return nil, err
+	}
+	plaintext, err := r.aead.Open(nil, r.nonce(), ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+	r.seq++
+	return plaintext, nil
+}
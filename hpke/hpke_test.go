@@ -0,0 +1,270 @@
+package hpke
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// hexBytes is a hex encoded field of the test vectors.
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	*h = b
+	return err
+}
+
+// vector is a test vector from RFC 9180 Appendix A, from
+// https://github.com/cfrg/draft-irtf-cfrg-hpke, keeping the
+// suites of this package and the first encryptions of each.
+type vector struct {
+	Mode           byte     `json:"mode"`
+	AEAD           AEAD     `json:"aead_id"`
+	Info           hexBytes `json:"info"`
+	IKME           hexBytes `json:"ikmE"`
+	IKMR           hexBytes `json:"ikmR"`
+	IKMS           hexBytes `json:"ikmS"`
+	SkRm           hexBytes `json:"skRm"`
+	SkEm           hexBytes `json:"skEm"`
+	SkSm           hexBytes `json:"skSm"`
+	PkRm           hexBytes `json:"pkRm"`
+	PkSm           hexBytes `json:"pkSm"`
+	Enc            hexBytes `json:"enc"`
+	SharedSecret   hexBytes `json:"shared_secret"`
+	ExporterSecret hexBytes `json:"exporter_secret"`
+	Encryptions    []struct {
+		AAD hexBytes `json:"aad"`
+		CT  hexBytes `json:"ct"`
+		PT  hexBytes `json:"pt"`
+	} `json:"encryptions"`
+	Exports []struct {
+		Context hexBytes `json:"exporter_context"`
+		L       int      `json:"L"`
+		Value   hexBytes `json:"exported_value"`
+	} `json:"exports"`
+}
+
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/rfc9180.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range vectors {
+		t.Run(fmt.Sprintf("mode_%d_%v", v.Mode, v.AEAD), func(t *testing.T) {
+			skE, err := DeriveKeyPair(v.IKME)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(skE.Bytes(), v.SkEm) {
+				t.Fatalf("DeriveKeyPair(ikmE) = %x, want %x", skE.Bytes(), v.SkEm)
+			}
+			skR, err := DeriveKeyPair(v.IKMR)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(skR.PublicKey().Bytes(), v.PkRm) {
+				t.Fatalf("DeriveKeyPair(ikmR) public key = %x, want %x", skR.PublicKey().Bytes(), v.PkRm)
+			}
+
+			var skS PrivateKey
+			var pkS *ecdh.PublicKey
+			if v.Mode == modeAuth {
+				sk, err := DeriveKeyPair(v.IKMS)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(sk.Bytes(), v.SkSm) {
+					t.Fatalf("DeriveKeyPair(ikmS) = %x, want %x", sk.Bytes(), v.SkSm)
+				}
+				skS, pkS = sk, sk.PublicKey()
+			}
+
+			enc, sender, err := newSender(skE, skR.PublicKey(), skS, v.AEAD, v.Info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(enc, v.Enc) {
+				t.Fatalf("enc = %x, want %x", enc, v.Enc)
+			}
+
+			recipient, err := newRecipient(enc, skR, pkS, v.AEAD, v.Info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(recipient.exporterSecret, v.ExporterSecret) {
+				t.Fatalf("exporter secret = %x, want %x", recipient.exporterSecret, v.ExporterSecret)
+			}
+
+			for i, e := range v.Encryptions {
+				ct, err := sender.Seal(e.AAD, e.PT)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(ct, e.CT) {
+					t.Errorf("encryption %d: Seal() = %x, want %x", i, ct, e.CT)
+				}
+				pt, err := recipient.Open(e.AAD, e.CT)
+				if err != nil {
+					t.Fatalf("encryption %d: Open() error = %v", i, err)
+				}
+				if !bytes.Equal(pt, e.PT) {
+					t.Errorf("encryption %d: Open() = %x, want %x", i, pt, e.PT)
+				}
+			}
+
+			for i, e := range v.Exports {
+				for _, c := range []*context{&sender.context, &recipient.context} {
+					got, err := c.Export(e.Context, e.L)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(got, e.Value) {
+						t.Errorf("export %d = %x, want %x", i, got, e.Value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	skR, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skS, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := []byte("test")
+
+	enc, sender, err := NewAuthSender(rand.Reader, skR.PublicKey(), skS, ChaCha20Poly1305, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := sender.Seal(nil, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipient, err := NewAuthRecipient(enc, skR, skS.PublicKey(), ChaCha20Poly1305, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pt, err := recipient.Open(nil, ct); err != nil || string(pt) != "hello" {
+		t.Errorf("Open() = %q, %v", pt, err)
+	}
+
+	tests := []struct {
+		name string
+		open func() (*Recipient, error)
+	}{
+		{name: "other_sender", open: func() (*Recipient, error) {
+			return NewAuthRecipient(enc, skR, other.PublicKey(), ChaCha20Poly1305, info)
+		}},
+		{name: "base_mode", open: func() (*Recipient, error) {
+			return NewRecipient(enc, skR, ChaCha20Poly1305, info)
+		}},
+		{name: "other_info", open: func() (*Recipient, error) {
+			return NewAuthRecipient(enc, skR, skS.PublicKey(), ChaCha20Poly1305, []byte("other"))
+		}},
+		{name: "other_aead", open: func() (*Recipient, error) {
+			return NewAuthRecipient(enc, skR, skS.PublicKey(), AES128GCM, info)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.Open(nil, ct); err == nil {
+				t.Errorf("Open() succeeded")
+			}
+		})
+	}
+}
+
+func TestOpen_Sequence(t *testing.T) {
+	skR, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, sender, err := NewSender(rand.Reader, skR.PublicKey(), AES128GCM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := NewRecipient(enc, skR, AES128GCM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := sender.Seal(nil, []byte("first"))
+	second, _ := sender.Seal(nil, []byte("second"))
+
+	// out of order messages fail without advancing the sequence.
+	if _, err := recipient.Open(nil, second); err == nil {
+		t.Fatal("Open() of the second message first succeeded")
+	}
+	for _, ct := range [][]byte{first, second} {
+		if _, err := recipient.Open(nil, ct); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSeal_MessageLimit(t *testing.T) {
+	skR, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, sender, err := NewSender(rand.Reader, skR.PublicKey(), AES128GCM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := NewRecipient(enc, skR, AES128GCM, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sender.seq = ^uint64(0) - 1
+	recipient.seq = sender.seq
+
+	ct, err := sender.Seal(nil, []byte("last"))
+	if err != nil {
+		t.Fatalf("Seal() of the last message error = %v", err)
+	}
+	if _, err := recipient.Open(nil, ct); err != nil {
+		t.Fatalf("Open() of the last message error = %v", err)
+	}
+
+	// no message is sealed with the final sequence number.
+	if ct, err := sender.Seal(nil, []byte("too many")); err == nil || ct != nil {
+		t.Errorf("Seal() past the message limit = %x, %v, want an error", ct, err)
+	}
+	if sender.seq != ^uint64(0) {
+		t.Errorf("Seal() past the message limit advanced the sequence")
+	}
+	if _, err := recipient.Open(nil, ct); err == nil {
+		t.Errorf("Open() past the message limit succeeded")
+	}
+}
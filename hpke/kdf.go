@@ -0,0 +1,46 @@
+package hpke
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// The KDF is HKDF-SHA256.
+const (
+	kdfID = 0x0001
+	nh    = sha256.Size
+)
+
+// versionLabel prefixes every labeled input, as specified
+// by RFC 9180 section 4.
+const versionLabel = "HPKE-v1"
+
+// labeledExtract is LabeledExtract of RFC 9180 section 4.
+func labeledExtract(suiteID []byte, salt []byte, label string, ikm []byte) []byte {
+	labeled := make([]byte, 0, len(versionLabel)+len(suiteID)+len(label)+len(ikm))
+	labeled = append(labeled, versionLabel...)
+	labeled = append(labeled, suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, ikm...)
+	return hkdf.Extract(sha256.New, labeled, salt)
+}
+
+// labeledExpand is LabeledExpand of RFC 9180 section 4.
+// length must be at most 255*nh.
+func labeledExpand(suiteID []byte, prk []byte, label string, info []byte, length int) []byte {
+	labeled := make([]byte, 2, 2+len(versionLabel)+len(suiteID)+len(label)+len(info))
+	binary.BigEndian.PutUint16(labeled, uint16(length))
+	labeled = append(labeled, versionLabel...)
+	labeled = append(labeled, suiteID...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, info...)
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, labeled), out); err != nil {
+		// length is at most 255*nh, the limit of HKDF.
+		panic(err)
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package hpke
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The KEM is DHKEM(P-256, HKDF-SHA256).
+const (
+	kemID   = 0x0010
+	nsecret = 32
+	nsk     = 32
+)
+
+var kemSuiteID = []byte{'K', 'E', 'M', kemID >> 8, kemID & 0xff}
+
+// PrivateKey is a P-256 KEM private key. It is implemented by
+// *ecdh.PrivateKey and by enclavekey.Key, whose key agreement
+// runs in the Secure Enclave.
+type PrivateKey interface {
+	// Public returns the public key, as an
+	// *ecdh.PublicKey or *ecdsa.PublicKey.
+	Public() crypto.PublicKey
+	// ECDH returns the shared secret with peer.
+	ECDH(peer *ecdh.PublicKey) ([]byte, error)
+}
+
+// GenerateKey generates a software KEM key pair.
+func GenerateKey(rand io.Reader) (*ecdh.PrivateKey, error) {
+	return ecdh.P256().GenerateKey(rand)
+}
+
+// DeriveKeyPair deterministically derives a software KEM key pair
+// from the input keying material ikm, as specified by RFC 9180
+// section 7.1.3.
+func DeriveKeyPair(ikm []byte) (*ecdh.PrivateKey, error) {
+	prk := labeledExtract(kemSuiteID, nil, "dkp_prk", ikm)
+	for counter := 0; counter < 256; counter++ {
+		candidate := labeledExpand(kemSuiteID, prk, "candidate", []byte{byte(counter)}, nsk)
+		// NewPrivateKey rejects scalars which are zero or not less than the order.
+		if key, err := ecdh.P256().NewPrivateKey(candidate); err == nil {
+			return key, nil
+		}
+	}
+	return nil, errors.New("deriving key pair: no valid candidate")
+}
+
+// publicKey returns the P-256 public key of k.
+func publicKey(k PrivateKey) (*ecdh.PublicKey, error) {
+	var pub *ecdh.PublicKey
+	switch p := k.Public().(type) {
+	case *ecdh.PublicKey:
+		pub = p
+	case *ecdsa.PublicKey:
+		var err error
+		if pub, err = p.ECDH(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", p)
+	}
+	if err := checkCurve(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func checkCurve(pub *ecdh.PublicKey) error {
+	if pub == nil {
+		return errors.New("public key is nil")
+	}
+	if pub.Curve() != ecdh.P256() {
+		return fmt.Errorf("DHKEM(P-256) requires a P-256 key but got %v", pub.Curve())
+	}
+	return nil
+}
+
+// encap is Encap, or AuthEncap if skS is not nil, of RFC 9180 section
+// 4.1, with the ephemeral key skE.
+func encap(skE *ecdh.PrivateKey, pkR *ecdh.PublicKey, skS PrivateKey) (sharedSecret, enc []byte, err error) {
+	if err := checkCurve(pkR); err != nil {
+		return nil, nil, err
+	}
+	dh, err := skE.ECDH(pkR)
+	if err != nil {
+		return nil, nil, err
+	}
+	enc = skE.PublicKey().Bytes()
+	kemContext := append(append([]byte{}, enc...), pkR.Bytes()...)
+
+	if skS != nil {
+		pkS, err := publicKey(skS)
+		if err != nil {
+			return nil, nil, err
+		}
+		dhS, err := skS.ECDH(pkR)
+		if err != nil {
+			return nil, nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, pkS.Bytes()...)
+	}
+
+	return extractAndExpand(dh, kemContext), enc, nil
+}
+
+// decap is Decap, or AuthDecap if pkS is not nil,
+// of RFC 9180 section 4.1.
+func decap(enc []byte, skR PrivateKey, pkS *ecdh.PublicKey) ([]byte, error) {
+	pkE, err := ecdh.P256().NewPublicKey(enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encapsulated key: %w", err)
+	}
+	pkR, err := publicKey(skR)
+	if err != nil {
+		return nil, err
+	}
+	dh, err := skR.ECDH(pkE)
+	if err != nil {
+		return nil, err
+	}
+	kemContext := append(append([]byte{}, enc...), pkR.Bytes()...)
+
+	if pkS != nil {
+		if err := checkCurve(pkS); err != nil {
+			return nil, err
+		}
+		dhS, err := skR.ECDH(pkS)
+		if err != nil {
+			return nil, err
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, pkS.Bytes()...)
+	}
+
+	return extractAndExpand(dh, kemContext), nil
+}
+
+func extractAndExpand(dh, kemContext []byte) []byte {
+	prk := labeledExtract(kemSuiteID, nil, "eae_prk", dh)
+	return labeledExpand(kemSuiteID, prk, "shared_secret", kemContext, nsecret)
+}
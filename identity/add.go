@@ -0,0 +1,69 @@
+package identity
+
+import (
+	"crypto/x509"
+	"errors"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+type AddCertificateInput struct {
+	// Scope selects the keychain holding the private key, which the
+	// certificate is added to, its access group and whether it is
+	// synchronised with iCloud Keychain. SynchronizableAny is not valid.
+	applesecurity.Scope
+
+	// Certificate is the certificate to add, such as one
+	// created for an enclavekey.Key with the certgen package.
+	Certificate *x509.Certificate
+	// Label to give the certificate in the keychain.
+	//
+	// Defaults to the common name of the certificate.
+	Label string
+}
+
+// AddCertificate adds a certificate for a private key which is already
+// in the keychain, such as a Secure Enclave key, forming an identity.
+//
+// The keychain pairs the certificate with the private key whose
+// application label is the SHA-1 hash of the certificate's public key.
+// Returns [applesecurity.ErrDuplicateItem] if the certificate is
+// already in the keychain.
+func AddCertificate(input AddCertificateInput) (*Identity, error) {
+	if input.Certificate == nil {
+		return nil, errors.New("a certificate is required")
+	}
+
+	publicKeyHash, err := pubkey.ApplicationLabel(input.Certificate.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	label := input.Label
+	if label == "" {
+		label = input.Certificate.Subject.CommonName
+	}
+
+	if err := addCertificate(input.Certificate, label, input.Scope); err != nil {
+		return nil, err
+	}
+
+	result := Identity{
+		Scope:         addedScope(input.Scope),
+		Label:         label,
+		Certificate:   input.Certificate,
+		PublicKeyHash: publicKeyHash,
+	}
+
+	return &result, nil
+}
+
+// addedScope returns the scope of an item added with the attributes in s.
+func addedScope(s applesecurity.Scope) applesecurity.Scope {
+	s.Keychain = s.Keychain.Resolve()
+	if s.Synchronizable == applesecurity.SynchronizableUnspecified {
+		s.Synchronizable = applesecurity.SynchronizableNo
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/x509"
+)
+
+// maxChainLength bounds buildChain, so that cross-signed
+// certificates cannot send it around in a loop.
+const maxChainLength = 10
+
+// buildChain returns leaf followed by its issuers, chosen from candidates.
+// It stops at a self-signed certificate or when no issuer can be found.
+func buildChain(leaf *x509.Certificate, candidates []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{leaf}
+
+	for current := leaf; len(chain) < maxChainLength; {
+		if bytes.Equal(current.RawIssuer, current.RawSubject) && current.CheckSignatureFrom(current) == nil {
+			break
+		}
+
+		var issuer *x509.Certificate
+		for _, c := range candidates {
+			if bytes.Equal(c.RawSubject, current.RawIssuer) && current.CheckSignatureFrom(c) == nil {
+				issuer = c
+				break
+			}
+		}
+		if issuer == nil || containsCertificate(chain, issuer) {
+			break
+		}
+
+		chain = append(chain, issuer)
+		current = issuer
+	}
+
+	return chain
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
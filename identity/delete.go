@@ -0,0 +1,119 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+type DeleteInput struct {
+	applesecurity.Scope
+
+	Label string
+
+	// PersistentRef selects exactly one identity. If
+	// it is set, Label is ignored.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// Delete identities in the keychain with the label or
+// persistent reference in DeleteInput.
+//
+// Both the certificate and the private key of each identity are
+// deleted. CA certificates are left in place, as other identities
+// may be issued by them.
+//
+// Returns a count of deleted identities. Returns ErrItemNotFound if
+// no identities were found matching the criteria.
+func Delete(input DeleteInput) (int, error) {
+	var identities []Identity
+	switch {
+	case !input.PersistentRef.IsZero():
+		identity, err := Get(GetInput{PersistentRef: input.PersistentRef, Keychain: input.Keychain})
+		if err != nil {
+			return 0, err
+		}
+		identities = append(identities, *identity)
+
+	case input.Label != "":
+		var err error
+		identities, err = List(ListInput{Scope: input.Scope, Label: input.Label})
+		if err != nil {
+			return 0, err
+		}
+		if len(identities) == 0 {
+			return 0, applesecurity.ErrItemNotFound
+		}
+
+	default:
+		return 0, errors.New("a label or persistent reference is required to delete identities")
+	}
+
+	var deleted int
+	for _, identity := range identities {
+		if err := deleteIdentity(identity); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// deleteIdentity deletes the private key and certificate of an identity.
+func deleteIdentity(identity Identity) error {
+	cfHash, err := corefoundation.NewCFData(identity.PublicKeyHash)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfHash))
+
+	scope := identity.itemScope()
+
+	err = deleteItems(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):                corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyClass):         corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+		corefoundation.TypeRef(C.kSecAttrApplicationLabel): corefoundation.TypeRef(cfHash),
+	}, scope)
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		return err
+	}
+
+	err = deleteItems(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):             corefoundation.TypeRef(C.kSecClassCertificate),
+		corefoundation.TypeRef(C.kSecAttrPublicKeyHash): corefoundation.TypeRef(cfHash),
+	}, scope)
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// deleteItems deletes items in scope matching m.
+func deleteItems(m corefoundation.Dictionary, scope applesecurity.Scope) error {
+	release, err := itemattr.AddScope(m, scope, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	query, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(query))
+
+	status := C.SecItemDelete(C.CFDictionaryRef(query))
+	return goError(status)
+}
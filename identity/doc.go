@@ -0,0 +1,8 @@
+// Package identity contains methods to work with identities:
+// a certificate paired with its private key in the keychain.
+//
+// Identities are usually distributed as password protected PKCS#12
+// (.p12/.pfx) files, which can be imported and exported here.
+//
+// See: https://developer.apple.com/documentation/security/certificate_key_and_trust_services/identities
+package identity
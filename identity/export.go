@@ -0,0 +1,51 @@
+package identity
+
+import (
+	"crypto/rand"
+
+	"github.com/common-fate/go-apple-security/keychainkey"
+	"github.com/common-fate/go-apple-security/pkcs12"
+)
+
+type ExportInput struct {
+	Identity Identity
+	// Password to protect the PKCS#12 file with.
+	Password string
+	// Legacy selects algorithms understood by older software.
+	// See [pkcs12.Legacy].
+	Legacy bool
+}
+
+// Export returns an identity and its certificate chain as a PKCS#12 file,
+// which is the equivalent of 'security export'.
+//
+// The private key must be extractable: keys held by the Secure Enclave
+// or a smart card cannot be exported.
+func Export(input ExportInput) ([]byte, error) {
+	key, err := keychainkey.Get(keychainkey.GetInput{Scope: input.Identity.itemScope(), ApplicationLabel: input.Identity.PublicKeyHash})
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := key.ExportPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := input.Identity.CertificateChain()
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := pkcs12.Modern
+	if input.Legacy {
+		encoder = pkcs12.Legacy
+	}
+
+	return encoder.Encode(rand.Reader, &pkcs12.Bundle{
+		PrivateKey:     privateKey,
+		Certificate:    chain[0],
+		CACertificates: chain[1:],
+		FriendlyName:   input.Identity.Label,
+	}, input.Password)
+}
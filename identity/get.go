@@ -0,0 +1,49 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+type GetInput struct {
+	// PersistentRef selects the identity, as returned in
+	// [Identity.PersistentRef] by List.
+	PersistentRef applesecurity.PersistentRef
+
+	// Keychain is the keychain holding the identity.
+	Keychain applesecurity.Keychain
+}
+
+// Get returns the identity referred to by input.PersistentRef.
+//
+// Returns [applesecurity.ErrItemNotFound] if it no longer exists.
+func Get(input GetInput) (*Identity, error) {
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):      corefoundation.TypeRef(C.kSecClassIdentity),
+		corefoundation.TypeRef(C.kSecMatchLimit): corefoundation.TypeRef(C.kSecMatchLimitAll),
+	}
+
+	release, err := itemattr.AddPersistentRef(m, input.PersistentRef, input.Keychain)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	identities, err := find(m, input.Keychain)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, applesecurity.ErrItemNotFound
+	}
+	return &identities[0], nil
+}
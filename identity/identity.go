@@ -0,0 +1,194 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/keychainkey"
+)
+
+const (
+	nilSecCertificate C.SecCertificateRef = 0
+	nilCFData         C.CFDataRef         = 0
+	nilCFDictionary   C.CFDictionaryRef   = 0
+)
+
+// Identity is a certificate and the private key it certifies,
+// both stored in the keychain.
+type Identity struct {
+	// Scope is the keychain and access group of the identity,
+	// and whether it is synchronised with iCloud Keychain.
+	applesecurity.Scope
+
+	// Label is the keychain label of the certificate.
+	Label       string
+	Certificate *x509.Certificate
+	// PublicKeyHash is the SHA-1 hash of the public key. The keychain
+	// uses it to pair the certificate with its private key, whose
+	// ApplicationLabel it is.
+	PublicKeyHash []byte
+	// PersistentRef refers to exactly this identity. It is
+	// set on identities returned by Get and List.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// Signer returns the private key of the identity as a crypto.Signer.
+//
+// The private key does not leave the keychain: signatures are made
+// by the Security framework.
+func (i *Identity) Signer() (crypto.Signer, error) {
+	switch i.Certificate.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", i.Certificate.PublicKey)
+	}
+
+	return &keychainkey.Key{
+		Scope:            i.Scope,
+		ApplicationLabel: i.PublicKeyHash,
+		PublicKey:        i.Certificate.PublicKey,
+		Label:            i.Label,
+	}, nil
+}
+
+// itemScope returns the scope of queries for the
+// certificate and private key of the identity.
+func (i *Identity) itemScope() applesecurity.Scope {
+	return applesecurity.Scope{
+		Keychain:       i.Keychain,
+		AccessGroup:    i.AccessGroup,
+		Synchronizable: applesecurity.SynchronizableAny,
+	}
+}
+
+// CertificateChain returns the certificate of the identity followed by
+// its issuers, as far as they can be found in the keychain. Issuers
+// may be in any access group and need not be synchronised like the
+// identity is.
+func (i *Identity) CertificateChain() ([]*x509.Certificate, error) {
+	candidates, err := listCertificates(applesecurity.Scope{
+		Keychain:       i.Keychain,
+		Synchronizable: applesecurity.SynchronizableAny,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buildChain(i.Certificate, candidates), nil
+}
+
+// listCertificates returns every certificate in scope.
+func listCertificates(scope applesecurity.Scope) ([]*x509.Certificate, error) {
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):      corefoundation.TypeRef(C.kSecClassCertificate),
+		corefoundation.TypeRef(C.kSecReturnData): corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit): corefoundation.TypeRef(C.kSecMatchLimitAll),
+	}
+
+	release, err := itemattr.AddScope(m, scope, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(query))
+
+	var resultsRef C.CFTypeRef
+	status := C.SecItemCopyMatching(C.CFDictionaryRef(query), &resultsRef)
+	err = goError(status)
+	if errors.Is(err, applesecurity.ErrItemNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(resultsRef)
+
+	var certs []*x509.Certificate
+	for _, ref := range corefoundation.CFArrayToArray(corefoundation.ArrayRef(resultsRef)) {
+		if C.CFGetTypeID(C.CFTypeRef(ref)) != C.CFDataGetTypeID() {
+			continue
+		}
+		cert, err := x509.ParseCertificate(corefoundation.CFDataToBytes(corefoundation.DataRef(ref)))
+		if err != nil {
+			// the keychain may hold certificates Go cannot parse,
+			// they cannot be part of a chain we return.
+			continue
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// extractIdentity reads the certificate and private key hash of an identity.
+func extractIdentity(ref C.SecIdentityRef) (*Identity, error) {
+	var certRef C.SecCertificateRef
+	status := C.SecIdentityCopyCertificate(ref, &certRef)
+	if err := goError(status); err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(certRef))
+
+	certData := C.SecCertificateCopyData(certRef)
+	if certData == nilCFData {
+		return nil, fmt.Errorf("cannot extract certificate data")
+	}
+	defer C.CFRelease(C.CFTypeRef(certData))
+
+	cert, err := x509.ParseCertificate(corefoundation.CFDataToBytes(corefoundation.DataRef(certData)))
+	if err != nil {
+		return nil, err
+	}
+
+	var keyRef C.SecKeyRef
+	status = C.SecIdentityCopyPrivateKey(ref, &keyRef)
+	if err := goError(status); err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(keyRef))
+
+	keyAttrs := C.SecKeyCopyAttributes(keyRef)
+	if keyAttrs == nilCFDictionary {
+		return nil, fmt.Errorf("cannot read private key attributes")
+	}
+	defer C.CFRelease(C.CFTypeRef(keyAttrs))
+
+	result := Identity{
+		Certificate:   cert,
+		PublicKeyHash: corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(keyAttrs), corefoundation.DataRef(C.kSecAttrApplicationLabel)),
+	}
+
+	return &result, nil
+}
+
+// cfTypeDescription returns type string for CFTypeRef.
+func cfTypeDescription(ref C.CFTypeRef) string {
+	typeID := C.CFGetTypeID(ref)
+	typeDesc := C.CFCopyTypeIDDescription(typeID)
+	defer C.CFRelease(C.CFTypeRef(typeDesc))
+	return corefoundation.CFStringToString(corefoundation.StringRef(typeDesc))
+}
+
+// dictionaryValue returns the value for key in d, or nil.
+func dictionaryValue(d C.CFDictionaryRef, key C.CFStringRef) C.CFTypeRef {
+	return C.CFTypeRef(C.CFDictionaryGetValue(d, unsafe.Pointer(key)))
+}
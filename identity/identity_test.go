@@ -0,0 +1,452 @@
+package identity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/certgen"
+	"github.com/common-fate/go-apple-security/enclavekey"
+	"github.com/common-fate/go-apple-security/pkcs12"
+)
+
+func TestImportExport(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		label string
+		key   crypto.Signer
+		opts  crypto.SignerOpts
+	}{
+		{
+			name:  "ec",
+			label: "com.example.goapplesecurity.test.identity.ec",
+			key:   ecKey,
+			opts:  crypto.SHA256,
+		},
+		{
+			name:  "rsa_pss",
+			label: "com.example.goapplesecurity.test.identity.rsa",
+			key:   rsaKey,
+			opts:  &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// delete any existing identities
+			_, err := Delete(DeleteInput{Label: tt.label})
+			if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+				t.Fatalf("error deleting existing identities: %v", err)
+			}
+
+			caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ca := newTestCertificate(t, "go-apple-security test CA", caKey.Public(), nil, caKey)
+			leaf := newTestCertificate(t, tt.label, tt.key.Public(), ca, caKey)
+
+			p12, err := pkcs12.Encode(rand.Reader, &pkcs12.Bundle{
+				PrivateKey:     tt.key,
+				Certificate:    leaf,
+				CACertificates: []*x509.Certificate{ca},
+			}, "correct-horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			imported, err := Import(ImportInput{
+				Data:     p12,
+				Password: "correct-horse",
+				Label:    tt.label,
+			})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			got, err := List(ListInput{Label: tt.label})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("wanted 1 identity but got %v", len(got))
+			}
+			if !got[0].Certificate.Equal(leaf) {
+				t.Errorf("listed certificate does not match imported certificate")
+			}
+			if string(got[0].PublicKeyHash) != string(imported.PublicKeyHash) {
+				t.Errorf("got PublicKeyHash = %x, want = %x", got[0].PublicKeyHash, imported.PublicKeyHash)
+			}
+
+			byRef, err := Get(GetInput{PersistentRef: got[0].PersistentRef})
+			if err != nil {
+				t.Fatalf("Get() by reference error = %v", err)
+			}
+			if !byRef.Certificate.Equal(leaf) {
+				t.Errorf("certificate from Get() by reference does not match imported certificate")
+			}
+
+			signer, err := got[0].Signer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			digest := sha256.Sum256([]byte("hello"))
+			sig, err := signer.Sign(rand.Reader, digest[:], tt.opts)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if err := leaf.CheckSignature(signatureAlgorithmFor(tt.opts, tt.key), []byte("hello"), sig); err != nil {
+				t.Errorf("invalid signature: %v", err)
+			}
+
+			chain, err := got[0].CertificateChain()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chain) != 2 || !chain[1].Equal(ca) {
+				t.Errorf("wanted chain of leaf and CA, got %d certificates", len(chain))
+			}
+
+			exported, err := Export(ExportInput{Identity: got[0], Password: "battery-staple"})
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			bundle, err := pkcs12.Decode(exported, "battery-staple")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(bundle.PrivateKey) {
+				t.Errorf("exported private key does not match imported private key")
+			}
+
+			deleted, err := Delete(DeleteInput{Label: tt.label})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Errorf("wanted 1 identity deleted but got %v", deleted)
+			}
+		})
+	}
+}
+
+func TestUpdate_PersistentRef(t *testing.T) {
+	const label = "com.example.goapplesecurity.test.identity.update"
+	const renamed = label + ".renamed"
+
+	for _, l := range []string{label, renamed} {
+		_, err := Delete(DeleteInput{Label: l})
+		if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+			t.Fatalf("error deleting existing identities: %v", err)
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, label, key.Public(), nil, key)
+	p12, err := pkcs12.Encode(rand.Reader, &pkcs12.Bundle{PrivateKey: key, Certificate: cert}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(ImportInput{Data: p12, Label: label}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := List(ListInput{Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("wanted 1 identity but got %v", len(got))
+	}
+	ref := got[0].PersistentRef
+
+	if err := Update(UpdateInput{PersistentRef: ref, Label: renamed}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	updated, err := Get(GetInput{PersistentRef: ref})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Label != renamed {
+		t.Errorf("got Label = %q, want %q", updated.Label, renamed)
+	}
+
+	deleted, err := Delete(DeleteInput{PersistentRef: ref})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 identity deleted but got %v", deleted)
+	}
+}
+
+func TestImport_LegacyKeychain(t *testing.T) {
+	const label = "com.example.goapplesecurity.test.identity.legacy"
+	legacy := applesecurity.Scope{Keychain: applesecurity.LegacyKeychain}
+
+	_, err := Delete(DeleteInput{Scope: legacy, Label: label})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing identities: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, label, key.Public(), nil, key)
+	p12, err := pkcs12.Encode(rand.Reader, &pkcs12.Bundle{PrivateKey: key, Certificate: cert}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(ImportInput{Scope: legacy, Data: p12, Label: label}); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	// the identity is only visible in the keychain it was added to.
+	got, err := List(ListInput{Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("wanted no identities in the data protection keychain but got %v", len(got))
+	}
+
+	got, err = List(ListInput{Scope: legacy, Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("wanted 1 identity but got %v", len(got))
+	}
+	if got[0].Keychain != applesecurity.LegacyKeychain {
+		t.Errorf("got Keychain = %v, want %v", got[0].Keychain, applesecurity.LegacyKeychain)
+	}
+
+	signer, err := got[0].Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if err := cert.CheckSignature(x509.ECDSAWithSHA256, []byte("hello"), sig); err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+
+	chain, err := got[0].CertificateChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 1 || !chain[0].Equal(cert) {
+		t.Errorf("wanted chain of the certificate, got %d certificates", len(chain))
+	}
+
+	deleted, err := Delete(DeleteInput{Scope: legacy, PersistentRef: got[0].PersistentRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 identity deleted but got %v", deleted)
+	}
+}
+
+func TestImport_Synchronizable(t *testing.T) {
+	const label = "com.example.goapplesecurity.test.identity.synchronizable"
+	anySync := applesecurity.Scope{Synchronizable: applesecurity.SynchronizableAny}
+
+	_, err := Delete(DeleteInput{Scope: anySync, Label: label})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing identities: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := newTestCertificate(t, label, key.Public(), nil, key)
+	p12, err := pkcs12.Encode(rand.Reader, &pkcs12.Bundle{PrivateKey: key, Certificate: cert}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Import(ImportInput{
+		Scope: applesecurity.Scope{Synchronizable: applesecurity.SynchronizableYes},
+		Data:  p12,
+		Label: label,
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	got, err := List(ListInput{Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("List() without SynchronizableAny found %v identities, want none", len(got))
+	}
+
+	got, err = List(ListInput{Scope: anySync, Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("wanted 1 identity but got %v", len(got))
+	}
+	if got[0].Synchronizable != applesecurity.SynchronizableYes {
+		t.Errorf("got Synchronizable = %v, want %v", got[0].Synchronizable, applesecurity.SynchronizableYes)
+	}
+	if got[0].AccessGroup == "" {
+		t.Errorf("got empty AccessGroup")
+	}
+
+	signer, err := got[0].Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("hello"))
+	if _, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if err := Update(UpdateInput{PersistentRef: got[0].PersistentRef, Label: label}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	deleted, err := Delete(DeleteInput{Scope: anySync, Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 identity deleted but got %v", deleted)
+	}
+}
+
+func signatureAlgorithmFor(opts crypto.SignerOpts, key crypto.Signer) x509.SignatureAlgorithm {
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		return x509.ECDSAWithSHA256
+	}
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return x509.SHA256WithRSAPSS
+	}
+	return x509.SHA256WithRSA
+}
+
+func newTestCertificate(t *testing.T, cn string, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestAddCertificate_EnclaveKey(t *testing.T) {
+	const label = "com.example.goapplesecurity.test.identity.enclave"
+
+	_, err := Delete(DeleteInput{Label: label})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing identities: %v", err)
+	}
+	_, err = enclavekey.Delete(enclavekey.DeleteInput{Tag: label})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	key, err := enclavekey.Create(enclavekey.CreateInput{Tag: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := certgen.CreateSelfSigned(key, &certgen.Template{
+		Subject:     pkix.Name{CommonName: label},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		t.Fatalf("CreateSelfSigned() error = %v", err)
+	}
+
+	added, err := AddCertificate(AddCertificateInput{Certificate: cert})
+	if err != nil {
+		t.Fatalf("AddCertificate() error = %v", err)
+	}
+	if string(added.PublicKeyHash) != string(key.ApplicationLabel) {
+		t.Errorf("got PublicKeyHash = %x, want the key's application label %x", added.PublicKeyHash, key.ApplicationLabel)
+	}
+
+	got, err := List(ListInput{Label: label})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 1 || !got[0].Certificate.Equal(cert) {
+		t.Fatalf("wanted the added certificate as an identity, got %d identities", len(got))
+	}
+
+	byHash, err := List(ListInput{PublicKeyHash: key.ApplicationLabel})
+	if err != nil {
+		t.Fatalf("List() by public key hash error = %v", err)
+	}
+	if len(byHash) != 1 || !byHash[0].Certificate.Equal(cert) {
+		t.Fatalf("wanted the added certificate by public key hash, got %d identities", len(byHash))
+	}
+
+	tlsCert, err := got[0].TLSCertificate(key)
+	if err != nil {
+		t.Fatalf("TLSCertificate() error = %v", err)
+	}
+	if tlsCert.PrivateKey != crypto.Signer(key) || len(tlsCert.Certificate) != 1 || !tlsCert.Leaf.Equal(cert) {
+		t.Errorf("TLSCertificate() did not return the enclave key and its certificate")
+	}
+
+	deleted, err := Delete(DeleteInput{Label: label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 identity deleted but got %v", deleted)
+	}
+}
@@ -0,0 +1,139 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/keychainkey"
+	"github.com/common-fate/go-apple-security/pkcs12"
+)
+
+type ImportInput struct {
+	// Scope selects the keychain to add the private key and
+	// certificates to, their access group and whether they are
+	// synchronised with iCloud Keychain. SynchronizableAny is not valid.
+	applesecurity.Scope
+
+	// Data is the content of a PKCS#12 (.p12/.pfx) file.
+	Data []byte
+	// Password the PKCS#12 file is protected with.
+	Password string
+	// Label to give the certificate and private key in the keychain.
+	//
+	// Defaults to the friendly name in the PKCS#12 file,
+	// or else the common name of the certificate.
+	Label string
+}
+
+// Import adds the private key and certificates in a PKCS#12 file to
+// the keychain, which is the equivalent of 'security import'.
+//
+// Certificates which are already in the keychain, such as a shared
+// CA certificate, are skipped. Returns [applesecurity.ErrDuplicateItem]
+// if the private key is already in the keychain.
+func Import(input ImportInput) (*Identity, error) {
+	bundle, err := pkcs12.Decode(input.Data, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	label := input.Label
+	if label == "" {
+		label = bundle.FriendlyName
+	}
+	if label == "" {
+		label = bundle.Certificate.Subject.CommonName
+	}
+
+	key, err := keychainkey.Import(keychainkey.ImportInput{
+		Scope:      input.Scope,
+		PrivateKey: bundle.PrivateKey,
+		Label:      label,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = addCertificate(bundle.Certificate, label, input.Scope)
+	if err != nil && !errors.Is(err, applesecurity.ErrDuplicateItem) {
+		// don't leave the private key behind without its certificate.
+		if _, delErr := keychainkey.Delete(keychainkey.DeleteInput{Scope: key.Scope, PersistentRef: key.PersistentRef}); delErr != nil {
+			return nil, fmt.Errorf("%w (and removing the imported private key failed: %v)", err, delErr)
+		}
+		return nil, err
+	}
+
+	for _, cert := range bundle.CACertificates {
+		err := addCertificate(cert, "", input.Scope)
+		if err != nil && !errors.Is(err, applesecurity.ErrDuplicateItem) {
+			return nil, err
+		}
+	}
+
+	result := Identity{
+		Scope:         key.Scope,
+		Label:         label,
+		Certificate:   bundle.Certificate,
+		PublicKeyHash: key.ApplicationLabel,
+	}
+
+	return &result, nil
+}
+
+// addCertificate adds a certificate to the keychain in scope.
+// The label is optional.
+func addCertificate(cert *x509.Certificate, label string, scope applesecurity.Scope) error {
+	cfCertData, err := corefoundation.NewCFData(cert.Raw)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfCertData))
+
+	secCert := C.SecCertificateCreateWithData(C.kCFAllocatorDefault, C.CFDataRef(cfCertData))
+	if secCert == nilSecCertificate {
+		return fmt.Errorf("error creating certificate")
+	}
+	defer C.CFRelease(C.CFTypeRef(secCert))
+
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):    corefoundation.TypeRef(C.kSecClassCertificate),
+		corefoundation.TypeRef(C.kSecValueRef): corefoundation.TypeRef(secCert),
+	}
+
+	release, err := itemattr.AddScope(m, scope, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if label != "" {
+		cfLabel, err := corefoundation.NewCFString(label)
+		if err != nil {
+			return err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	attrs, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	status := C.SecItemAdd(C.CFDictionaryRef(attrs), nil)
+	return goError(status)
+}
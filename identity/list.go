@@ -0,0 +1,116 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+type ListInput struct {
+	applesecurity.Scope
+
+	// Label filters identities by the label of their certificate.
+	Label string
+
+	// PublicKeyHash filters identities by the SHA-1 hash of their
+	// public key, such as the ApplicationLabel of an enclavekey.Key.
+	PublicKeyHash []byte
+}
+
+// List identities in the keychain matching the criteria in ListInput.
+//
+// Returns nil if no identities are found.
+func List(input ListInput) ([]Identity, error) {
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):      corefoundation.TypeRef(C.kSecClassIdentity),
+		corefoundation.TypeRef(C.kSecMatchLimit): corefoundation.TypeRef(C.kSecMatchLimitAll),
+	}
+
+	release, err := itemattr.AddScope(m, input.Scope, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if input.Label != "" {
+		cfLabel, err := corefoundation.NewCFString(input.Label)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	if len(input.PublicKeyHash) > 0 {
+		cfHash, err := corefoundation.NewCFData(input.PublicKeyHash)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfHash))
+
+		m[corefoundation.TypeRef(C.kSecAttrPublicKeyHash)] = corefoundation.TypeRef(cfHash)
+	}
+
+	return find(m, input.Keychain)
+}
+
+// find returns the identities matching the query m of keychain k, adding
+// the attributes to return. Returns nil if no identities are found.
+func find(m corefoundation.Dictionary, k applesecurity.Keychain) ([]Identity, error) {
+	m[corefoundation.TypeRef(C.kSecReturnRef)] = corefoundation.TypeRef(C.kCFBooleanTrue)
+	m[corefoundation.TypeRef(C.kSecReturnAttributes)] = corefoundation.TypeRef(C.kCFBooleanTrue)
+	m[corefoundation.TypeRef(C.kSecReturnPersistentRef)] = corefoundation.TypeRef(C.kCFBooleanTrue)
+
+	query, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(query))
+
+	var resultsRef C.CFTypeRef
+	status := C.SecItemCopyMatching(C.CFDictionaryRef(query), &resultsRef)
+	err = goError(status)
+	if errors.Is(err, applesecurity.ErrItemNotFound) {
+		// no items found, return nil.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(resultsRef)
+
+	var results []Identity
+
+	arr := corefoundation.CFArrayToArray(corefoundation.ArrayRef(resultsRef))
+	for _, ref := range arr {
+		elementTypeID := C.CFGetTypeID(C.CFTypeRef(ref))
+		if elementTypeID != C.CFDictionaryGetTypeID() {
+			return nil, fmt.Errorf("Invalid result type within array: %s", cfTypeDescription(C.CFTypeRef(ref)))
+		}
+
+		d := C.CFDictionaryRef(ref)
+		identity, err := extractIdentity(C.SecIdentityRef(dictionaryValue(d, C.kSecValueRef)))
+		if err != nil {
+			return nil, err
+		}
+		identity.Label = corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(d), corefoundation.StringRef(C.kSecAttrLabel))
+		identity.PersistentRef = itemattr.PersistentRef(corefoundation.DictionaryRef(d))
+		identity.Scope = itemattr.Scope(corefoundation.DictionaryRef(d), k)
+
+		results = append(results, *identity)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+
+	"github.com/common-fate/go-apple-security/mtls"
+)
+
+// TLSCertificate returns the identity as a client or server certificate
+// for crypto/tls, presenting its certificate chain from the keychain
+// without the self-signed root, which peers must already trust.
+//
+// signer is the private key of the identity, such as the enclavekey.Key
+// the certificate was issued for. If it is nil, the key returned by
+// Signer is used.
+func (i *Identity) TLSCertificate(signer crypto.Signer) (tls.Certificate, error) {
+	if signer == nil {
+		var err error
+		if signer, err = i.Signer(); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	chain, err := i.CertificateChain()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if root := chain[len(chain)-1]; len(chain) > 1 && bytes.Equal(root.RawIssuer, root.RawSubject) {
+		chain = chain[:len(chain)-1]
+	}
+
+	return mtls.Certificate(signer, chain)
+}
@@ -0,0 +1,93 @@
+package identity
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+type UpdateInput struct {
+	// PersistentRef selects the identity to update.
+	PersistentRef applesecurity.PersistentRef
+	// Keychain is the keychain holding the identity.
+	Keychain applesecurity.Keychain
+	// Label to give the certificate and private key of the identity.
+	Label string
+}
+
+// Update relabels the certificate and private key of the
+// identity referred to by input.PersistentRef.
+func Update(input UpdateInput) error {
+	if input.Label == "" {
+		return errors.New("a label is required to update an identity")
+	}
+
+	identity, err := Get(GetInput{PersistentRef: input.PersistentRef, Keychain: input.Keychain})
+	if err != nil {
+		return err
+	}
+
+	cfHash, err := corefoundation.NewCFData(identity.PublicKeyHash)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfHash))
+
+	cfLabel, err := corefoundation.NewCFString(input.Label)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+	attrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrLabel): corefoundation.TypeRef(cfLabel),
+	})
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	scope := identity.itemScope()
+
+	err = updateItems(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):             corefoundation.TypeRef(C.kSecClassCertificate),
+		corefoundation.TypeRef(C.kSecAttrPublicKeyHash): corefoundation.TypeRef(cfHash),
+	}, scope, C.CFDictionaryRef(attrs))
+	if err != nil {
+		return err
+	}
+
+	return updateItems(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):                corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyClass):         corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+		corefoundation.TypeRef(C.kSecAttrApplicationLabel): corefoundation.TypeRef(cfHash),
+	}, scope, C.CFDictionaryRef(attrs))
+}
+
+// updateItems sets attrs on the items in scope matching m.
+func updateItems(m corefoundation.Dictionary, scope applesecurity.Scope, attrs C.CFDictionaryRef) error {
+	release, err := itemattr.AddScope(m, scope, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	query, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(query))
+
+	status := C.SecItemUpdate(C.CFDictionaryRef(query), attrs)
+	return goError(status)
+}
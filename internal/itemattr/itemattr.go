@@ -0,0 +1,143 @@
+// Package itemattr converts attributes common to every class
+// of keychain item between Go and CoreFoundation.
+package itemattr
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+// AddScope selects the keychain of s in m, and sets its access group
+// and synchronizable attributes. query must be true if m is a query
+// rather than the attributes of a new item, allowing SynchronizableAny.
+//
+// The legacy keychain has neither access groups nor iCloud sync, so
+// setting them is an error, while SynchronizableNo and
+// SynchronizableAny describe every item in it and are ignored.
+//
+// The caller must call release once m is no longer used.
+func AddScope(m corefoundation.Dictionary, s applesecurity.Scope, query bool) (release func(), err error) {
+	release = func() {}
+
+	if s.Keychain.Resolve() == applesecurity.LegacyKeychain {
+		if s.AccessGroup != "" {
+			return nil, errors.New("access groups are not supported by the legacy keychain")
+		}
+		if s.Synchronizable == applesecurity.SynchronizableYes {
+			return nil, errors.New("the legacy keychain cannot be synchronised with iCloud Keychain")
+		}
+		if s.Synchronizable == applesecurity.SynchronizableAny && !query {
+			return nil, fmt.Errorf("synchronizable %v is only valid in queries", s.Synchronizable)
+		}
+		return release, nil
+	}
+
+	if err := addKeychain(m, s.Keychain); err != nil {
+		return nil, err
+	}
+
+	switch s.Synchronizable {
+	case applesecurity.SynchronizableUnspecified:
+	case applesecurity.SynchronizableYes:
+		m[corefoundation.TypeRef(C.kSecAttrSynchronizable)] = corefoundation.TypeRef(C.kCFBooleanTrue)
+	case applesecurity.SynchronizableNo:
+		m[corefoundation.TypeRef(C.kSecAttrSynchronizable)] = corefoundation.TypeRef(C.kCFBooleanFalse)
+	case applesecurity.SynchronizableAny:
+		if !query {
+			return nil, fmt.Errorf("synchronizable %v is only valid in queries", s.Synchronizable)
+		}
+		m[corefoundation.TypeRef(C.kSecAttrSynchronizable)] = corefoundation.TypeRef(C.kSecAttrSynchronizableAny)
+	default:
+		return nil, fmt.Errorf("invalid synchronizable value %v", s.Synchronizable)
+	}
+
+	if s.AccessGroup != "" {
+		cfGroup, err := corefoundation.NewCFString(s.AccessGroup)
+		if err != nil {
+			return nil, err
+		}
+		release = func() { C.CFRelease(C.CFTypeRef(cfGroup)) }
+
+		m[corefoundation.TypeRef(C.kSecAttrAccessGroup)] = corefoundation.TypeRef(cfGroup)
+	}
+
+	return release, nil
+}
+
+// addKeychain sets kSecUseDataProtectionKeychain in m
+// unless k resolves to the legacy keychain.
+func addKeychain(m corefoundation.Dictionary, k applesecurity.Keychain) error {
+	switch k.Resolve() {
+	case applesecurity.DataProtectionKeychain:
+		m[corefoundation.TypeRef(C.kSecUseDataProtectionKeychain)] = corefoundation.TypeRef(C.kCFBooleanTrue)
+	case applesecurity.LegacyKeychain:
+	default:
+		return fmt.Errorf("invalid keychain %v", k)
+	}
+	return nil
+}
+
+// Scope reads the access group and synchronizable attributes of an
+// item returned with kSecReturnAttributes from a query of keychain k.
+func Scope(d corefoundation.DictionaryRef, k applesecurity.Keychain) applesecurity.Scope {
+	s := applesecurity.Scope{
+		Keychain:       k.Resolve(),
+		AccessGroup:    corefoundation.GetDictionaryStringValue(d, corefoundation.StringRef(C.kSecAttrAccessGroup)),
+		Synchronizable: applesecurity.SynchronizableNo,
+	}
+
+	sync := C.CFTypeRef(C.CFDictionaryGetValue(C.CFDictionaryRef(d), unsafe.Pointer(C.kSecAttrSynchronizable)))
+	if sync != 0 && C.CFGetTypeID(sync) == C.CFBooleanGetTypeID() && C.CFBooleanGetValue(C.CFBooleanRef(sync)) != 0 {
+		s.Synchronizable = applesecurity.SynchronizableYes
+	}
+
+	return s
+}
+
+// AddPersistentRef adds ref to the query m, which then matches the
+// one item it refers to in keychain k. Every other attribute in m
+// must agree with the item, so synchronised items are included.
+//
+// The caller must call release once m is no longer used.
+func AddPersistentRef(m corefoundation.Dictionary, ref applesecurity.PersistentRef, k applesecurity.Keychain) (release func(), err error) {
+	if ref.IsZero() {
+		return nil, errors.New("persistent reference is empty")
+	}
+	data, _ := ref.MarshalBinary()
+
+	if k.Resolve() != applesecurity.LegacyKeychain {
+		if err := addKeychain(m, k); err != nil {
+			return nil, err
+		}
+		m[corefoundation.TypeRef(C.kSecAttrSynchronizable)] = corefoundation.TypeRef(C.kSecAttrSynchronizableAny)
+	}
+
+	cfRef, err := corefoundation.NewCFData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m[corefoundation.TypeRef(C.kSecValuePersistentRef)] = corefoundation.TypeRef(cfRef)
+
+	return func() { C.CFRelease(C.CFTypeRef(cfRef)) }, nil
+}
+
+// PersistentRef reads the persistent reference of an item
+// returned with kSecReturnPersistentRef and kSecReturnAttributes.
+func PersistentRef(d corefoundation.DictionaryRef) applesecurity.PersistentRef {
+	var ref applesecurity.PersistentRef
+	_ = ref.UnmarshalBinary(corefoundation.GetDictionaryDataValue(d, corefoundation.DataRef(C.kSecValuePersistentRef)))
+	return ref
+}
@@ -0,0 +1,186 @@
+// Package keyrep converts Go keys to and from the external representation
+// used by SecKeyCreateWithData and SecKeyCopyExternalRepresentation.
+//
+// RSA keys are PKCS#1 DER. Elliptic curve public keys are the uncompressed
+// X9.63 point 04 || X || Y, and private keys append the scalar: 04 || X || Y || D.
+//
+// See: https://developer.apple.com/documentation/security/1643698-seckeycopyexternalrepresentation
+package keyrep
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// KeyType is the algorithm family of a key, corresponding to kSecAttrKeyType.
+type KeyType int
+
+const (
+	// RSA corresponds to kSecAttrKeyTypeRSA.
+	RSA KeyType = iota + 1
+	// EC corresponds to kSecAttrKeyTypeECSECPrimeRandom.
+	EC
+)
+
+func (t KeyType) String() string {
+	switch t {
+	case RSA:
+		return "RSA"
+	case EC:
+		return "EC"
+	}
+	return fmt.Sprintf("KeyType(%d)", int(t))
+}
+
+// curveForSize returns the NIST curve with coordinates of n bytes.
+func curveForSize(n int) (elliptic.Curve, ecdh.Curve, error) {
+	switch n {
+	case 32:
+		return elliptic.P256(), ecdh.P256(), nil
+	case 48:
+		return elliptic.P384(), ecdh.P384(), nil
+	case 66:
+		return elliptic.P521(), ecdh.P521(), nil
+	}
+	return nil, nil, fmt.Errorf("unsupported elliptic curve coordinate size %d", n)
+}
+
+// MarshalPublicKey returns the external representation of an RSA or ECDSA public key.
+func MarshalPublicKey(pub crypto.PublicKey) ([]byte, KeyType, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return x509.MarshalPKCS1PublicKey(k), RSA, nil
+	case *ecdsa.PublicKey:
+		e, err := k.ECDH()
+		if err != nil {
+			return nil, 0, err
+		}
+		return e.Bytes(), EC, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// PublicKeyType infers the type of a public key from its external
+// representation: X9.63 points start with 0x04, and PKCS#1 with a SEQUENCE.
+func PublicKeyType(data []byte) (KeyType, error) {
+	if len(data) > 0 {
+		switch data[0] {
+		case 0x04:
+			return EC, nil
+		case 0x30:
+			return RSA, nil
+		}
+	}
+	return 0, errors.New("unrecognised public key encoding")
+}
+
+// ParsePublicKey parses the external representation of a public key.
+//
+// Elliptic curve points are validated to be on the curve.
+func ParsePublicKey(keyType KeyType, data []byte) (crypto.PublicKey, error) {
+	switch keyType {
+	case RSA:
+		return x509.ParsePKCS1PublicKey(data)
+	case EC:
+		return ParseECPublicKey(data)
+	}
+	return nil, fmt.Errorf("unsupported key type %v", keyType)
+}
+
+// ParseECPublicKey parses an uncompressed X9.63 elliptic curve point.
+// The curve is inferred from the length of the point.
+func ParseECPublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	if len(data) == 0 || data[0] != 4 || len(data)%2 != 1 {
+		return nil, errors.New("public key is not an uncompressed X9.63 point")
+	}
+	size := (len(data) - 1) / 2
+
+	curve, ec, err := curveForSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	// crypto/ecdh rejects points which are not on the curve.
+	if _, err := ec.NewPublicKey(data); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(data[1 : 1+size]),
+		Y:     new(big.Int).SetBytes(data[1+size:]),
+	}, nil
+}
+
+// MarshalPrivateKey returns the external representation of an RSA or ECDSA private key.
+func MarshalPrivateKey(priv crypto.PrivateKey) ([]byte, KeyType, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return x509.MarshalPKCS1PrivateKey(k), RSA, nil
+	case *ecdsa.PrivateKey:
+		e, err := k.ECDH()
+		if err != nil {
+			return nil, 0, err
+		}
+		out := append([]byte{}, e.PublicKey().Bytes()...)
+		return append(out, e.Bytes()...), EC, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported private key type %T", priv)
+}
+
+// ParsePrivateKey parses the external representation of a private key.
+//
+// For elliptic curve keys the scalar is checked against the public point.
+func ParsePrivateKey(keyType KeyType, data []byte) (crypto.Signer, error) {
+	switch keyType {
+	case RSA:
+		return x509.ParsePKCS1PrivateKey(data)
+	case EC:
+		if len(data) == 0 || data[0] != 4 || len(data)%3 != 1 {
+			return nil, errors.New("private key is not an X9.63 point followed by a scalar")
+		}
+		size := (len(data) - 1) / 3
+
+		curve, ec, err := curveForSize(size)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := ec.NewPrivateKey(data[1+2*size:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		if !bytes.Equal(e.PublicKey().Bytes(), data[:1+2*size]) {
+			return nil, errors.New("private key does not match its public key")
+		}
+
+		return &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(data[1 : 1+size]),
+				Y:     new(big.Int).SetBytes(data[1+size : 1+2*size]),
+			},
+			D: new(big.Int).SetBytes(data[1+2*size:]),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %v", keyType)
+}
+
+// KeySizeInBits returns the value of kSecAttrKeySizeInBits for a public key.
+func KeySizeInBits(pub crypto.PublicKey) (int, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen(), nil
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize, nil
+	}
+	return 0, fmt.Errorf("unsupported public key type %T", pub)
+}
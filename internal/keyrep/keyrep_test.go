@@ -0,0 +1,100 @@
+package keyrep
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      crypto.Signer
+		wantType KeyType
+		wantLen  int
+	}{
+		{name: "p256", key: p256, wantType: EC, wantLen: 1 + 3*32},
+		{name: "p521", key: p521, wantType: EC, wantLen: 1 + 3*66},
+		{name: "rsa", key: rsaKey, wantType: RSA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, keyType, err := MarshalPrivateKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keyType != tt.wantType {
+				t.Errorf("got key type %v, want %v", keyType, tt.wantType)
+			}
+			if tt.wantLen != 0 && len(data) != tt.wantLen {
+				t.Errorf("got %d bytes, want %d", len(data), tt.wantLen)
+			}
+
+			priv, err := ParsePrivateKey(keyType, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(priv) {
+				t.Errorf("parsed private key does not match")
+			}
+
+			pubData, pubType, err := MarshalPublicKey(tt.key.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detected, err := PublicKeyType(pubData); err != nil || detected != pubType {
+				t.Errorf("PublicKeyType() = %v, %v, want %v", detected, err, pubType)
+			}
+			pub, err := ParsePublicKey(pubType, pubData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(pub) {
+				t.Errorf("parsed public key does not match")
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := MarshalPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offCurve := append([]byte{}, data[:65]...)
+	offCurve[64] ^= 1
+	if _, err := ParseECPublicKey(offCurve); err == nil {
+		t.Errorf("expected error for point not on the curve")
+	}
+
+	if _, err := ParseECPublicKey(data[:64]); err == nil {
+		t.Errorf("expected error for truncated point")
+	}
+
+	mismatched := append([]byte{}, data...)
+	mismatched[len(mismatched)-1] ^= 1
+	if _, err := ParsePrivateKey(EC, mismatched); err == nil {
+		t.Errorf("expected error for scalar which does not match the public key")
+	}
+}
@@ -0,0 +1,90 @@
+// Package retry calls a function until it succeeds, waiting with
+// exponential backoff between attempts which fail with retryable errors.
+//
+// It is pure Go so that the backoff is tested on every platform, while
+// applesecurity.RetryPolicy decides which keychain errors are retryable.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Defaults for the zero fields of a Policy.
+const (
+	DefaultAttempts = 5
+	DefaultDelay    = 100 * time.Millisecond
+	DefaultMaxDelay = 5 * time.Second
+)
+
+// Policy decides which errors are retried and how often.
+type Policy struct {
+	// MaxAttempts is the number of attempts, including the first.
+	MaxAttempts int
+
+	// Delay is the wait after the first failed attempt.
+	// It doubles after each subsequent attempt up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// Retryable reports whether an attempt failing with err is retried.
+	Retryable func(err error) bool
+
+	// Uncounted reports whether a retryable err does not count against
+	// MaxAttempts, so that it is retried until ctx is done. It may be nil.
+	Uncounted func(err error) bool
+}
+
+// Do calls fn until it succeeds, fails with an error which is not
+// retryable, the attempts are exhausted or ctx is done. It returns
+// the last result and error from fn, or the error of ctx if it was
+// done first.
+func Do[T any](ctx context.Context, p Policy, fn func() (T, error)) (T, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultAttempts
+	}
+	delay := p.Delay
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+
+	attempts := 0
+	for {
+		result, err := fn()
+		if err == nil || !p.Retryable(err) {
+			return result, err
+		}
+
+		if p.Uncounted == nil || !p.Uncounted(err) {
+			attempts++
+			if attempts >= maxAttempts {
+				return result, err
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
+
+		delay = Backoff(delay, maxDelay)
+	}
+}
+
+// Backoff returns the delay after delay: twice it, up to maxDelay.
+func Backoff(delay, maxDelay time.Duration) time.Duration {
+	delay *= 2
+	if delay > maxDelay || delay <= 0 {
+		return maxDelay
+	}
+	return delay
+}
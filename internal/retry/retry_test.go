@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errLocked    = errors.New("locked")
+	errPermanent = errors.New("permanent")
+)
+
+func retryable(err error) bool {
+	return errors.Is(err, errTransient) || errors.Is(err, errLocked)
+}
+
+func TestDo(t *testing.T) {
+	fast := Policy{MaxAttempts: 3, Delay: time.Millisecond, Retryable: retryable}
+	waitForUnlock := Policy{
+		MaxAttempts: 1,
+		Delay:       time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Retryable:   retryable,
+		Uncounted:   func(err error) bool { return errors.Is(err, errLocked) },
+	}
+
+	tests := []struct {
+		name         string
+		policy       Policy
+		failures     []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "first_attempt", policy: fast, wantAttempts: 1},
+		{name: "succeeds_after_transient", policy: fast, failures: []error{errTransient, errTransient}, wantAttempts: 3},
+		{name: "gives_up", policy: fast, failures: []error{errTransient, errTransient, errTransient, errTransient}, wantErr: errTransient, wantAttempts: 3},
+		{name: "not_retryable", policy: fast, failures: []error{errPermanent, errTransient}, wantErr: errPermanent, wantAttempts: 1},
+		{name: "permanent_after_transient", policy: fast, failures: []error{errTransient, errPermanent}, wantErr: errPermanent, wantAttempts: 2},
+		{name: "uncounted", policy: waitForUnlock, failures: []error{errLocked, errLocked, errLocked, errLocked, errLocked}, wantAttempts: 6},
+		{name: "counted_after_uncounted", policy: waitForUnlock, failures: []error{errLocked, errLocked, errTransient, errTransient}, wantErr: errTransient, wantAttempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			got, err := Do(context.Background(), tt.policy, func() (int, error) {
+				attempts++
+				if attempts <= len(tt.failures) {
+					return attempts, tt.failures[attempts-1]
+				}
+				return 42, nil
+			})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != 42 {
+				t.Errorf("Do() = %v, want 42", got)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestDo_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	got, err := Do(ctx, Policy{Delay: time.Hour, Retryable: retryable}, func() (string, error) {
+		attempts++
+		return "partial", errTransient
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if got != "" {
+		t.Errorf("Do() with a cancelled context = %q, want the zero value", got)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{name: "doubles", delay: 100 * time.Millisecond, maxDelay: time.Second, want: 200 * time.Millisecond},
+		{name: "capped", delay: 800 * time.Millisecond, maxDelay: time.Second, want: time.Second},
+		{name: "at_max", delay: time.Second, maxDelay: time.Second, want: time.Second},
+		{name: "overflow", delay: 1 << 62, maxDelay: time.Hour, want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Backoff(tt.delay, tt.maxDelay); got != tt.want {
+				t.Errorf("Backoff(%v, %v) = %v, want %v", tt.delay, tt.maxDelay, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package sealed implements the envelope of data sealed to a Secure
+// Enclave key, as stored by the keychain package.
+//
+// The data is encrypted with AES-256-GCM under a random data key, which
+// is encrypted to the enclave key with ECIES. An envelope is the magic
+// "SEAL", a version byte, the length and value of the ApplicationLabel
+// of the key, the length and value of the encrypted data key, and the
+// encrypted data:
+//
+//	"SEAL" || 0x01 || len(label) || label || len(key) || key || ciphertext
+//
+// where len(key) is a big-endian uint16. The additional data of the
+// ciphertext is everything before it, followed by the service and the
+// account of the keychain item the envelope is stored in, each preceded
+// by its length as a big-endian uint32. So neither the header nor the
+// data key can be changed, and an envelope cannot be moved to another
+// item, without opening it failing.
+//
+// It is pure Go so that data can be sealed on any platform.
+package sealed
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// Version is the version of envelopes written by Seal.
+const Version = 1
+
+const dataKeySize = 32
+
+var magic = []byte("SEAL")
+
+// ErrNotSealed is returned by Parse when data is not an envelope.
+var ErrNotSealed = errors.New("data is not sealed to an enclave key")
+
+// Envelope is a parsed envelope.
+type Envelope struct {
+	// ApplicationLabel is the ApplicationLabel
+	// of the key the envelope is sealed to.
+	ApplicationLabel []byte
+
+	header     []byte
+	dataKey    []byte
+	ciphertext []byte
+}
+
+// Seal encrypts data to pub, the public key of a Secure Enclave key,
+// for the keychain item with service and account.
+func Seal(rand io.Reader, pub *ecdsa.PublicKey, service, account string, data []byte) ([]byte, error) {
+	label, err := pubkey.ApplicationLabel(pub)
+	if err != nil {
+		return nil, err
+	}
+	if len(label) > 255 {
+		return nil, errors.New("key label is too long")
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand, dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err := ecies.Encrypt(rand, pub, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) > 0xffff {
+		return nil, errors.New("encrypted data key is too long")
+	}
+
+	header := make([]byte, 0, len(magic)+2+len(label)+2+len(wrapped))
+	header = append(header, magic...)
+	header = append(header, Version, byte(len(label)))
+	header = append(header, label...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce(aead), data, additionalData(header, service, account)), nil
+}
+
+// Parse parses an envelope, so that the key it
+// is sealed to can be found to open it with.
+func Parse(envelope []byte) (*Envelope, error) {
+	if !bytes.HasPrefix(envelope, magic) {
+		return nil, ErrNotSealed
+	}
+	rest := envelope[len(magic):]
+	if len(rest) < 2 {
+		return nil, errors.New("sealed data is truncated")
+	}
+	if rest[0] != Version {
+		return nil, fmt.Errorf("unsupported sealed data version %d", rest[0])
+	}
+	labelLen := int(rest[1])
+	rest = rest[2:]
+	if labelLen == 0 || len(rest) < labelLen {
+		return nil, errors.New("sealed data has an invalid key label")
+	}
+	label := rest[:labelLen]
+	rest = rest[labelLen:]
+
+	if len(rest) < 2 {
+		return nil, errors.New("sealed data is truncated")
+	}
+	keyLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if keyLen == 0 || len(rest) < keyLen {
+		return nil, errors.New("sealed data has an invalid data key")
+	}
+	dataKey := rest[:keyLen]
+	ciphertext := rest[keyLen:]
+
+	return &Envelope{
+		ApplicationLabel: label,
+		header:           envelope[:len(envelope)-len(ciphertext)],
+		dataKey:          dataKey,
+		ciphertext:       ciphertext,
+	}, nil
+}
+
+// Open decrypts the envelope with key, the key named by its
+// ApplicationLabel, for the keychain item with service and account.
+// It fails if the envelope was sealed for a different item or has
+// been modified.
+func (e *Envelope) Open(key crypto.Decrypter, service, account string) ([]byte, error) {
+	dataKey, err := key.Decrypt(nil, e.dataKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(dataKey) != dataKeySize {
+		return nil, errors.New("sealed data key has the wrong length")
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, nonce(aead), e.ciphertext, additionalData(e.header, service, account))
+	if err != nil {
+		return nil, errors.New("sealed data failed authentication")
+	}
+	return data, nil
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce returns the nonce of the ciphertext, which is zero
+// as every envelope is encrypted with a new data key.
+func nonce(aead cipher.AEAD) []byte {
+	return make([]byte, aead.NonceSize())
+}
+
+// additionalData returns the additional data of the ciphertext,
+// binding it to the header and to the item it is stored in.
+func additionalData(header []byte, service, account string) []byte {
+	ad := append([]byte(nil), header...)
+	ad = binary.BigEndian.AppendUint32(ad, uint32(len(service)))
+	ad = append(ad, service...)
+	ad = binary.BigEndian.AppendUint32(ad, uint32(len(account)))
+	return append(ad, account...)
+}
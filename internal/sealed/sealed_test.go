@@ -0,0 +1,179 @@
+package sealed
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/common-fate/go-apple-security/ecies"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+func newKey(t *testing.T) (*ecdsa.PublicKey, crypto.Decrypter) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := key.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &key.PublicKey, ecies.NewDecrypter(priv)
+}
+
+func TestSeal(t *testing.T) {
+	pub, key := newKey(t)
+	envelope, err := Seal(rand.Reader, pub, "service", "account", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Parse(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := pubkey.ApplicationLabel(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(e.ApplicationLabel, want) {
+		t.Errorf("Parse() label = %x, want %x", e.ApplicationLabel, want)
+	}
+
+	got, err := e.Open(key, "service", "account")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("Open() = %q, want %q", got, "secret")
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	pub, key := newKey(t)
+	envelope, err := Seal(rand.Reader, pub, "service", "account", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Parse(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := len(e.header)
+
+	tests := []struct {
+		name     string
+		envelope func(envelope []byte) []byte
+		service  string
+		account  string
+		key      crypto.Decrypter
+	}{
+		{
+			name:    "other_account",
+			service: "service",
+			account: "other",
+		},
+		{
+			name:    "other_service",
+			service: "other",
+			account: "account",
+		},
+		{
+			name:    "ambiguous_item",
+			service: "servicea",
+			account: "ccount",
+		},
+		{
+			name: "label_modified",
+			envelope: func(envelope []byte) []byte {
+				envelope[len(magic)+2] ^= 1
+				return envelope
+			},
+		},
+		{
+			name: "data_key_modified",
+			envelope: func(envelope []byte) []byte {
+				envelope[headerLen-1] ^= 1
+				return envelope
+			},
+		},
+		{
+			name: "ciphertext_modified",
+			envelope: func(envelope []byte) []byte {
+				envelope[headerLen] ^= 1
+				return envelope
+			},
+		},
+		{
+			name: "ciphertext_truncated",
+			envelope: func(envelope []byte) []byte {
+				return envelope[:len(envelope)-1]
+			},
+		},
+		{
+			name: "other_key",
+			key: func() crypto.Decrypter {
+				_, key := newKey(t)
+				return key
+			}(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := append([]byte(nil), envelope...)
+			if tt.envelope != nil {
+				modified = tt.envelope(modified)
+			}
+			service, account := tt.service, tt.account
+			if service == "" && account == "" {
+				service, account = "service", "account"
+			}
+			k := key
+			if tt.key != nil {
+				k = tt.key
+			}
+
+			e, err := Parse(modified)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := e.Open(k, service, account); err == nil {
+				t.Error("Open() succeeded")
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope []byte
+		wantErr  error
+	}{
+		{name: "plaintext", envelope: []byte("password"), wantErr: ErrNotSealed},
+		{name: "empty", envelope: nil, wantErr: ErrNotSealed},
+		{name: "truncated_header", envelope: []byte("SEAL\x01")},
+		{name: "unknown_version", envelope: []byte("SEAL\x02\x01a")},
+		{name: "empty_label", envelope: []byte("SEAL\x01\x00abc")},
+		{name: "truncated_label", envelope: []byte("SEAL\x01\x14abc")},
+		{name: "no_data_key", envelope: []byte("SEAL\x01\x01a")},
+		{name: "empty_data_key", envelope: []byte("SEAL\x01\x01a\x00\x00abc")},
+		{name: "truncated_data_key", envelope: []byte("SEAL\x01\x01a\x00\x71abc")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.envelope)
+			if err == nil {
+				t.Fatal("Parse() succeeded")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Package secerror converts the result codes and CFErrors
+// returned by the Security framework to Go errors.
+package secerror
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/localauth"
+)
+
+const nilCFError C.CFErrorRef = 0
+
+// osStatusDomain is the value of kCFErrorDomainOSStatus.
+const osStatusDomain = "NSOSStatusErrorDomain"
+
+// FromStatus converts an OSStatus result code to an error,
+// returning nil for errSecSuccess.
+func FromStatus(status int32) error {
+	return applesecurity.ErrorFromCode(int(status))
+}
+
+// FromCFError converts ref to an [applesecurity.CFError], returning nil
+// if ref is nil. It does not release ref, which the caller owns.
+func FromCFError(ref corefoundation.ErrorRef) error {
+	e := C.CFErrorRef(ref)
+	if e == nilCFError {
+		return nil
+	}
+
+	result := &applesecurity.CFError{
+		Domain:        corefoundation.CFStringToString(corefoundation.StringRef(C.CFErrorGetDomain(e))),
+		Code:          int(C.CFErrorGetCode(e)),
+		Description:   copyString(C.CFErrorCopyDescription(e)),
+		FailureReason: copyString(C.CFErrorCopyFailureReason(e)),
+	}
+
+	switch result.Domain {
+	case osStatusDomain:
+		result.Err = applesecurity.ErrorFromCode(result.Code)
+	case localauth.Domain:
+		// LocalAuthentication codes overlap with OSStatus codes,
+		// so they must not be converted to applesecurity.Error.
+		result.Err, _ = localauth.FromCFError(result.Domain, result.Code)
+	}
+
+	userInfo := C.CFErrorCopyUserInfo(e)
+	if userInfo != 0 {
+		defer C.CFRelease(C.CFTypeRef(userInfo))
+
+		underlying := C.CFTypeRef(C.CFDictionaryGetValue(userInfo, unsafe.Pointer(C.kCFErrorUnderlyingErrorKey)))
+		if underlying != 0 && C.CFGetTypeID(underlying) == C.CFErrorGetTypeID() {
+			result.Underlying = FromCFError(corefoundation.ErrorRef(underlying))
+		}
+	}
+
+	return result
+}
+
+// copyString converts and releases a string returned by a Copy function.
+func copyString(s C.CFStringRef) string {
+	if s == 0 {
+		return ""
+	}
+	defer C.CFRelease(C.CFTypeRef(s))
+	return corefoundation.CFStringToString(corefoundation.StringRef(s))
+}
@@ -0,0 +1,59 @@
+// Package sigopts validates the crypto.SignerOpts passed to the ECDSA
+// signers of the Secure Enclave, which support the X9.62 algorithms
+// with SHA-256, SHA-384 and SHA-512.
+//
+// It is pure Go so that the validation is tested on every platform.
+package sigopts
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// ErrMessageOpts is returned when the options of a digest signature
+// have no hash function, which means the message is not hashed.
+var ErrMessageOpts = errors.New("signer options have no hash function: sign the message with SignMessage")
+
+// Supported reports whether hash is one of the hash functions
+// supported by the Secure Enclave.
+func Supported(hash crypto.Hash) bool {
+	switch hash {
+	case crypto.SHA256, crypto.SHA384, crypto.SHA512:
+		return true
+	}
+	return false
+}
+
+// Digest returns the hash function which produced digest, according to
+// opts. Nil options mean SHA-256, the only hash function supported
+// before options were honoured.
+func Digest(digest []byte, opts crypto.SignerOpts) (crypto.Hash, error) {
+	hash := crypto.SHA256
+	if opts != nil {
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			return 0, errors.New("RSA PSS options cannot be used with an ECDSA key")
+		}
+		hash = opts.HashFunc()
+	}
+
+	if hash == 0 {
+		return 0, ErrMessageOpts
+	}
+	if !Supported(hash) {
+		return 0, fmt.Errorf("unsupported hash function %v: use SHA-256, SHA-384 or SHA-512", hash)
+	}
+	if len(digest) != hash.Size() {
+		return 0, fmt.Errorf("digest length %d does not match hash function %v, which has length %d", len(digest), hash, hash.Size())
+	}
+	return hash, nil
+}
+
+// Message returns an error if messages cannot be hashed with hash.
+func Message(hash crypto.Hash) error {
+	if !Supported(hash) {
+		return fmt.Errorf("unsupported hash function %v: use SHA-256, SHA-384 or SHA-512", hash)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package sigopts
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		opts    crypto.SignerOpts
+		want    crypto.Hash
+		wantErr bool
+	}{
+		{name: "nil_sha256", length: 32, opts: nil, want: crypto.SHA256},
+		{name: "nil_wrong_length", length: 48, opts: nil, wantErr: true},
+		{name: "sha256", length: 32, opts: crypto.SHA256, want: crypto.SHA256},
+		{name: "sha384", length: 48, opts: crypto.SHA384, want: crypto.SHA384},
+		{name: "sha512", length: 64, opts: crypto.SHA512, want: crypto.SHA512},
+		{name: "length_mismatch", length: 32, opts: crypto.SHA384, wantErr: true},
+		{name: "empty_digest", length: 0, opts: crypto.SHA256, wantErr: true},
+		{name: "sha1", length: 20, opts: crypto.SHA1, wantErr: true},
+		{name: "sha3", length: 32, opts: crypto.SHA3_256, wantErr: true},
+		{name: "message", length: 32, opts: crypto.Hash(0), wantErr: true},
+		{name: "pss", length: 32, opts: &rsa.PSSOptions{Hash: crypto.SHA256}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Digest(make([]byte, tt.length), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Digest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Digest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Digest(make([]byte, 32), crypto.Hash(0)); !errors.Is(err, ErrMessageOpts) {
+		t.Errorf("Digest() without a hash function error = %v, want ErrMessageOpts", err)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		hash    crypto.Hash
+		wantErr bool
+	}{
+		{hash: crypto.SHA256},
+		{hash: crypto.SHA384},
+		{hash: crypto.SHA512},
+		{hash: crypto.SHA1, wantErr: true},
+		{hash: crypto.SHA224, wantErr: true},
+		{hash: crypto.Hash(0), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hash.String(), func(t *testing.T) {
+			if err := Message(tt.hash); (err != nil) != tt.wantErr {
+				t.Errorf("Message() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
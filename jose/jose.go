@@ -0,0 +1,105 @@
+// Package jose signs and verifies JSON Web Signatures (RFC 7515) and
+// JSON Web Tokens (RFC 7519) with a crypto.Signer, such as an
+// enclavekey.Key or a keychainkey.Key.
+//
+// JWS requires ECDSA signatures in the fixed-width r || s encoding of
+// IEEE P1363 over a digest of the signing input, whereas Key.Sign
+// returns ASN.1 DER. Signer hashes the signing input with the hash
+// function of the algorithm and converts the signature, unless
+// SignerOptions.P1363 says the key already returns IEEE P1363.
+//
+// The package is pure Go, so tokens can be created and verified with
+// software keys on any platform.
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// Algorithms of JWS, as registered by RFC 7518 section 3.1.
+const (
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	RS256 = "RS256"
+)
+
+// ErrInvalidSignature is returned when a signature does not verify.
+var ErrInvalidSignature = errors.New("invalid JWS signature")
+
+var b64 = base64.RawURLEncoding
+
+// Header is the JOSE header of a JWS.
+type Header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+	// JWK is the public key of the signer, if it is embedded.
+	JWK *pubkey.JWK `json:"jwk,omitempty"`
+	// X5C is the certificate chain of the signer, if it is embedded,
+	// as base64 encoded DER certificates beginning with the signer's.
+	X5C []string `json:"x5c,omitempty"`
+	// Critical lists extensions which must be understood. No
+	// extensions are supported, so signatures with it are rejected.
+	Critical []string `json:"crit,omitempty"`
+}
+
+// Certificates parses the embedded certificate chain. It does
+// not verify the chain: use x509.Certificate.Verify to do so.
+func (h *Header) Certificates() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, c := range h.X5C {
+		der, err := base64.StdEncoding.DecodeString(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x5c certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// Algorithm returns the JWS algorithm for a key of pub: the ECDSA
+// algorithm for its curve, or RS256 for RSA.
+func Algorithm(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return ES256, nil
+		case elliptic.P384():
+			return ES384, nil
+		case elliptic.P521():
+			return ES512, nil
+		}
+		return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return RS256, nil
+	}
+	return "", fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// hashFor returns the hash function of alg.
+func hashFor(alg string) (crypto.Hash, error) {
+	switch alg {
+	case ES256, RS256:
+		return crypto.SHA256, nil
+	case ES384:
+		return crypto.SHA384, nil
+	case ES512:
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unsupported JWS algorithm %q", alg)
+}
@@ -0,0 +1,135 @@
+package jose
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxAssertionLifetime bounds the lifetime of client assertions, which
+// authorization servers only accept for a short time.
+const MaxAssertionLifetime = time.Hour
+
+// Claims are the registered claims of a JWT, defined by RFC 7519
+// section 4.1. Times are seconds since the Unix epoch.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// Audience is the aud claim, which is either a string or an array of
+// strings. It is marshalled as a string if it has one element.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// Contains reports whether aud is one of the audiences.
+func (a Audience) Contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the claims are valid at now, allowing leeway for
+// clock skew, and that audience, if it is not empty, is an audience of
+// the token. A token without an exp claim is rejected.
+func (c *Claims) Validate(now time.Time, audience string, leeway time.Duration) error {
+	if c.ExpiresAt == 0 {
+		return errors.New("JWT has no expiry")
+	}
+	if now.Add(-leeway).After(time.Unix(c.ExpiresAt, 0)) {
+		return errors.New("JWT has expired")
+	}
+	if c.NotBefore != 0 && now.Add(leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return errors.New("JWT is not valid yet")
+	}
+	if audience != "" && !c.Audience.Contains(audience) {
+		return fmt.Errorf("JWT is not intended for %s", audience)
+	}
+	return nil
+}
+
+// SignJWT returns a JWT of claims, which may be Claims or any type
+// marshalling to a JSON object, such as a struct embedding Claims.
+func (s *Signer) SignJWT(claims interface{}) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	h := s.header
+	if h.Type == "" {
+		h.Type = "JWT"
+	}
+	return s.signCompact(h, payload)
+}
+
+// VerifyJWT verifies the signature of a JWT with pub, as VerifyCompact
+// does, and unmarshals its claims into claims. It does not validate the
+// claims: call Claims.Validate to do so.
+func VerifyJWT(token string, pub crypto.PublicKey, claims interface{}) (*Header, error) {
+	h, payload, err := VerifyCompact(token, pub)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("parsing JWT claims: %w", err)
+	}
+	return h, nil
+}
+
+// ClientAssertion returns a JWT for OAuth 2.0 client authentication
+// with the private_key_jwt method of RFC 7523 section 2.2: the issuer
+// and subject are clientID, the audience is the token endpoint of the
+// authorization server, and it has a random ID and expires after
+// lifetime, which must not exceed MaxAssertionLifetime.
+func (s *Signer) ClientAssertion(clientID, tokenEndpoint string, lifetime time.Duration) (string, error) {
+	return s.clientAssertion(time.Now(), clientID, tokenEndpoint, lifetime)
+}
+
+func (s *Signer) clientAssertion(now time.Time, clientID, tokenEndpoint string, lifetime time.Duration) (string, error) {
+	if clientID == "" || tokenEndpoint == "" {
+		return "", errors.New("a client ID and token endpoint are required")
+	}
+	if lifetime <= 0 || lifetime > MaxAssertionLifetime {
+		return "", fmt.Errorf("lifetime must be between 0 and %s", MaxAssertionLifetime)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return s.SignJWT(Claims{
+		Issuer:    clientID,
+		Subject:   clientID,
+		Audience:  Audience{tokenEndpoint},
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(lifetime).Unix(),
+		ID:        hex.EncodeToString(id),
+	})
+}
@@ -0,0 +1,157 @@
+package jose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAudience(t *testing.T) {
+	tests := []struct {
+		aud  Audience
+		json string
+	}{
+		{aud: Audience{"https://example.com/token"}, json: `"https://example.com/token"`},
+		{aud: Audience{"a", "b"}, json: `["a","b"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			got, err := json.Marshal(tt.aud)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.json {
+				t.Errorf("Marshal() = %s, want %s", got, tt.json)
+			}
+			var aud Audience
+			if err := json.Unmarshal([]byte(tt.json), &aud); err != nil {
+				t.Fatal(err)
+			}
+			if len(aud) != len(tt.aud) || !aud.Contains(tt.aud[0]) {
+				t.Errorf("Unmarshal() = %v, want %v", aud, tt.aud)
+			}
+		})
+	}
+}
+
+func TestClaims_Validate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	valid := Claims{Audience: Audience{"api"}, ExpiresAt: now.Unix() + 60, NotBefore: now.Unix() - 60}
+
+	tests := []struct {
+		name     string
+		modify   func(c *Claims)
+		audience string
+		wantErr  bool
+	}{
+		{name: "valid", modify: func(c *Claims) {}, audience: "api"},
+		{name: "any audience", modify: func(c *Claims) {}},
+		{name: "wrong audience", modify: func(c *Claims) {}, audience: "other", wantErr: true},
+		{name: "no expiry", modify: func(c *Claims) { c.ExpiresAt = 0 }, wantErr: true},
+		{name: "expired", modify: func(c *Claims) { c.ExpiresAt = now.Unix() - 31 }, wantErr: true},
+		{name: "expired within leeway", modify: func(c *Claims) { c.ExpiresAt = now.Unix() - 29 }},
+		{name: "not yet valid", modify: func(c *Claims) { c.NotBefore = now.Unix() + 31 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate(now, tt.audience, 30*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type customClaims struct {
+		Claims
+		Scope string `json:"scope"`
+	}
+	token, err := s.SignJWT(customClaims{Claims: Claims{Subject: "alice"}, Scope: "read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got customClaims
+	h, err := VerifyJWT(token, &key.PublicKey, &got)
+	if err != nil {
+		t.Fatalf("VerifyJWT() error = %v", err)
+	}
+	if h.Type != "JWT" {
+		t.Errorf("typ = %q, want JWT", h.Type)
+	}
+	if got.Subject != "alice" || got.Scope != "read" {
+		t.Errorf("claims = %+v", got)
+	}
+	if s.Header().Type != "" {
+		t.Errorf("SignJWT() changed the header of the signer")
+	}
+}
+
+func TestClientAssertion(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSigner(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const endpoint = "https://auth.example.com/oauth2/token"
+	now := time.Unix(1700000000, 0)
+
+	token, err := s.clientAssertion(now, "cli", endpoint, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims Claims
+	h, err := VerifyJWT(token, &key.PublicKey, &claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Algorithm != ES256 || h.KeyID != s.Header().KeyID {
+		t.Errorf("header = %+v", h)
+	}
+	if claims.Issuer != "cli" || claims.Subject != "cli" || claims.IssuedAt != now.Unix() ||
+		claims.ExpiresAt != now.Add(5*time.Minute).Unix() || len(claims.ID) != 32 {
+		t.Errorf("claims = %+v", claims)
+	}
+	if err := claims.Validate(now, endpoint, 0); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	another, err := s.clientAssertion(now, "cli", endpoint, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var anotherClaims Claims
+	if _, err := VerifyJWT(another, &key.PublicKey, &anotherClaims); err != nil {
+		t.Fatal(err)
+	}
+	if anotherClaims.ID == claims.ID {
+		t.Error("assertions have the same jti")
+	}
+
+	for _, lifetime := range []time.Duration{0, MaxAssertionLifetime + time.Second} {
+		if _, err := s.ClientAssertion("cli", endpoint, lifetime); err == nil {
+			t.Errorf("ClientAssertion() with lifetime %s succeeded", lifetime)
+		}
+	}
+	if _, err := s.ClientAssertion("", endpoint, time.Minute); err == nil {
+		t.Error("ClientAssertion() without a client ID succeeded")
+	}
+}
@@ -0,0 +1,176 @@
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/ecdsasig"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// SignerOptions configure the header of signatures.
+type SignerOptions struct {
+	// KeyID is the kid header. It defaults to the RFC 7638
+	// JWK thumbprint of the public key of the signer.
+	KeyID string
+	// Type is the typ header, such as "JWT". SignJWT sets it to "JWT"
+	// if it is empty.
+	Type string
+	// EmbedJWK adds the public key of the signer as the jwk header.
+	EmbedJWK bool
+	// Certificates are added as the x5c header. The first must
+	// be the certificate of the public key of the signer.
+	Certificates []*x509.Certificate
+	// P1363 reports that the signer returns ECDSA signatures in the
+	// IEEE P1363 encoding used by JWS, such as an enclavekey.Key with
+	// SignatureP1363, rather than the ASN.1 DER of crypto/ecdsa.
+	P1363 bool
+}
+
+// Signer signs JWS with a crypto.Signer.
+type Signer struct {
+	key    crypto.Signer
+	header Header
+	p1363  bool
+}
+
+// NewSigner returns a signer for key, using the algorithm for
+// its public key returned by Algorithm. opts may be nil.
+func NewSigner(key crypto.Signer, opts *SignerOptions) (*Signer, error) {
+	if opts == nil {
+		opts = &SignerOptions{}
+	}
+	pub := key.Public()
+
+	alg, err := Algorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := pubkey.NewJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	h := Header{Algorithm: alg, KeyID: opts.KeyID, Type: opts.Type}
+	if h.KeyID == "" {
+		h.KeyID = jwk.Kid
+	}
+	if opts.EmbedJWK {
+		h.JWK = jwk
+	}
+	for i, cert := range opts.Certificates {
+		if i == 0 {
+			certPub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !certPub.Equal(pub) {
+				return nil, errors.New("the first certificate does not match the public key of the signer")
+			}
+		}
+		h.X5C = append(h.X5C, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+
+	return &Signer{key: key, header: h, p1363: opts.P1363}, nil
+}
+
+// Header returns the protected header of the signatures.
+func (s *Signer) Header() Header {
+	return s.header
+}
+
+// SignCompact returns the JWS Compact Serialization of payload.
+func (s *Signer) SignCompact(payload []byte) (string, error) {
+	return s.signCompact(s.header, payload)
+}
+
+func (s *Signer) signCompact(h Header, payload []byte) (string, error) {
+	protected, sig, err := s.sign(h, b64.EncodeToString(payload))
+	if err != nil {
+		return "", err
+	}
+	return protected + "." + b64.EncodeToString(payload) + "." + sig, nil
+}
+
+// jsonSignature is a signature in the JWS JSON Serialization.
+type jsonSignature struct {
+	Protected string `json:"protected"`
+	Signature string `json:"signature"`
+}
+
+// jsonJWS is the general, or with one signature
+// the flattened, JWS JSON Serialization.
+type jsonJWS struct {
+	Payload    string          `json:"payload"`
+	Signatures []jsonSignature `json:"signatures,omitempty"`
+	jsonSignature
+}
+
+// SignJSON returns the general JWS JSON Serialization of payload,
+// signed by each of the signers, so that it can be verified with
+// the public key of any of them.
+func SignJSON(payload []byte, signers ...*Signer) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("at least one signer is required")
+	}
+	jws := struct {
+		Payload    string          `json:"payload"`
+		Signatures []jsonSignature `json:"signatures"`
+	}{Payload: b64.EncodeToString(payload)}
+
+	for _, s := range signers {
+		protected, sig, err := s.sign(s.header, jws.Payload)
+		if err != nil {
+			return nil, err
+		}
+		jws.Signatures = append(jws.Signatures, jsonSignature{Protected: protected, Signature: sig})
+	}
+	return json.Marshal(jws)
+}
+
+// sign returns the encoded protected header and signature over it and
+// the encoded payload.
+func (s *Signer) sign(h Header, payload string) (string, string, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", "", err
+	}
+	protected := b64.EncodeToString(data)
+
+	hash, err := hashFor(h.Algorithm)
+	if err != nil {
+		return "", "", err
+	}
+	digest := hash.New()
+	digest.Write([]byte(protected + "." + payload))
+
+	sig, err := s.key.Sign(rand.Reader, digest.Sum(nil), hash)
+	if err != nil {
+		return "", "", err
+	}
+	if pub, ok := s.key.Public().(*ecdsa.PublicKey); ok {
+		if sig, err = s.toP1363(sig, pub); err != nil {
+			return "", "", err
+		}
+	}
+	return protected, b64.EncodeToString(sig), nil
+}
+
+// toP1363 returns an ECDSA signature in the IEEE P1363 encoding of JWS,
+// converting it from ASN.1 DER unless SignerOptions.P1363 was set.
+func (s *Signer) toP1363(sig []byte, pub *ecdsa.PublicKey) ([]byte, error) {
+	if !s.p1363 {
+		p1363, err := ecdsasig.ToP1363(sig, pub.Curve, nil)
+		if err != nil {
+			return nil, fmt.Errorf("signer returned an invalid ASN.1 DER signature, set SignerOptions.P1363 for IEEE P1363 signers: %w", err)
+		}
+		return p1363, nil
+	}
+	if _, _, err := ecdsasig.ParseP1363(sig, pub.Curve); err != nil {
+		return nil, fmt.Errorf("signer returned an invalid IEEE P1363 signature: %w", err)
+	}
+	return sig, nil
+}
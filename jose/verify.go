@@ -0,0 +1,109 @@
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/common-fate/go-apple-security/ecdsasig"
+)
+
+// VerifyCompact verifies a JWS in the Compact Serialization with pub,
+// returning its header and payload. The alg header must be the
+// algorithm returned by Algorithm for pub, so that a token cannot
+// choose a weaker algorithm, or "none".
+func VerifyCompact(token string, pub crypto.PublicKey) (*Header, []byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("compact JWS must have three parts")
+	}
+	h, err := verify(parts[0], parts[1], parts[2], pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JWS payload: %w", err)
+	}
+	return h, payload, nil
+}
+
+// VerifyJSON verifies a JWS in the general or flattened JSON
+// Serialization, returning the header of the first signature
+// which verifies with pub, and the payload.
+func VerifyJSON(data []byte, pub crypto.PublicKey) (*Header, []byte, error) {
+	var jws jsonJWS
+	if err := json.Unmarshal(data, &jws); err != nil {
+		return nil, nil, fmt.Errorf("parsing JWS: %w", err)
+	}
+	signatures := jws.Signatures
+	if len(signatures) == 0 {
+		signatures = []jsonSignature{jws.jsonSignature}
+	}
+
+	payload, err := b64.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid JWS payload: %w", err)
+	}
+
+	err = ErrInvalidSignature
+	for _, sig := range signatures {
+		var h *Header
+		if h, err = verify(sig.Protected, jws.Payload, sig.Signature, pub); err == nil {
+			return h, payload, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// verify verifies the signature over the encoded protected header and
+// payload, returning the header.
+func verify(protected, payload, signature string, pub crypto.PublicKey) (*Header, error) {
+	data, err := b64.DecodeString(protected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS header: %w", err)
+	}
+	var h Header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parsing JWS header: %w", err)
+	}
+	if len(h.Critical) > 0 {
+		return nil, fmt.Errorf("unsupported critical JWS extensions %v", h.Critical)
+	}
+
+	alg, err := Algorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+	if h.Algorithm != alg {
+		return nil, fmt.Errorf("JWS algorithm %q does not match the %s key", h.Algorithm, alg)
+	}
+	hash, err := hashFor(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := b64.DecodeString(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWS signature encoding: %w", err)
+	}
+	digest := hash.New()
+	digest.Write([]byte(protected + "." + payload))
+
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		r, s, err := ecdsasig.ParseP1363(sig, k.Curve)
+		if err != nil || !ecdsa.Verify(k, digest.Sum(nil), r, s) {
+			return nil, ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, hash, digest.Sum(nil), sig); err != nil {
+			return nil, ErrInvalidSignature
+		}
+	}
+	return &h, nil
+}
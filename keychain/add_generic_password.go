@@ -10,14 +10,33 @@ import "C"
 import (
 	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 // AddGenericPassword adds a generic password to the keychain.
 //
+// The item is added to the access group in input.Scope, or the
+// default access group of the application if it is empty.
+//
 // Returns [ErrDuplicateItem] if the item already exists
 // for the provided account and service.
 func AddGenericPassword(input GenericPassword) error {
-	valueData, err := corefoundation.NewCFData(input.Data)
+	if err := addGenericPassword(input); err != nil {
+		return opError("AddGenericPassword", input.Scope, input.Service, input.Account, err)
+	}
+	return nil
+}
+
+func addGenericPassword(input GenericPassword) error {
+	data := input.Data
+	if input.SealTo != nil {
+		var err error
+		if data, err = seal(input.SealTo, input.Service, input.Account, data); err != nil {
+			return err
+		}
+	}
+
+	valueData, err := corefoundation.NewCFData(data)
 	if err != nil {
 		return err
 	}
@@ -35,13 +54,20 @@ func AddGenericPassword(input GenericPassword) error {
 	}
 	defer C.CFRelease(C.CFTypeRef(cfService))
 
-	attrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):                     corefoundation.TypeRef(C.kSecClassGenericPassword),
-		corefoundation.TypeRef(C.kSecUseDataProtectionKeychain): corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecValueData):                 corefoundation.TypeRef(valueData),
-		corefoundation.TypeRef(C.kSecAttrAccount):               corefoundation.TypeRef(cfAccount),
-		corefoundation.TypeRef(C.kSecAttrService):               corefoundation.TypeRef(cfService),
-	})
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):       corefoundation.TypeRef(C.kSecClassGenericPassword),
+		corefoundation.TypeRef(C.kSecValueData):   corefoundation.TypeRef(valueData),
+		corefoundation.TypeRef(C.kSecAttrAccount): corefoundation.TypeRef(cfAccount),
+		corefoundation.TypeRef(C.kSecAttrService): corefoundation.TypeRef(cfService),
+	}
+
+	release, err := itemattr.AddScope(m, input.Scope, false)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	attrs, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
 		return err
 	}
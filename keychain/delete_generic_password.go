@@ -8,36 +8,66 @@ package keychain
 */
 import "C"
 import (
+	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 type DeleteGenericPasswordsInput struct {
+	applesecurity.Scope
+
 	Account string
 	Service string
+
+	// PersistentRef selects exactly one item. If it is
+	// set, Account, Service and Scope are ignored.
+	PersistentRef applesecurity.PersistentRef
 }
 
 // DeleteGenericPasswords deletes matching items from the keychain.
 func DeleteGenericPasswords(input DeleteGenericPasswordsInput) (int, error) {
-	cfService, err := corefoundation.NewCFString(input.Service)
+	deleted, err := deleteGenericPasswords(input)
 	if err != nil {
-		return 0, err
+		return deleted, opError("DeleteGenericPasswords", input.Scope, input.Service, input.Account, err)
 	}
-	defer C.CFRelease(C.CFTypeRef(cfService))
+	return deleted, nil
+}
 
+func deleteGenericPasswords(input DeleteGenericPasswordsInput) (int, error) {
 	m := corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):                     corefoundation.TypeRef(C.kSecClassGenericPassword),
-		corefoundation.TypeRef(C.kSecUseDataProtectionKeychain): corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecAttrService):               corefoundation.TypeRef(cfService),
+		corefoundation.TypeRef(C.kSecClass): corefoundation.TypeRef(C.kSecClassGenericPassword),
 	}
 
-	if input.Account != "" {
-		cfAccount, err := corefoundation.NewCFString(input.Account)
+	if !input.PersistentRef.IsZero() {
+		release, err := itemattr.AddPersistentRef(m, input.PersistentRef, input.Keychain)
+		if err != nil {
+			return 0, err
+		}
+		defer release()
+	} else {
+		cfService, err := corefoundation.NewCFString(input.Service)
 		if err != nil {
 			return 0, err
 		}
-		defer C.CFRelease(C.CFTypeRef(cfAccount))
+		defer C.CFRelease(C.CFTypeRef(cfService))
 
-		m[corefoundation.TypeRef(C.kSecAttrAccount)] = corefoundation.TypeRef(cfAccount)
+		m[corefoundation.TypeRef(C.kSecAttrService)] = corefoundation.TypeRef(cfService)
+
+		if input.Account != "" {
+			cfAccount, err := corefoundation.NewCFString(input.Account)
+			if err != nil {
+				return 0, err
+			}
+			defer C.CFRelease(C.CFTypeRef(cfAccount))
+
+			m[corefoundation.TypeRef(C.kSecAttrAccount)] = corefoundation.TypeRef(cfAccount)
+		}
+
+		release, err := itemattr.AddScope(m, input.Scope, true)
+		if err != nil {
+			return 0, err
+		}
+		defer release()
 	}
 
 	query, err := corefoundation.NewCFDictionary(m)
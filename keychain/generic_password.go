@@ -8,11 +8,34 @@ package keychain
 */
 import "C"
 
+import (
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/enclavekey"
+)
+
 // GenericPassword is a generic password item.
 //
+// Items read from the keychain have their access group and
+// synchronizable attributes set in Scope, and their PersistentRef set.
+//
 // See: https://developer.apple.com/documentation/security/ksecclassgenericpassword
 type GenericPassword struct {
+	applesecurity.Scope
+
 	Account string
 	Service string
 	Data    []byte
+
+	// PersistentRef refers to exactly this item. When it is set,
+	// UpdateGenericPassword finds the item by it rather than by
+	// Account, Service and Scope.
+	PersistentRef applesecurity.PersistentRef
+
+	// SealTo, when set, makes AddGenericPassword and
+	// UpdateGenericPassword store Data encrypted to this Secure Enclave
+	// key, so that it can only be read on this device with the key,
+	// subject to its user presence policy. Read it with
+	// GetGenericPasswordInput.Unseal. The sealed data is bound to
+	// Service and Account, so it cannot be unsealed from another item.
+	SealTo *enclavekey.Key
 }
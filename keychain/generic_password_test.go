@@ -2,6 +2,9 @@ package keychain
 
 import (
 	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	applesecurity "github.com/common-fate/go-apple-security"
@@ -40,3 +43,229 @@ func TestGenericPassword_AddRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericPassword_Scope(t *testing.T) {
+	const service = "com.example.goapplesecurity.test.scope"
+
+	_, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{
+		Service: service,
+		Scope:   applesecurity.Scope{Synchronizable: applesecurity.SynchronizableAny},
+	})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatal(err)
+	}
+
+	local := GenericPassword{Account: "local", Service: service, Data: []byte("local")}
+	if err := AddGenericPassword(local); err != nil {
+		t.Fatal(err)
+	}
+
+	// the test binaries are signed with a shared access group,
+	// named after the team ID which prefixes the default group.
+	got, err := GetGenericPassword(GetGenericPasswordInput{Account: local.Account, Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	teamID, _, _ := strings.Cut(got.AccessGroup, ".")
+	shared := applesecurity.Scope{
+		AccessGroup:    teamID + ".goapplesecurity.test.shared",
+		Synchronizable: applesecurity.SynchronizableYes,
+	}
+
+	synced := GenericPassword{Scope: shared, Account: "synced", Service: service, Data: []byte("synced")}
+	if err := AddGenericPassword(synced); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input ListGenericPasswordsInput
+		want  []string
+	}{
+		{
+			name:  "default_excludes_synchronizable",
+			input: ListGenericPasswordsInput{Service: service},
+			want:  []string{"local"},
+		},
+		{
+			name:  "any",
+			input: ListGenericPasswordsInput{Service: service, Scope: applesecurity.Scope{Synchronizable: applesecurity.SynchronizableAny}},
+			want:  []string{"local", "synced"},
+		},
+		{
+			name:  "within_group",
+			input: ListGenericPasswordsInput{Service: service, Scope: applesecurity.Scope{AccessGroup: shared.AccessGroup, Synchronizable: applesecurity.SynchronizableAny}},
+			want:  []string{"synced"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListGenericPasswords(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var accounts []string
+			for _, p := range got {
+				accounts = append(accounts, p.Account)
+			}
+			sort.Strings(accounts)
+			if !reflect.DeepEqual(accounts, tt.want) {
+				t.Errorf("ListGenericPasswords() accounts = %v, want %v", accounts, tt.want)
+			}
+		})
+	}
+
+	got, err = GetGenericPassword(GetGenericPasswordInput{Scope: shared, Account: synced.Account, Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Scope != shared {
+		t.Errorf("got Scope = %+v, want %+v", got.Scope, shared)
+	}
+
+	if err := AddGenericPassword(GenericPassword{Scope: applesecurity.Scope{Synchronizable: applesecurity.SynchronizableAny}, Account: "invalid", Service: service}); err == nil {
+		t.Errorf("expected error adding an item with SynchronizableAny")
+	}
+}
+
+func TestGenericPassword_LegacyKeychain(t *testing.T) {
+	const service = "com.example.goapplesecurity.test.legacy"
+	legacy := applesecurity.Scope{Keychain: applesecurity.LegacyKeychain}
+
+	_, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{Scope: legacy, Service: service})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatal(err)
+	}
+
+	if err := AddGenericPassword(GenericPassword{Scope: legacy, Account: "legacy", Service: service, Data: []byte("legacy")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the item is only visible in the keychain it was added to.
+	_, err = GetGenericPassword(GetGenericPasswordInput{Account: "legacy", Service: service})
+	if !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Errorf("GetGenericPassword() from the data protection keychain error = %v, want ErrItemNotFound", err)
+	}
+
+	got, err := GetGenericPassword(GetGenericPasswordInput{Scope: legacy, Account: "legacy", Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Data) != "legacy" {
+		t.Errorf("got data %q, want %q", got.Data, "legacy")
+	}
+	if got.Keychain != applesecurity.LegacyKeychain {
+		t.Errorf("got Keychain = %v, want %v", got.Keychain, applesecurity.LegacyKeychain)
+	}
+
+	got.Data = []byte("updated")
+	if err := UpdateGenericPassword(*got); err != nil {
+		t.Fatalf("UpdateGenericPassword() by reference error = %v", err)
+	}
+
+	deleted, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{PersistentRef: got.PersistentRef, Scope: legacy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 item deleted but got %v", deleted)
+	}
+
+	err = AddGenericPassword(GenericPassword{Scope: applesecurity.Scope{Keychain: applesecurity.LegacyKeychain, AccessGroup: "TEAMID.shared"}, Account: "invalid", Service: service})
+	if err == nil {
+		t.Errorf("expected error adding an item with an access group to the legacy keychain")
+	}
+}
+
+func TestGenericPassword_PersistentRef(t *testing.T) {
+	const service = "com.example.goapplesecurity.test.persistentref"
+
+	_, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{Service: service})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatal(err)
+	}
+
+	for _, account := range []string{"first", "second"} {
+		err := AddGenericPassword(GenericPassword{Account: account, Service: service, Data: []byte(account)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := GetGenericPassword(GetGenericPasswordInput{Account: "first", Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip the reference through its text encoding,
+	// as it would be when stored in a configuration file.
+	text, err := first.PersistentRef.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ref applesecurity.PersistentRef
+	if err := ref.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(first.PersistentRef) {
+		t.Fatalf("persistent reference changed after text round trip")
+	}
+
+	got, err := GetGenericPassword(GetGenericPasswordInput{PersistentRef: ref})
+	if err != nil {
+		t.Fatalf("GetGenericPassword() by reference error = %v", err)
+	}
+	if got.Account != "first" || string(got.Data) != "first" {
+		t.Errorf("got account %q with data %q, want the first item", got.Account, got.Data)
+	}
+
+	got.Account = "renamed"
+	got.Data = []byte("updated")
+	if err := UpdateGenericPassword(*got); err != nil {
+		t.Fatalf("UpdateGenericPassword() by reference error = %v", err)
+	}
+
+	updated, err := GetGenericPassword(GetGenericPasswordInput{Account: "renamed", Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(updated.Data) != "updated" {
+		t.Errorf("got data %q, want %q", updated.Data, "updated")
+	}
+
+	deleted, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{PersistentRef: updated.PersistentRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 item deleted but got %v", deleted)
+	}
+
+	remaining, err := ListGenericPasswords(ListGenericPasswordsInput{Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].Account != "second" {
+		t.Errorf("wanted only the second item to remain, got %v", remaining)
+	}
+}
+
+// checkReadAttributes checks that an item read from the keychain has a
+// persistent reference, is in the default access group and is not
+// synchronised. It then clears those attributes so the item can be
+// compared with the one which was added.
+func checkReadAttributes(t *testing.T, p *GenericPassword) {
+	t.Helper()
+
+	if p.PersistentRef.IsZero() {
+		t.Errorf("got empty PersistentRef")
+	}
+	if p.AccessGroup == "" {
+		t.Errorf("got empty AccessGroup")
+	}
+	if p.Synchronizable != applesecurity.SynchronizableNo {
+		t.Errorf("got Synchronizable = %v, want %v", p.Synchronizable, applesecurity.SynchronizableNo)
+	}
+	p.Scope = applesecurity.Scope{}
+	p.PersistentRef = applesecurity.PersistentRef{}
+}
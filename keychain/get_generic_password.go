@@ -8,7 +8,10 @@ package keychain
 */
 import "C"
 import (
+	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/enclavekey"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 const (
@@ -16,32 +19,73 @@ const (
 )
 
 type GetGenericPasswordInput struct {
+	applesecurity.Scope
+
 	Account string
 	Service string
+
+	// PersistentRef selects exactly one item. If it is
+	// set, Account, Service and Scope are ignored.
+	PersistentRef applesecurity.PersistentRef
+
+	// Unseal decrypts Data stored with GenericPassword.SealTo, using
+	// the enclave key named by the data. Reading fails with
+	// ErrNotSealed if the data is not sealed, so a sealed item
+	// cannot be replaced with plaintext unnoticed.
+	Unseal bool
+
+	// LAContext is the authentication context used to unseal
+	// Data if the enclave key requires user presence.
+	LAContext *enclavekey.LAContext
 }
 
 func GetGenericPassword(input GetGenericPasswordInput) (*GenericPassword, error) {
-	cfAccount, err := corefoundation.NewCFString(input.Account)
+	p, err := getGenericPassword(input)
 	if err != nil {
-		return nil, err
+		return nil, opError("GetGenericPassword", input.Scope, input.Service, input.Account, err)
 	}
-	defer C.CFRelease(C.CFTypeRef(cfAccount))
+	return p, nil
+}
 
-	cfService, err := corefoundation.NewCFString(input.Service)
-	if err != nil {
-		return nil, err
+func getGenericPassword(input GetGenericPasswordInput) (*GenericPassword, error) {
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):               corefoundation.TypeRef(C.kSecClassGenericPassword),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnData):          corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit):          corefoundation.TypeRef(C.kSecMatchLimitOne),
+	}
+
+	if !input.PersistentRef.IsZero() {
+		release, err := itemattr.AddPersistentRef(m, input.PersistentRef, input.Keychain)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	} else {
+		cfAccount, err := corefoundation.NewCFString(input.Account)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfAccount))
+
+		cfService, err := corefoundation.NewCFString(input.Service)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfService))
+
+		m[corefoundation.TypeRef(C.kSecAttrAccount)] = corefoundation.TypeRef(cfAccount)
+		m[corefoundation.TypeRef(C.kSecAttrService)] = corefoundation.TypeRef(cfService)
+
+		release, err := itemattr.AddScope(m, input.Scope, true)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
 	}
-	defer C.CFRelease(C.CFTypeRef(cfService))
-
-	query, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):                     corefoundation.TypeRef(C.kSecClassGenericPassword),
-		corefoundation.TypeRef(C.kSecUseDataProtectionKeychain): corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecAttrAccount):               corefoundation.TypeRef(cfAccount),
-		corefoundation.TypeRef(C.kSecAttrService):               corefoundation.TypeRef(cfService),
-		corefoundation.TypeRef(C.kSecReturnAttributes):          corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecReturnData):                corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecMatchLimit):                corefoundation.TypeRef(C.kSecMatchLimitOne),
-	})
+
+	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
 		return nil, err
 	}
@@ -54,5 +98,16 @@ func GetGenericPassword(input GetGenericPasswordInput) (*GenericPassword, error)
 	}
 	defer C.CFRelease(itemRef)
 
-	return extractGenericPassword(C.CFDictionaryRef(itemRef))
+	p, err := extractGenericPassword(C.CFDictionaryRef(itemRef), input.Keychain)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Unseal {
+		if p.Data, err = unseal(p.Data, p.Service, p.Account, input.LAContext); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
 }
@@ -1,11 +1,13 @@
 package keychain
 
 import (
+	"bytes"
 	"errors"
 	"reflect"
 	"testing"
 
 	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/enclavekey"
 )
 
 func TestGetGenericPassword(t *testing.T) {
@@ -59,9 +61,104 @@ func TestGetGenericPassword(t *testing.T) {
 				t.Errorf("GetGenericPassword() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			checkReadAttributes(t, got)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetGenericPassword() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestGetGenericPassword_OpError(t *testing.T) {
+	input := GetGenericPasswordInput{
+		Service: "com.example.goapplesecurity.test.operror",
+		Account: "missing",
+	}
+	_, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{Service: input.Service, Account: input.Account})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatal(err)
+	}
+
+	_, err = GetGenericPassword(input)
+	if !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("GetGenericPassword() error = %v, want ErrItemNotFound", err)
+	}
+
+	var opErr *applesecurity.OpError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("GetGenericPassword() error %T is not an OpError", err)
+	}
+	want := applesecurity.OpError{
+		Op:      "GetGenericPassword",
+		Service: input.Service,
+		Account: input.Account,
+		Err:     applesecurity.ErrItemNotFound,
+	}
+	if *opErr != want {
+		t.Errorf("got %+v, want %+v", *opErr, want)
+	}
+	if opErr.Code() != applesecurity.ErrItemNotFound {
+		t.Errorf("got Code() = %d, want %d", opErr.Code(), applesecurity.ErrItemNotFound)
+	}
+}
+
+func TestGetGenericPassword_Sealed(t *testing.T) {
+	const (
+		service = "com.example.goapplesecurity.test.sealed"
+		tag     = "com.example.goapplesecurity.test.sealed"
+	)
+
+	_, err := enclavekey.Delete(enclavekey.DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatal(err)
+	}
+	key, err := enclavekey.Create(enclavekey.CreateInput{Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, account := range []string{"sealed", "plain", "moved"} {
+		_, err := DeleteGenericPasswords(DeleteGenericPasswordsInput{Service: service, Account: account})
+		if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+			t.Fatal(err)
+		}
+	}
+
+	secret := []byte("hello")
+	if err := AddGenericPassword(GenericPassword{Service: service, Account: "sealed", Data: secret, SealTo: key}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddGenericPassword(GenericPassword{Service: service, Account: "plain", Data: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	// without Unseal, only the ciphertext is read.
+	raw, err := GetGenericPassword(GetGenericPasswordInput{Service: service, Account: "sealed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw.Data, secret) {
+		t.Errorf("sealed item stores the secret in plaintext")
+	}
+
+	got, err := GetGenericPassword(GetGenericPasswordInput{Service: service, Account: "sealed", Unseal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, secret) {
+		t.Errorf("got Data = %q, want %q", got.Data, secret)
+	}
+
+	_, err = GetGenericPassword(GetGenericPasswordInput{Service: service, Account: "plain", Unseal: true})
+	if !errors.Is(err, ErrNotSealed) {
+		t.Errorf("GetGenericPassword() of a plaintext item error = %v, want ErrNotSealed", err)
+	}
+
+	// the envelope is bound to its item, so it cannot be copied to another.
+	if err := AddGenericPassword(GenericPassword{Service: service, Account: "moved", Data: raw.Data}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetGenericPassword(GetGenericPasswordInput{Service: service, Account: "moved", Unseal: true}); err == nil {
+		t.Error("GetGenericPassword() unsealed data copied from another item")
+	}
+}
@@ -14,27 +14,46 @@ import (
 
 	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 type ListGenericPasswordsInput struct {
+	applesecurity.Scope
+
 	Service string
 }
 
 func ListGenericPasswords(input ListGenericPasswordsInput) ([]GenericPassword, error) {
+	items, err := listGenericPasswords(input)
+	if err != nil {
+		return nil, opError("ListGenericPasswords", input.Scope, input.Service, "", err)
+	}
+	return items, nil
+}
+
+func listGenericPasswords(input ListGenericPasswordsInput) ([]GenericPassword, error) {
 	cfService, err := corefoundation.NewCFString(input.Service)
 	if err != nil {
 		return nil, err
 	}
 	defer C.CFRelease(C.CFTypeRef(cfService))
 
-	query, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):                     corefoundation.TypeRef(C.kSecClassGenericPassword),
-		corefoundation.TypeRef(C.kSecUseDataProtectionKeychain): corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecAttrService):               corefoundation.TypeRef(cfService),
-		corefoundation.TypeRef(C.kSecReturnAttributes):          corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecReturnData):                corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecMatchLimit):                corefoundation.TypeRef(C.kSecMatchLimitAll),
-	})
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):               corefoundation.TypeRef(C.kSecClassGenericPassword),
+		corefoundation.TypeRef(C.kSecAttrService):         corefoundation.TypeRef(cfService),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnData):          corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit):          corefoundation.TypeRef(C.kSecMatchLimitAll),
+	}
+
+	release, err := itemattr.AddScope(m, input.Scope, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +80,7 @@ func ListGenericPasswords(input ListGenericPasswordsInput) ([]GenericPassword, e
 			return nil, fmt.Errorf("Invalid result type within array: %s", cfTypeDescription(C.CFTypeRef(ref)))
 		}
 
-		key, err := extractGenericPassword(C.CFDictionaryRef(ref))
+		key, err := extractGenericPassword(C.CFDictionaryRef(ref), input.Keychain)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +90,7 @@ func ListGenericPasswords(input ListGenericPasswordsInput) ([]GenericPassword, e
 	return results, nil
 }
 
-func extractGenericPassword(ref C.CFDictionaryRef) (*GenericPassword, error) {
+func extractGenericPassword(ref C.CFDictionaryRef, keychain applesecurity.Keychain) (*GenericPassword, error) {
 	val := C.CFDataRef(C.CFDictionaryGetValue(ref, unsafe.Pointer(C.kSecValueData)))
 	if val == nilCFData {
 		return nil, fmt.Errorf("cannot extract data")
@@ -82,8 +101,10 @@ func extractGenericPassword(ref C.CFDictionaryRef) (*GenericPassword, error) {
 			unsafe.Pointer(C.CFDataGetBytePtr(val)),
 			C.int(C.CFDataGetLength(val)),
 		),
-		Account: corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(ref), corefoundation.StringRef(C.kSecAttrAccount)),
-		Service: corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(ref), corefoundation.StringRef(C.kSecAttrService)),
+		Account:       corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(ref), corefoundation.StringRef(C.kSecAttrAccount)),
+		Service:       corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(ref), corefoundation.StringRef(C.kSecAttrService)),
+		Scope:         itemattr.Scope(corefoundation.DictionaryRef(ref), keychain),
+		PersistentRef: itemattr.PersistentRef(corefoundation.DictionaryRef(ref)),
 	}
 
 	return &p, nil
@@ -73,6 +73,9 @@ func TestListGenericPasswords(t *testing.T) {
 				t.Errorf("ListGenericPasswords() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			for i := range got {
+				checkReadAttributes(t, &got[i])
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ListGenericPasswords() = %v, want %v", got, tt.want)
 			}
@@ -0,0 +1,39 @@
+package keychain
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/common-fate/go-apple-security/enclavekey"
+	"github.com/common-fate/go-apple-security/internal/sealed"
+)
+
+// ErrNotSealed is returned by GetGenericPassword with Unseal set
+// when the data of the item is not sealed to an enclave key.
+var ErrNotSealed = sealed.ErrNotSealed
+
+// seal returns data encrypted to key for the item with service and
+// account, in an envelope naming the ApplicationLabel of the key.
+func seal(key *enclavekey.Key, service, account string, data []byte) ([]byte, error) {
+	if key.PublicKey == nil {
+		return nil, errors.New("enclave key to seal data to has no public key")
+	}
+	return sealed.Seal(rand.Reader, key.PublicKey, service, account, data)
+}
+
+// unseal decrypts an envelope read from the item with service and
+// account with the enclave key it names, which prompts the user if
+// the key requires user presence. It fails if the envelope was
+// sealed for another item.
+func unseal(envelope []byte, service, account string, laContext *enclavekey.LAContext) ([]byte, error) {
+	e, err := sealed.Parse(envelope)
+	if err != nil {
+		return nil, err
+	}
+	key, err := enclavekey.Get(enclavekey.GetInput{ApplicationLabel: e.ApplicationLabel})
+	if err != nil {
+		return nil, err
+	}
+	key.LAContext = laContext
+	return e.Open(key, service, account)
+}
@@ -10,11 +10,33 @@ import "C"
 import (
 	applesecurity "github.com/common-fate/go-apple-security"
 	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
 )
 
 // UpdateGenericPassword updates a generic password in the keychain.
+//
+// The item is found by input.PersistentRef if it is set, which allows
+// its account and service to be changed. Otherwise it is found by its
+// account and service within input.Scope, which may use
+// [applesecurity.SynchronizableAny]. The access group and
+// synchronizable attributes of the item are not changed.
 func UpdateGenericPassword(input GenericPassword) error {
-	valueData, err := corefoundation.NewCFData(input.Data)
+	if err := updateGenericPassword(input); err != nil {
+		return opError("UpdateGenericPassword", input.Scope, input.Service, input.Account, err)
+	}
+	return nil
+}
+
+func updateGenericPassword(input GenericPassword) error {
+	data := input.Data
+	if input.SealTo != nil {
+		var err error
+		if data, err = seal(input.SealTo, input.Service, input.Account, data); err != nil {
+			return err
+		}
+	}
+
+	valueData, err := corefoundation.NewCFData(data)
 	if err != nil {
 		return err
 	}
@@ -32,13 +54,29 @@ func UpdateGenericPassword(input GenericPassword) error {
 	}
 	defer C.CFRelease(C.CFTypeRef(cfService))
 
-	query, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
-		corefoundation.TypeRef(C.kSecClass):                     corefoundation.TypeRef(C.kSecClassGenericPassword),
-		corefoundation.TypeRef(C.kSecUseDataProtectionKeychain): corefoundation.TypeRef(C.kCFBooleanTrue),
-		corefoundation.TypeRef(C.kSecValueData):                 corefoundation.TypeRef(valueData),
-		corefoundation.TypeRef(C.kSecAttrAccount):               corefoundation.TypeRef(cfAccount),
-		corefoundation.TypeRef(C.kSecAttrService):               corefoundation.TypeRef(cfService),
-	})
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass): corefoundation.TypeRef(C.kSecClassGenericPassword),
+	}
+
+	if !input.PersistentRef.IsZero() {
+		release, err := itemattr.AddPersistentRef(m, input.PersistentRef, input.Keychain)
+		if err != nil {
+			return err
+		}
+		defer release()
+	} else {
+		m[corefoundation.TypeRef(C.kSecValueData)] = corefoundation.TypeRef(valueData)
+		m[corefoundation.TypeRef(C.kSecAttrAccount)] = corefoundation.TypeRef(cfAccount)
+		m[corefoundation.TypeRef(C.kSecAttrService)] = corefoundation.TypeRef(cfService)
+
+		release, err := itemattr.AddScope(m, input.Scope, true)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	query, err := corefoundation.NewCFDictionary(m)
 	if err != nil {
 		return err
 	}
@@ -43,6 +43,7 @@ func TestUpdateGenericPassword(t *testing.T) {
 		t.Errorf("GetGenericPassword() error = %v, wantErr %v", err, false)
 		return
 	}
+	checkReadAttributes(t, got)
 	if !reflect.DeepEqual(got, &pw) {
 		t.Errorf("GetGenericPassword() = %v, want %v", got, pw)
 	}
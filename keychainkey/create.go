@@ -0,0 +1,160 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+type CreateInput struct {
+	// Scope selects the access group of the key and whether it is
+	// synchronised with iCloud Keychain. SynchronizableAny is not valid.
+	applesecurity.Scope
+
+	Type KeyType
+
+	// Bits is the size of the key. RSA keys may be between 2048 and
+	// 4096 bits and default to 2048. EC keys may be 256, 384 or 521
+	// bits, selecting the P-256, P-384 or P-521 curve, and default to 256.
+	Bits int
+
+	// Tag data is constructed from a string, using reverse DNS notation, though any unique tag will do.
+	//
+	// For example: 'com.example.keys.mykey'.
+	//
+	// See: https://developer.apple.com/documentation/security/certificate_key_and_trust_services/keys/generating_new_cryptographic_keys#2863927
+	Tag string
+
+	Label string
+}
+
+// Create generates a new key and stores it in the keychain.
+func Create(input CreateInput) (*Key, error) {
+	bits, err := keySize(input.Type, input.Bits)
+	if err != nil {
+		return nil, err
+	}
+
+	keyType, err := secKeyType(input.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	cfBits, err := corefoundation.NewCFNumber(bits)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfBits))
+
+	privKeyAttrs := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrIsPermanent): corefoundation.TypeRef(C.kCFBooleanTrue),
+	}
+
+	if input.Tag != "" {
+		cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfTag))
+
+		privKeyAttrs[corefoundation.TypeRef(C.kSecAttrApplicationTag)] = corefoundation.TypeRef(cfTag)
+	}
+
+	cfPrivKeyAttrs, err := corefoundation.NewCFDictionary(privKeyAttrs)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfPrivKeyAttrs))
+
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrKeyType):       keyType,
+		corefoundation.TypeRef(C.kSecAttrKeySizeInBits): corefoundation.TypeRef(cfBits),
+		corefoundation.TypeRef(C.kSecPrivateKeyAttrs):   corefoundation.TypeRef(cfPrivKeyAttrs),
+	}
+
+	release, err := itemattr.AddScope(m, input.Scope, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if input.Label != "" {
+		cfLabel, err := corefoundation.NewCFString(input.Label)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	attrs, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	var eref C.CFErrorRef
+	privKey := C.SecKeyCreateRandomKey(C.CFDictionaryRef(attrs), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	if privKey == nilSecKey {
+		return nil, fmt.Errorf("error generating random private key")
+	}
+	defer C.CFRelease(C.CFTypeRef(privKey))
+
+	pub, err := extractPublicKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyAttrs := C.SecKeyCopyAttributes(privKey)
+	defer C.CFRelease(C.CFTypeRef(keyAttrs))
+
+	key := Key{
+		Scope:            itemattr.Scope(corefoundation.DictionaryRef(keyAttrs), input.Keychain),
+		ApplicationLabel: corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(keyAttrs), corefoundation.DataRef(C.kSecAttrApplicationLabel)),
+		PublicKey:        pub,
+		Tag:              input.Tag,
+		Label:            input.Label,
+	}
+
+	return &key, nil
+}
+
+// keySize validates the requested key size, applying the default if unset.
+func keySize(t KeyType, bits int) (int, error) {
+	switch t {
+	case RSA:
+		if bits == 0 {
+			return 2048, nil
+		}
+		if bits < 2048 || bits > 4096 || bits%8 != 0 {
+			return 0, fmt.Errorf("unsupported RSA key size %d: must be between 2048 and 4096 bits", bits)
+		}
+		return bits, nil
+
+	case EC:
+		switch bits {
+		case 0:
+			return 256, nil
+		case 256, 384, 521:
+			return bits, nil
+		}
+		return 0, fmt.Errorf("unsupported EC key size %d: must be 256, 384 or 521 bits", bits)
+	}
+
+	return 0, fmt.Errorf("unsupported key type %v", t)
+}
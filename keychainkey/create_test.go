@@ -0,0 +1,165 @@
+package keychainkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+)
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   CreateInput
+		wantErr bool
+	}{
+		{
+			name:  "rsa_default",
+			input: CreateInput{Type: RSA, Tag: "com.example.goapplesecurity.test.keychainkey.rsa"},
+		},
+		{
+			name:  "rsa_3072",
+			input: CreateInput{Type: RSA, Bits: 3072, Tag: "com.example.goapplesecurity.test.keychainkey.rsa3072"},
+		},
+		{
+			name:  "ec_default",
+			input: CreateInput{Type: EC, Tag: "com.example.goapplesecurity.test.keychainkey.ec", Label: "test label"},
+		},
+		{
+			name:  "ec_p521",
+			input: CreateInput{Type: EC, Bits: 521, Tag: "com.example.goapplesecurity.test.keychainkey.ec521"},
+		},
+		{
+			name:    "rsa_too_small",
+			input:   CreateInput{Type: RSA, Bits: 1024, Tag: "com.example.goapplesecurity.test.keychainkey.rsa1024"},
+			wantErr: true,
+		},
+		{
+			name:    "ec_unsupported_curve",
+			input:   CreateInput{Type: EC, Bits: 224, Tag: "com.example.goapplesecurity.test.keychainkey.ec224"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// delete any existing keys
+			_, err := Delete(DeleteInput{Tag: tt.input.Tag})
+			if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+				t.Fatalf("error deleting existing keys: %v", err)
+			}
+
+			key, err := Create(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := Get(GetInput{Tag: tt.input.Tag, Label: tt.input.Label})
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !bytes.Equal(got.ApplicationLabel, key.ApplicationLabel) {
+				t.Errorf("got ApplicationLabel = %x, want = %x", got.ApplicationLabel, key.ApplicationLabel)
+			}
+			if !got.PublicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.PublicKey) {
+				t.Errorf("retrieved public key was not equal to public key from Create()")
+			}
+
+			digest := sha256.Sum256([]byte("hello"))
+			sig, err := got.Sign(rand.Reader, digest[:], crypto.SHA256)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			switch pub := got.PublicKey.(type) {
+			case *rsa.PublicKey:
+				err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig)
+			case *ecdsa.PublicKey:
+				if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+					err = errors.New("ecdsa verification failed")
+				}
+			}
+			if err != nil {
+				t.Errorf("invalid signature: %v", err)
+			}
+
+			deleted, err := Delete(DeleteInput{ApplicationLabel: key.ApplicationLabel})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Errorf("wanted 1 key deleted but got %v", deleted)
+			}
+		})
+	}
+}
+
+func TestKey_Sign_NoSelector(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a persistent reference or application
+	// label, the key would match any key in the keychain.
+	key := &Key{PublicKey: &priv.PublicKey}
+
+	digest := sha256.Sum256([]byte("hello"))
+	if _, err := key.Sign(rand.Reader, digest[:], crypto.SHA256); err == nil {
+		t.Error("Sign() of a key without a persistent reference or application label succeeded")
+	}
+}
+
+func TestCreate_Synchronizable(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.keychainkey.synchronizable"
+	anySync := applesecurity.Scope{Synchronizable: applesecurity.SynchronizableAny}
+
+	_, err := Delete(DeleteInput{Scope: anySync, Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	key, err := Create(CreateInput{
+		Scope: applesecurity.Scope{Synchronizable: applesecurity.SynchronizableYes},
+		Type:  EC,
+		Tag:   tag,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Get(GetInput{Tag: tag}); !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Errorf("Get() without SynchronizableAny error = %v, want %v", err, applesecurity.ErrItemNotFound)
+	}
+
+	got, err := Get(GetInput{Scope: anySync, Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Synchronizable != applesecurity.SynchronizableYes {
+		t.Errorf("got Synchronizable = %v, want %v", got.Synchronizable, applesecurity.SynchronizableYes)
+	}
+	if got.AccessGroup == "" {
+		t.Errorf("got empty AccessGroup")
+	}
+
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := got.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if !ecdsa.VerifyASN1(key.PublicKey.(*ecdsa.PublicKey), digest[:], sig) {
+		t.Errorf("invalid signature")
+	}
+
+	if _, err := Delete(DeleteInput{Scope: anySync, Tag: tag}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,95 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+// Decrypt decrypts ciphertext with an RSA private key.
+//
+// opts may be nil or an *rsa.PKCS1v15DecryptOptions for PKCS #1 v1.5
+// padding, or an *rsa.OAEPOptions for OAEP. The Security framework does
+// not support OAEP labels, nor an MGF1 hash different from the OAEP hash.
+func (k *Key) Decrypt(_ io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if _, ok := k.PublicKey.(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("decryption is not supported for %v keys", k.Type())
+	}
+
+	algorithm, err := decryptionAlgorithm(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	cfCiphertext, err := corefoundation.NewCFData(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfCiphertext))
+
+	var eref C.CFErrorRef
+	plaintext := C.SecKeyCreateDecryptedData(key, algorithm, C.CFDataRef(cfCiphertext), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(plaintext))
+
+	return corefoundation.CFDataToBytes(corefoundation.DataRef(plaintext)), nil
+}
+
+// decryptionAlgorithm returns the SecKeyAlgorithm for RSA decryption.
+func decryptionAlgorithm(opts crypto.DecrypterOpts) (C.SecKeyAlgorithm, error) {
+	var algorithm C.SecKeyAlgorithm
+
+	switch o := opts.(type) {
+	case nil:
+		return C.kSecKeyAlgorithmRSAEncryptionPKCS1, nil
+
+	case *rsa.PKCS1v15DecryptOptions:
+		if o.SessionKeyLen != 0 {
+			return algorithm, fmt.Errorf("session key decryption is not supported")
+		}
+		return C.kSecKeyAlgorithmRSAEncryptionPKCS1, nil
+
+	case *rsa.OAEPOptions:
+		if len(o.Label) != 0 {
+			return algorithm, fmt.Errorf("OAEP labels are not supported")
+		}
+		if o.MGFHash != 0 && o.MGFHash != o.Hash {
+			return algorithm, fmt.Errorf("MGF1 hash %v must match OAEP hash %v", o.MGFHash, o.Hash)
+		}
+		switch o.Hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmRSAEncryptionOAEPSHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmRSAEncryptionOAEPSHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmRSAEncryptionOAEPSHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmRSAEncryptionOAEPSHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmRSAEncryptionOAEPSHA512, nil
+		}
+		return algorithm, fmt.Errorf("unsupported OAEP hash function %v", o.Hash)
+	}
+
+	return algorithm, fmt.Errorf("unsupported decrypter options %T", opts)
+}
@@ -0,0 +1,71 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+)
+
+type DeleteInput struct {
+	applesecurity.Scope
+
+	Tag              string
+	Label            string
+	ApplicationLabel []byte
+
+	// PersistentRef selects exactly one key. If it is
+	// set, the other criteria are ignored.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// Delete keys in the keychain matching the criteria in DeleteInput.
+//
+// Multiple keys will be deleted if they all match the criteria.
+// At least one criterion is required.
+//
+// Returns a count of deleted keys. Returns [applesecurity.ErrItemNotFound]
+// if no keys were found matching the criteria.
+func Delete(input DeleteInput) (int, error) {
+	m := match{
+		Scope:            input.Scope,
+		Tag:              input.Tag,
+		Label:            input.Label,
+		ApplicationLabel: input.ApplicationLabel,
+		PersistentRef:    input.PersistentRef,
+	}
+	if m.Tag == "" && m.Label == "" && m.ApplicationLabel == nil && m.PersistentRef.IsZero() {
+		return 0, errors.New("a tag, label, application label or persistent reference is required to delete keys")
+	}
+
+	// count the keys first, SecItemDelete removes every match at once.
+	keys, err := m.find()
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, applesecurity.ErrItemNotFound
+	}
+
+	var deleted int
+	for _, key := range keys {
+		query, release, err := key.match().query(nil)
+		if err != nil {
+			return deleted, err
+		}
+		status := C.SecItemDelete(query)
+		release()
+		if err := goError(status); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,8 @@
+// Package keychainkey contains methods to work with RSA and
+// elliptic curve keys stored in the keychain in software.
+//
+// Unlike keys in the enclavekey package, these keys can be imported
+// and exported, and are not limited to P-256. The private key still
+// never needs to leave the keychain: keys implement crypto.Signer and
+// crypto.Decrypter by calling into the Security framework.
+package keychainkey
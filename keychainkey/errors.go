@@ -0,0 +1,26 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/secerror"
+)
+
+func goError(e interface{}) error {
+	switch v := e.(type) {
+	case C.OSStatus:
+		return secerror.FromStatus(int32(v))
+	case C.CFErrorRef:
+		return secerror.FromCFError(corefoundation.ErrorRef(v))
+	}
+	return fmt.Errorf("unknown error type %T", e)
+}
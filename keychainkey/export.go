@@ -0,0 +1,43 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+)
+
+// ExportPrivateKey copies the private key out of the keychain,
+// returning an *rsa.PrivateKey or an *ecdsa.PrivateKey.
+//
+// Fails if the key is not extractable.
+func (k *Key) ExportPrivateKey() (crypto.Signer, error) {
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	var eref C.CFErrorRef
+	keyData := C.SecKeyCopyExternalRepresentation(key, &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(keyData))
+
+	keyType := keyrep.EC
+	if k.Type() == RSA {
+		keyType = keyrep.RSA
+	}
+
+	return keyrep.ParsePrivateKey(keyType, corefoundation.CFDataToBytes(corefoundation.DataRef(keyData)))
+}
@@ -0,0 +1,35 @@
+package keychainkey
+
+import applesecurity "github.com/common-fate/go-apple-security"
+
+type GetInput struct {
+	applesecurity.Scope
+
+	Tag              string
+	Label            string
+	ApplicationLabel []byte
+
+	// PersistentRef selects exactly one key. If it is
+	// set, the other criteria are ignored.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// Get returns the first key matching the criteria in GetInput.
+//
+// Returns [applesecurity.ErrItemNotFound] if no keys match.
+func Get(input GetInput) (*Key, error) {
+	keys, err := match{
+		Scope:            input.Scope,
+		Tag:              input.Tag,
+		Label:            input.Label,
+		ApplicationLabel: input.ApplicationLabel,
+		PersistentRef:    input.PersistentRef,
+	}.find()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, applesecurity.ErrItemNotFound
+	}
+	return &keys[0], nil
+}
@@ -0,0 +1,144 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+)
+
+type ImportInput struct {
+	// Scope selects the access group of the key and whether it is
+	// synchronised with iCloud Keychain. SynchronizableAny is not valid.
+	applesecurity.Scope
+
+	// PrivateKey is an *rsa.PrivateKey, or an *ecdsa.PrivateKey
+	// on the P-256, P-384 or P-521 curve.
+	PrivateKey crypto.PrivateKey
+
+	Tag   string
+	Label string
+}
+
+// Import adds an existing private key to the keychain.
+//
+// Returns [applesecurity.ErrDuplicateItem] if the key is already in the keychain.
+func Import(input ImportInput) (*Key, error) {
+	keyData, keyType, err := keyrep.MarshalPrivateKey(input.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	t := RSA
+	if keyType == keyrep.EC {
+		t = EC
+	}
+
+	pub := input.PrivateKey.(crypto.Signer).Public()
+	bits, err := keyrep.KeySizeInBits(pub)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := keySize(t, bits); err != nil {
+		return nil, err
+	}
+
+	secType, err := secKeyType(t)
+	if err != nil {
+		return nil, err
+	}
+
+	cfKeyData, err := corefoundation.NewCFData(keyData)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfKeyData))
+
+	keyAttrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrKeyType):  secType,
+		corefoundation.TypeRef(C.kSecAttrKeyClass): corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(keyAttrs))
+
+	var eref C.CFErrorRef
+	secKey := C.SecKeyCreateWithData(C.CFDataRef(cfKeyData), C.CFDictionaryRef(keyAttrs), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	if secKey == nilSecKey {
+		return nil, fmt.Errorf("error creating private key")
+	}
+	defer C.CFRelease(C.CFTypeRef(secKey))
+
+	m := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):               corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecValueRef):            corefoundation.TypeRef(secKey),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
+	}
+
+	if input.Tag != "" {
+		cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfTag))
+
+		m[corefoundation.TypeRef(C.kSecAttrApplicationTag)] = corefoundation.TypeRef(cfTag)
+	}
+
+	if input.Label != "" {
+		cfLabel, err := corefoundation.NewCFString(input.Label)
+		if err != nil {
+			return nil, err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	release, err := itemattr.AddScope(m, input.Scope, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	attrs, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	var result C.CFTypeRef
+	status := C.SecItemAdd(C.CFDictionaryRef(attrs), &result)
+	if err := goError(status); err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(result)
+
+	key := Key{
+		Scope:            itemattr.Scope(corefoundation.DictionaryRef(result), input.Keychain),
+		PersistentRef:    itemattr.PersistentRef(corefoundation.DictionaryRef(result)),
+		ApplicationLabel: corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(result), corefoundation.DataRef(C.kSecAttrApplicationLabel)),
+		PublicKey:        pub,
+		Tag:              input.Tag,
+		Label:            input.Label,
+	}
+
+	return &key, nil
+}
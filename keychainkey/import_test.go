@@ -0,0 +1,138 @@
+package keychainkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+)
+
+func TestImportExport(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tag  string
+		key  crypto.Signer
+	}{
+		{name: "ec", tag: "com.example.goapplesecurity.test.keychainkey.import.ec", key: ecKey},
+		{name: "rsa", tag: "com.example.goapplesecurity.test.keychainkey.import.rsa", key: rsaKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// delete any existing keys
+			_, err := Delete(DeleteInput{Tag: tt.tag})
+			if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+				t.Fatalf("error deleting existing keys: %v", err)
+			}
+
+			imported, err := Import(ImportInput{PrivateKey: tt.key, Tag: tt.tag})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			_, err = Import(ImportInput{PrivateKey: tt.key, Tag: tt.tag})
+			if !errors.Is(err, applesecurity.ErrDuplicateItem) {
+				t.Errorf("second Import() error = %v, want %v", err, applesecurity.ErrDuplicateItem)
+			}
+
+			keys, err := List(ListInput{Tag: tt.tag})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != 1 {
+				t.Fatalf("wanted 1 key but got %v", len(keys))
+			}
+
+			exported, err := keys[0].ExportPrivateKey()
+			if err != nil {
+				t.Fatalf("ExportPrivateKey() error = %v", err)
+			}
+			if !tt.key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(exported) {
+				t.Errorf("exported private key does not match imported private key")
+			}
+
+			if _, err := Delete(DeleteInput{ApplicationLabel: imported.ApplicationLabel}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestKey_Decrypt(t *testing.T) {
+	tag := "com.example.goapplesecurity.test.keychainkey.decrypt"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	key, err := Create(CreateInput{Type: RSA, Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := key.PublicKey.(*rsa.PublicKey)
+	msg := []byte("hello")
+
+	tests := []struct {
+		name    string
+		encrypt func() ([]byte, error)
+		opts    crypto.DecrypterOpts
+		wantErr bool
+	}{
+		{
+			name:    "pkcs1v15",
+			encrypt: func() ([]byte, error) { return rsa.EncryptPKCS1v15(rand.Reader, pub, msg) },
+		},
+		{
+			name:    "oaep_sha256",
+			encrypt: func() ([]byte, error) { return rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, nil) },
+			opts:    &rsa.OAEPOptions{Hash: crypto.SHA256},
+		},
+		{
+			name:    "oaep_label",
+			encrypt: func() ([]byte, error) { return rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, []byte("label")) },
+			opts:    &rsa.OAEPOptions{Hash: crypto.SHA256, Label: []byte("label")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := tt.encrypt()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := key.Decrypt(rand.Reader, ciphertext, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != string(msg) {
+				t.Errorf("Decrypt() = %q, want %q", got, msg)
+			}
+		})
+	}
+
+	// ciphertext the key cannot decrypt is reported by the framework as a CFError.
+	_, err = key.Decrypt(rand.Reader, make([]byte, pub.Size()), nil)
+	var cfErr *applesecurity.CFError
+	if !errors.As(err, &cfErr) {
+		t.Fatalf("Decrypt() of invalid ciphertext error = %v, want a CFError", err)
+	}
+	if cfErr.Domain == "" || cfErr.Description == "" {
+		t.Errorf("got CFError without a domain or description: %+v", cfErr)
+	}
+}
@@ -0,0 +1,177 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"unsafe"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+)
+
+const (
+	nilSecKey C.SecKeyRef = 0
+	nilCFData C.CFDataRef = 0
+)
+
+// KeyType is the algorithm of a key.
+type KeyType int
+
+const (
+	// RSA keys, corresponding to kSecAttrKeyTypeRSA.
+	RSA KeyType = iota + 1
+	// EC keys on the NIST P-256, P-384 and P-521 curves,
+	// corresponding to kSecAttrKeyTypeECSECPrimeRandom.
+	EC
+)
+
+func (t KeyType) String() string {
+	switch t {
+	case RSA:
+		return "RSA"
+	case EC:
+		return "EC"
+	}
+	return fmt.Sprintf("KeyType(%d)", int(t))
+}
+
+// Key is an RSA or elliptic curve private key stored in the keychain.
+type Key struct {
+	// Scope is the access group of the key and whether
+	// it is synchronised with iCloud Keychain.
+	applesecurity.Scope
+
+	// ApplicationLabel is used to look up a key programmatically
+	// and is the SHA-1 hash of the public key.
+	ApplicationLabel []byte
+	// PublicKey is an *rsa.PublicKey or an *ecdsa.PublicKey.
+	PublicKey crypto.PublicKey
+	Tag       string
+	Label     string
+	// PersistentRef refers to exactly this key. It is set on
+	// keys returned by Get, List and Import.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// Public returns the public key of this key
+func (k *Key) Public() crypto.PublicKey {
+	return k.PublicKey
+}
+
+// Type returns the algorithm of the key.
+func (k *Key) Type() KeyType {
+	switch k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return RSA
+	case *ecdsa.PublicKey:
+		return EC
+	}
+	return 0
+}
+
+// MarshalPKIXPublicKey returns the public key in PKIX,
+// ASN.1 DER form, as used in a "PUBLIC KEY" PEM block.
+func (k *Key) MarshalPKIXPublicKey() ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(k.PublicKey)
+}
+
+// match returns the criteria selecting exactly this key.
+func (k *Key) match() match {
+	return match{
+		Scope: applesecurity.Scope{
+			Keychain:       k.Keychain,
+			AccessGroup:    k.AccessGroup,
+			Synchronizable: applesecurity.SynchronizableAny,
+		},
+		ApplicationLabel: k.ApplicationLabel,
+		PersistentRef:    k.PersistentRef,
+	}
+}
+
+// secKeyType returns the kSecAttrKeyType value for t.
+func secKeyType(t KeyType) (corefoundation.TypeRef, error) {
+	switch t {
+	case RSA:
+		return corefoundation.TypeRef(C.kSecAttrKeyTypeRSA), nil
+	case EC:
+		return corefoundation.TypeRef(C.kSecAttrKeyTypeECSECPrimeRandom), nil
+	}
+	return 0, fmt.Errorf("unsupported key type %v", t)
+}
+
+// extractPublicKey reads the public key of a private key reference.
+func extractPublicKey(key C.SecKeyRef) (crypto.PublicKey, error) {
+	publicKey := C.SecKeyCopyPublicKey(key)
+	if publicKey == nilSecKey {
+		return nil, fmt.Errorf("error extracting public key")
+	}
+	defer C.CFRelease(C.CFTypeRef(publicKey))
+
+	var eref C.CFErrorRef
+	data := C.SecKeyCopyExternalRepresentation(publicKey, &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(data))
+
+	keyData := corefoundation.CFDataToBytes(corefoundation.DataRef(data))
+
+	keyType, err := keyrep.PublicKeyType(keyData)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyrep.ParsePublicKey(keyType, keyData)
+}
+
+// convertResult converts an item returned with kSecReturnRef
+// and kSecReturnAttributes from a query of keychain k to a Key.
+func convertResult(d C.CFDictionaryRef, k applesecurity.Keychain) (Key, error) {
+	keyRef := C.SecKeyRef(C.CFDictionaryGetValue(d, unsafe.Pointer(C.CFStringRef(C.kSecValueRef))))
+	pub, err := extractPublicKey(keyRef)
+	if err != nil {
+		return Key{}, err
+	}
+
+	var result Key
+	result.Label = corefoundation.GetDictionaryStringValue(corefoundation.DictionaryRef(d), corefoundation.StringRef(C.kSecAttrLabel))
+	result.Tag = string(corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(d), corefoundation.DataRef(C.kSecAttrApplicationTag)))
+	result.ApplicationLabel = corefoundation.GetDictionaryDataValue(corefoundation.DictionaryRef(d), corefoundation.DataRef(C.kSecAttrApplicationLabel))
+	result.PublicKey = pub
+	result.Scope = itemattr.Scope(corefoundation.DictionaryRef(d), k)
+	result.PersistentRef = itemattr.PersistentRef(corefoundation.DictionaryRef(d))
+
+	return result, nil
+}
+
+// isSecureEnclaveItem reports whether the attributes of an item
+// show that it is held by the Secure Enclave rather than in software.
+func isSecureEnclaveItem(d C.CFDictionaryRef) bool {
+	tokenID := C.CFDictionaryGetValue(d, unsafe.Pointer(C.CFStringRef(C.kSecAttrTokenID)))
+	if tokenID == nil {
+		return false
+	}
+	return C.CFEqual(C.CFTypeRef(tokenID), C.CFTypeRef(C.kSecAttrTokenIDSecureEnclave)) != 0
+}
+
+// cfTypeDescription returns type string for CFTypeRef.
+func cfTypeDescription(ref C.CFTypeRef) string {
+	typeID := C.CFGetTypeID(ref)
+	typeDesc := C.CFCopyTypeIDDescription(typeID)
+	defer C.CFRelease(C.CFTypeRef(typeDesc))
+	return corefoundation.CFStringToString(corefoundation.StringRef(typeDesc))
+}
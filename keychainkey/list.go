@@ -0,0 +1,20 @@
+package keychainkey
+
+import applesecurity "github.com/common-fate/go-apple-security"
+
+type ListInput struct {
+	applesecurity.Scope
+
+	Tag   string
+	Label string
+}
+
+// List keys matching the criteria specified in ListInput.
+//
+// Keys held by the Secure Enclave are not included, use the
+// enclavekey package for those.
+//
+// Returns nil if no keys are found.
+func List(input ListInput) ([]Key, error) {
+	return match{Scope: input.Scope, Tag: input.Tag, Label: input.Label}.find()
+}
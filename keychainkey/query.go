@@ -0,0 +1,187 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/itemattr"
+)
+
+// match are the attributes which select keys. Empty fields match any key.
+type match struct {
+	applesecurity.Scope
+
+	Tag              string
+	Label            string
+	ApplicationLabel []byte
+
+	// PersistentRef selects exactly one key, the other
+	// criteria are ignored if it is set.
+	PersistentRef applesecurity.PersistentRef
+}
+
+// query returns a query dictionary for private keys matching the
+// criteria in m, merged with extra. The caller must call release.
+func (m match) query(extra corefoundation.Dictionary) (query C.CFDictionaryRef, release func(), err error) {
+	d := corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecClass):        corefoundation.TypeRef(C.kSecClassKey),
+		corefoundation.TypeRef(C.kSecAttrKeyClass): corefoundation.TypeRef(C.kSecAttrKeyClassPrivate),
+	}
+	for k, v := range extra {
+		d[k] = v
+	}
+
+	var releases []func()
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+
+	if !m.PersistentRef.IsZero() {
+		releaseRef, err := itemattr.AddPersistentRef(d, m.PersistentRef, m.Keychain)
+		if err != nil {
+			release()
+			return 0, nil, err
+		}
+		releases = append(releases, releaseRef)
+	} else if err := m.addAttributes(d, &releases); err != nil {
+		release()
+		return 0, nil, err
+	}
+
+	cfQuery, err := corefoundation.NewCFDictionary(d)
+	if err != nil {
+		release()
+		return 0, nil, err
+	}
+	releases = append(releases, func() { C.CFRelease(C.CFTypeRef(cfQuery)) })
+
+	return C.CFDictionaryRef(cfQuery), release, nil
+}
+
+// addAttributes adds the tag, label, application label and scope
+// criteria to d, appending functions releasing the values to releases.
+func (m match) addAttributes(d corefoundation.Dictionary, releases *[]func()) error {
+	if m.Tag != "" {
+		cfTag, err := corefoundation.NewCFData([]byte(m.Tag))
+		if err != nil {
+			return err
+		}
+		*releases = append(*releases, func() { C.CFRelease(C.CFTypeRef(cfTag)) })
+		d[corefoundation.TypeRef(C.kSecAttrApplicationTag)] = corefoundation.TypeRef(cfTag)
+	}
+
+	if m.Label != "" {
+		cfLabel, err := corefoundation.NewCFString(m.Label)
+		if err != nil {
+			return err
+		}
+		*releases = append(*releases, func() { C.CFRelease(C.CFTypeRef(cfLabel)) })
+		d[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	if m.ApplicationLabel != nil {
+		cfAppLabel, err := corefoundation.NewCFData(m.ApplicationLabel)
+		if err != nil {
+			return err
+		}
+		*releases = append(*releases, func() { C.CFRelease(C.CFTypeRef(cfAppLabel)) })
+		d[corefoundation.TypeRef(C.kSecAttrApplicationLabel)] = corefoundation.TypeRef(cfAppLabel)
+	}
+
+	releaseScope, err := itemattr.AddScope(d, m.Scope, true)
+	if err != nil {
+		return err
+	}
+	*releases = append(*releases, releaseScope)
+
+	return nil
+}
+
+// find returns the software keys matching m.
+// Keys held by the Secure Enclave are skipped.
+//
+// Returns nil if no keys are found.
+func (m match) find() ([]Key, error) {
+	query, release, err := m.query(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecReturnRef):           corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnAttributes):    corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecReturnPersistentRef): corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit):          corefoundation.TypeRef(C.kSecMatchLimitAll),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var resultsRef C.CFTypeRef
+	status := C.SecItemCopyMatching(query, &resultsRef)
+	err = goError(status)
+	if errors.Is(err, applesecurity.ErrItemNotFound) {
+		// no items found, return nil.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(resultsRef)
+
+	var results []Key
+
+	arr := corefoundation.CFArrayToArray(corefoundation.ArrayRef(resultsRef))
+	for _, ref := range arr {
+		elementTypeID := C.CFGetTypeID(C.CFTypeRef(ref))
+		if elementTypeID != C.CFDictionaryGetTypeID() {
+			return nil, fmt.Errorf("Invalid result type within array: %s", cfTypeDescription(C.CFTypeRef(ref)))
+		}
+
+		if isSecureEnclaveItem(C.CFDictionaryRef(ref)) {
+			continue
+		}
+
+		key, err := convertResult(C.CFDictionaryRef(ref), m.Keychain)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, key)
+	}
+
+	return results, nil
+}
+
+// copyPrivateKey returns a reference to the private key, found by its
+// PersistentRef, or else its ApplicationLabel and access group. Without
+// either it would match any key, so an error is returned. The caller
+// must release the returned key.
+func (k *Key) copyPrivateKey() (C.SecKeyRef, error) {
+	if k.PersistentRef.IsZero() && len(k.ApplicationLabel) == 0 {
+		return nilSecKey, errors.New("a persistent reference or application label is required to use a key")
+	}
+	query, release, err := k.match().query(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecReturnRef):  corefoundation.TypeRef(C.kCFBooleanTrue),
+		corefoundation.TypeRef(C.kSecMatchLimit): corefoundation.TypeRef(C.kSecMatchLimitOne),
+	})
+	if err != nil {
+		return nilSecKey, err
+	}
+	defer release()
+
+	var key C.CFTypeRef
+	status := C.SecItemCopyMatching(query, &key)
+	if err := goError(status); err != nil {
+		return nilSecKey, err
+	}
+
+	return C.SecKeyRef(key), nil
+}
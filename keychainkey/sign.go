@@ -0,0 +1,118 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+// Sign signs a digest with the private key.
+//
+// RSA keys use PKCS #1 v1.5 padding, or PSS when opts is a
+// *rsa.PSSOptions. ECDSA signatures are ASN.1 DER encoded.
+func (k *Key) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts == nil {
+		return nil, errors.New("signer options are required")
+	}
+
+	algorithm, err := signatureAlgorithm(k.PublicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(digest) != opts.HashFunc().Size() {
+		return nil, fmt.Errorf("digest length %d does not match hash function %v", len(digest), opts.HashFunc())
+	}
+
+	key, err := k.copyPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(key))
+
+	cfDigest, err := corefoundation.NewCFData(digest)
+	if err != nil {
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfDigest))
+
+	var eref C.CFErrorRef
+	signature := C.SecKeyCreateSignature(key, algorithm, C.CFDataRef(cfDigest), &eref)
+	if err := goError(eref); err != nil {
+		C.CFRelease(C.CFTypeRef(eref))
+		return nil, err
+	}
+	defer C.CFRelease(C.CFTypeRef(signature))
+
+	return corefoundation.CFDataToBytes(corefoundation.DataRef(signature)), nil
+}
+
+// signatureAlgorithm returns the SecKeyAlgorithm for signing a digest.
+func signatureAlgorithm(pub crypto.PublicKey, opts crypto.SignerOpts) (C.SecKeyAlgorithm, error) {
+	var algorithm C.SecKeyAlgorithm
+	hash := opts.HashFunc()
+
+	switch pub.(type) {
+	case *ecdsa.PublicKey:
+		switch hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA512, nil
+		}
+
+	case *rsa.PublicKey:
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			// The Security framework always uses a salt as long as the hash.
+			if pss.SaltLength != rsa.PSSSaltLengthAuto && pss.SaltLength != rsa.PSSSaltLengthEqualsHash && pss.SaltLength != hash.Size() {
+				return algorithm, fmt.Errorf("unsupported PSS salt length %d", pss.SaltLength)
+			}
+			switch hash {
+			case crypto.SHA1:
+				return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA1, nil
+			case crypto.SHA224:
+				return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA224, nil
+			case crypto.SHA256:
+				return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA256, nil
+			case crypto.SHA384:
+				return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA384, nil
+			case crypto.SHA512:
+				return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA512, nil
+			}
+			break
+		}
+
+		switch hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA512, nil
+		}
+	}
+
+	return algorithm, fmt.Errorf("unsupported hash function %v for %T", hash, pub)
+}
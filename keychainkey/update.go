@@ -0,0 +1,81 @@
+package keychainkey
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"errors"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+	"github.com/common-fate/go-apple-security/corefoundation"
+)
+
+type UpdateInput struct {
+	// PersistentRef selects the key to update.
+	PersistentRef applesecurity.PersistentRef
+
+	// Keychain is the keychain holding the key.
+	Keychain applesecurity.Keychain
+
+	// Tag and Label replace the attributes of the key.
+	// Empty values leave the attribute unchanged.
+	Tag   string
+	Label string
+}
+
+// Update changes the tag or label of the key referred to by
+// input.PersistentRef.
+func Update(input UpdateInput) error {
+	if input.PersistentRef.IsZero() {
+		return errors.New("a persistent reference is required to update a key")
+	}
+
+	m := corefoundation.Dictionary{}
+
+	if input.Tag != "" {
+		cfTag, err := corefoundation.NewCFData([]byte(input.Tag))
+		if err != nil {
+			return err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfTag))
+
+		m[corefoundation.TypeRef(C.kSecAttrApplicationTag)] = corefoundation.TypeRef(cfTag)
+	}
+
+	if input.Label != "" {
+		cfLabel, err := corefoundation.NewCFString(input.Label)
+		if err != nil {
+			return err
+		}
+		defer C.CFRelease(C.CFTypeRef(cfLabel))
+
+		m[corefoundation.TypeRef(C.kSecAttrLabel)] = corefoundation.TypeRef(cfLabel)
+	}
+
+	if len(m) == 0 {
+		return errors.New("a tag or label is required to update a key")
+	}
+
+	attrs, err := corefoundation.NewCFDictionary(m)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(attrs))
+
+	query, release, err := match{
+		Scope:         applesecurity.Scope{Keychain: input.Keychain},
+		PersistentRef: input.PersistentRef,
+	}.query(nil)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	status := C.SecItemUpdate(query, C.CFDictionaryRef(attrs))
+	return goError(status)
+}
@@ -0,0 +1,69 @@
+package keychainkey
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	applesecurity "github.com/common-fate/go-apple-security"
+)
+
+func TestUpdate_PersistentRef(t *testing.T) {
+	const tag = "com.example.goapplesecurity.test.keychainkey.persistentref"
+
+	_, err := Delete(DeleteInput{Tag: tag})
+	if err != nil && !errors.Is(err, applesecurity.ErrItemNotFound) {
+		t.Fatalf("error deleting existing keys: %v", err)
+	}
+
+	// tags are not unique, create two keys sharing one.
+	for i := 0; i < 2; i++ {
+		if _, err := Create(CreateInput{Type: EC, Tag: tag}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := List(ListInput{Tag: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("wanted 2 keys but got %v", len(keys))
+	}
+	if keys[0].PersistentRef.IsZero() || keys[0].PersistentRef.Equal(keys[1].PersistentRef) {
+		t.Fatalf("wanted distinct persistent references")
+	}
+
+	got, err := Get(GetInput{PersistentRef: keys[1].PersistentRef})
+	if err != nil {
+		t.Fatalf("Get() by reference error = %v", err)
+	}
+	if !bytes.Equal(got.ApplicationLabel, keys[1].ApplicationLabel) {
+		t.Errorf("Get() by reference returned the wrong key")
+	}
+
+	err = Update(UpdateInput{PersistentRef: keys[1].PersistentRef, Label: "renamed"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	renamed, err := List(ListInput{Tag: tag, Label: "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(renamed) != 1 || !bytes.Equal(renamed[0].ApplicationLabel, keys[1].ApplicationLabel) {
+		t.Errorf("wanted only the second key to be renamed")
+	}
+
+	deleted, err := Delete(DeleteInput{PersistentRef: keys[0].PersistentRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Errorf("wanted 1 key deleted but got %v", deleted)
+	}
+
+	if _, err := Delete(DeleteInput{Tag: tag}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package localauth describes errors raised by the LocalAuthentication
+// framework, which prompts for Touch ID, Apple Watch or the user's
+// password when a protected key is used.
+//
+// The Security framework reports them as CFErrors in the [Domain] domain,
+// which the packages of this module convert to [LAError] values.
+package localauth
+
+import "fmt"
+
+// Domain is the CFError domain of LocalAuthentication errors.
+const Domain = "com.apple.LocalAuthentication"
+
+// LAError is a LocalAuthentication error code.
+//
+// See: https://developer.apple.com/documentation/localauthentication/laerror/code
+type LAError int
+
+var (
+	// ErrAuthenticationFailed corresponds to LAErrorAuthenticationFailed:
+	// the user failed to provide valid credentials.
+	ErrAuthenticationFailed = LAError(-1)
+	// ErrUserCancel corresponds to LAErrorUserCancel: the user cancelled the prompt.
+	ErrUserCancel = LAError(-2)
+	// ErrUserFallback corresponds to LAErrorUserFallback: the user
+	// chose the fallback button rather than authenticating.
+	ErrUserFallback = LAError(-3)
+	// ErrSystemCancel corresponds to LAErrorSystemCancel: the system
+	// cancelled the prompt, for example because another application came to the front.
+	ErrSystemCancel = LAError(-4)
+	// ErrPasscodeNotSet corresponds to LAErrorPasscodeNotSet.
+	ErrPasscodeNotSet = LAError(-5)
+	// ErrBiometryNotAvailable corresponds to LAErrorBiometryNotAvailable.
+	ErrBiometryNotAvailable = LAError(-6)
+	// ErrBiometryNotEnrolled corresponds to LAErrorBiometryNotEnrolled.
+	ErrBiometryNotEnrolled = LAError(-7)
+	// ErrBiometryLockout corresponds to LAErrorBiometryLockout: there were
+	// too many failed attempts and the user must enter their password to
+	// unlock biometry.
+	ErrBiometryLockout = LAError(-8)
+	// ErrAppCancel corresponds to LAErrorAppCancel: the application
+	// invalidated the authentication context.
+	ErrAppCancel = LAError(-9)
+	// ErrInvalidContext corresponds to LAErrorInvalidContext.
+	ErrInvalidContext = LAError(-10)
+	// ErrCompanionNotAvailable corresponds to LAErrorCompanionNotAvailable.
+	ErrCompanionNotAvailable = LAError(-11)
+	// ErrBiometryNotPaired corresponds to LAErrorBiometryNotPaired.
+	ErrBiometryNotPaired = LAError(-12)
+	// ErrBiometryDisconnected corresponds to LAErrorBiometryDisconnected.
+	ErrBiometryDisconnected = LAError(-13)
+	// ErrInvalidDimensions corresponds to LAErrorInvalidDimensions.
+	ErrInvalidDimensions = LAError(-14)
+	// ErrNotInteractive corresponds to LAErrorNotInteractive: a prompt
+	// was needed but interaction was not allowed.
+	ErrNotInteractive = LAError(-1004)
+)
+
+var descriptions = map[LAError]struct{ name, msg string }{
+	ErrAuthenticationFailed:  {"LAErrorAuthenticationFailed", "authentication failed"},
+	ErrUserCancel:            {"LAErrorUserCancel", "authentication was cancelled by the user"},
+	ErrUserFallback:          {"LAErrorUserFallback", "the user chose to use the fallback authentication method"},
+	ErrSystemCancel:          {"LAErrorSystemCancel", "authentication was cancelled by the system"},
+	ErrPasscodeNotSet:        {"LAErrorPasscodeNotSet", "no password is set on the device"},
+	ErrBiometryNotAvailable:  {"LAErrorBiometryNotAvailable", "biometry is not available on the device"},
+	ErrBiometryNotEnrolled:   {"LAErrorBiometryNotEnrolled", "no fingerprints or faces are enrolled"},
+	ErrBiometryLockout:       {"LAErrorBiometryLockout", "biometry is locked out after too many failed attempts"},
+	ErrAppCancel:             {"LAErrorAppCancel", "authentication was cancelled by the application"},
+	ErrInvalidContext:        {"LAErrorInvalidContext", "the authentication context is invalid"},
+	ErrCompanionNotAvailable: {"LAErrorCompanionNotAvailable", "no paired companion device is nearby"},
+	ErrBiometryNotPaired:     {"LAErrorBiometryNotPaired", "the biometric accessory is not paired"},
+	ErrBiometryDisconnected:  {"LAErrorBiometryDisconnected", "the biometric accessory is disconnected"},
+	ErrInvalidDimensions:     {"LAErrorInvalidDimensions", "invalid dimensions"},
+	ErrNotInteractive:        {"LAErrorNotInteractive", "authentication requires user interaction, which is not allowed"},
+}
+
+// Name returns the symbolic name of the code,
+// such as "LAErrorUserCancel", or "" if it is unknown.
+func (e LAError) Name() string {
+	return descriptions[e].name
+}
+
+func (e LAError) Error() string {
+	d, ok := descriptions[e]
+	if !ok {
+		return fmt.Sprintf("local authentication error (%d)", int(e))
+	}
+	return fmt.Sprintf("%s (%s %d)", d.msg, d.name, int(e))
+}
+
+// FromCFError returns the LAError for a CFError with the given domain
+// and code. It returns false if the error is not in the LocalAuthentication domain.
+func FromCFError(domain string, code int) (LAError, bool) {
+	if domain != Domain {
+		return 0, false
+	}
+	return LAError(code), true
+}
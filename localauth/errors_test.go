@@ -0,0 +1,52 @@
+package localauth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFromCFError(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		code     int
+		want     error
+		wantOK   bool
+		wantName string
+	}{
+		{name: "lockout", domain: Domain, code: -8, want: ErrBiometryLockout, wantOK: true, wantName: "LAErrorBiometryLockout"},
+		{name: "user_cancel", domain: Domain, code: -2, want: ErrUserCancel, wantOK: true, wantName: "LAErrorUserCancel"},
+		{name: "system_cancel", domain: Domain, code: -4, want: ErrSystemCancel, wantOK: true, wantName: "LAErrorSystemCancel"},
+		{name: "not_interactive", domain: Domain, code: -1004, want: ErrNotInteractive, wantOK: true, wantName: "LAErrorNotInteractive"},
+		{name: "unknown_code", domain: Domain, code: -999, want: LAError(-999), wantOK: true},
+		{name: "osstatus_domain", domain: "NSOSStatusErrorDomain", code: -8, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromCFError(tt.domain, tt.code)
+			if ok != tt.wantOK {
+				t.Fatalf("FromCFError() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Name() != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got.Name(), tt.wantName)
+			}
+
+			// the error is usually wrapped with the operation which failed.
+			err := fmt.Errorf("Sign: %w", got)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+			if got.Error() == "" {
+				t.Errorf("empty error message")
+			}
+		})
+	}
+
+	if errors.Is(ErrBiometryLockout, ErrUserCancel) {
+		t.Errorf("lockout and cancellation must be distinguishable")
+	}
+}
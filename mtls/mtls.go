@@ -0,0 +1,124 @@
+// Package mtls presents client certificates whose private keys stay in
+// the keychain or the Secure Enclave, such as an enclavekey.Key, in
+// mutual TLS connections made with crypto/tls.
+//
+// The package is pure Go, so handshakes can be tested with software
+// keys on any platform. On macOS, identity.Identity.TLSCertificate
+// returns a certificate with its chain loaded from the keychain.
+package mtls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Certificate returns a TLS certificate for signer, presenting chain,
+// which begins with the certificate of the public key of signer.
+//
+// The signature schemes of the certificate are limited to those the
+// key can make: for an ECDSA key, only the scheme of its curve, as the
+// Secure Enclave signs SHA-256, SHA-384 and SHA-512 digests but not the
+// SHA-1 digests of legacy TLS 1.2 peers. An ECDSA signer must return
+// ASN.1 DER signatures, the default encoding of enclavekey.Key.
+func Certificate(signer crypto.Signer, chain []*x509.Certificate) (tls.Certificate, error) {
+	if len(chain) == 0 {
+		return tls.Certificate{}, errors.New("a certificate chain is required")
+	}
+	leaf := chain[0]
+
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(leaf.PublicKey) {
+		return tls.Certificate{}, errors.New("certificate does not match the public key of the signer")
+	}
+
+	schemes, err := SignatureSchemes(signer.Public())
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	cert := tls.Certificate{
+		PrivateKey:                   signer,
+		Leaf:                         leaf,
+		SupportedSignatureAlgorithms: schemes,
+	}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	return cert, nil
+}
+
+// CertificateFromPEM returns a TLS certificate for signer, presenting
+// the chain of PEM encoded certificates in chainPEM, beginning with the
+// certificate of the public key of signer. Blocks other than
+// certificates are ignored.
+func CertificateFromPEM(signer crypto.Signer, chainPEM []byte) (tls.Certificate, error) {
+	var chain []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, chainPEM = pem.Decode(chainPEM)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return tls.Certificate{}, err
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return tls.Certificate{}, errors.New("no PEM encoded certificates found")
+	}
+	return Certificate(signer, chain)
+}
+
+// SignatureSchemes returns the TLS signature schemes which a key of pub
+// can sign with, in order of preference.
+func SignatureSchemes(pub crypto.PublicKey) ([]tls.SignatureScheme, error) {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256}, nil
+		case elliptic.P384():
+			return []tls.SignatureScheme{tls.ECDSAWithP384AndSHA384}, nil
+		case elliptic.P521():
+			return []tls.SignatureScheme{tls.ECDSAWithP521AndSHA512}, nil
+		}
+		return nil, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+
+	case *rsa.PublicKey:
+		return []tls.SignatureScheme{
+			tls.PSSWithSHA256, tls.PSSWithSHA384, tls.PSSWithSHA512,
+			tls.PKCS1WithSHA256, tls.PKCS1WithSHA384, tls.PKCS1WithSHA512,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// GetClientCertificate returns a function for tls.Config.GetClientCertificate
+// which selects the first of certs that the server accepts: one issued,
+// directly or through its chain, by a certificate authority the server
+// names, with a signature scheme it supports.
+//
+// If the server accepts none of them, no certificate is sent, leaving
+// the server to decide whether to continue the handshake.
+func GetClientCertificate(certs ...tls.Certificate) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		for i := range certs {
+			if err := cri.SupportsCertificate(&certs[i]); err == nil {
+				return &certs[i], nil
+			}
+		}
+		return &tls.Certificate{}, nil
+	}
+}
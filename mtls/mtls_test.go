@@ -0,0 +1,230 @@
+package mtls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/common-fate/go-apple-security/certgen"
+	"github.com/common-fate/go-apple-security/internal/sigopts"
+)
+
+// enclaveSigner validates its options as an enclavekey.Key does,
+// recording the hash functions it was asked to sign with.
+type enclaveSigner struct {
+	*ecdsa.PrivateKey
+
+	mu     sync.Mutex
+	hashes []crypto.Hash
+}
+
+func (s *enclaveSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash, err := sigopts.Digest(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.hashes = append(s.hashes, hash)
+	s.mu.Unlock()
+	return s.PrivateKey.Sign(rand, digest, opts)
+}
+
+func newSigner(t *testing.T, curve elliptic.Curve) *enclaveSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &enclaveSigner{PrivateKey: key}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := certgen.CreateSelfSigned(key, &certgen.Template{
+		Subject:  pkix.Name{CommonName: name},
+		KeyUsage: x509.KeyUsageCertSign,
+		IsCA:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, pub crypto.PublicKey, name string) *x509.Certificate {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// newServer returns a TLS server requiring a client certificate issued
+// by ca, which responds with the common name of the client certificate.
+func newServer(t *testing.T, ca *testCA, version uint16) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: version,
+		MaxVersion: version,
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(srv *httptest.Server, certs ...tls.Certificate) (string, error) {
+	client := srv.Client()
+	transport := client.Transport.(*http.Transport)
+	transport.TLSClientConfig.GetClientCertificate = GetClientCertificate(certs...)
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestHandshake(t *testing.T) {
+	ca := newCA(t, "Internal CA")
+
+	curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+	versions := map[string]uint16{"TLS 1.2": tls.VersionTLS12, "TLS 1.3": tls.VersionTLS13}
+	wantHash := map[string]crypto.Hash{"P-256": crypto.SHA256, "P-384": crypto.SHA384, "P-521": crypto.SHA512}
+
+	for curveName, curve := range curves {
+		for versionName, version := range versions {
+			t.Run(curveName+"/"+versionName, func(t *testing.T) {
+				signer := newSigner(t, curve)
+				cert, err := Certificate(signer, []*x509.Certificate{ca.issue(t, signer.Public(), "device"), ca.cert})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got, err := get(newServer(t, ca, version), cert)
+				if err != nil {
+					t.Fatalf("request error = %v", err)
+				}
+				if got != "device" {
+					t.Errorf("server saw client certificate %q, want %q", got, "device")
+				}
+				if len(signer.hashes) != 1 || signer.hashes[0] != wantHash[curveName] {
+					t.Errorf("signed with %v, want %v", signer.hashes, wantHash[curveName])
+				}
+			})
+		}
+	}
+}
+
+func TestGetClientCertificate(t *testing.T) {
+	caA, caB, caC := newCA(t, "CA A"), newCA(t, "CA B"), newCA(t, "CA C")
+
+	signer := newSigner(t, elliptic.P256())
+	fromA, err := Certificate(signer, []*x509.Certificate{caA.issue(t, signer.Public(), "device-a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromB, err := Certificate(signer, []*x509.Certificate{caB.issue(t, signer.Public(), "device-b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ca   *testCA
+		want string
+	}{
+		{ca: caA, want: "device-a"},
+		{ca: caB, want: "device-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ca.cert.Subject.CommonName, func(t *testing.T) {
+			got, err := get(newServer(t, tt.ca, tls.VersionTLS13), fromA, fromB)
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("server saw client certificate %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no certificate accepted", func(t *testing.T) {
+		if _, err := get(newServer(t, caC, tls.VersionTLS12), fromA, fromB); err == nil {
+			t.Error("request succeeded without an accepted client certificate")
+		}
+	})
+}
+
+func TestCertificateFromPEM(t *testing.T) {
+	ca := newCA(t, "Internal CA")
+	signer := newSigner(t, elliptic.P256())
+	leaf := ca.issue(t, signer.Public(), "device")
+
+	var chainPEM []byte
+	chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("ignored")})...)
+	for _, c := range []*x509.Certificate{leaf, ca.cert} {
+		chainPEM = append(chainPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})...)
+	}
+
+	cert, err := CertificateFromPEM(signer, chainPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.Certificate) != 2 || !cert.Leaf.Equal(leaf) {
+		t.Errorf("got %d certificates, want the leaf and CA", len(cert.Certificate))
+	}
+	if len(cert.SupportedSignatureAlgorithms) != 1 || cert.SupportedSignatureAlgorithms[0] != tls.ECDSAWithP256AndSHA256 {
+		t.Errorf("SupportedSignatureAlgorithms = %v", cert.SupportedSignatureAlgorithms)
+	}
+
+	other := newSigner(t, elliptic.P256())
+	if _, err := CertificateFromPEM(other, chainPEM); err == nil {
+		t.Error("CertificateFromPEM() with the wrong signer succeeded")
+	}
+	if _, err := CertificateFromPEM(signer, nil); err == nil {
+		t.Error("CertificateFromPEM() without certificates succeeded")
+	}
+}
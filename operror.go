@@ -0,0 +1,65 @@
+package applesecurity
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// OpError is returned by the keychain and enclavekey packages when an
+// operation fails. It records the operation and the identifiers of the
+// items it was performed on, but never their secret data.
+//
+// Use errors.Is to test for a result code, such as
+// errors.Is(err, ErrItemNotFound), and errors.As to read the OpError.
+type OpError struct {
+	// Op is the name of the function which failed, such as "AddGenericPassword".
+	Op string
+
+	// The identifiers of the items in the operation.
+	// Empty fields were not part of it.
+	Service     string
+	Account     string
+	Tag         string
+	Label       string
+	AccessGroup string
+
+	Err error
+}
+
+func (e *OpError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+
+	for _, f := range []struct{ name, value string }{
+		{"service", e.Service},
+		{"account", e.Account},
+		{"tag", e.Tag},
+		{"label", e.Label},
+		{"access group", e.AccessGroup},
+	} {
+		if f.value == "" {
+			continue
+		}
+		b.WriteString(" ")
+		b.WriteString(f.name)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(f.value))
+	}
+
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *OpError) Unwrap() error { return e.Err }
+
+// Code returns the result code of the Security framework
+// which caused the error, or 0 if it was raised in Go.
+func (e *OpError) Code() Error {
+	var code Error
+	if errors.As(e.Err, &code) {
+		return code
+	}
+	return 0
+}
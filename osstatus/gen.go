@@ -0,0 +1,154 @@
+//go:build ignore
+
+// gen reads the result codes declared in the Security framework headers
+// of the macOS SDK and writes them to table.go. Run it with go generate
+// on a Mac with the Xcode command line tools installed:
+//
+//	go generate ./osstatus
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resultCode matches a result code declaration with a trailing comment,
+// such as:
+//
+//	errSecItemNotFound = -25300, /* The specified item could not be found in the keychain. */
+var resultCode = regexp.MustCompile(`^\s*(errSec\w+)\s*=\s*(-?\d+)\s*,?\s*/\*\s*(.*?)\s*\*/`)
+
+type entry struct {
+	code     int32
+	name     string
+	message  string
+	category string
+}
+
+func main() {
+	sdk := flag.String("sdk", "", "path to the macOS SDK (default: xcrun --show-sdk-path)")
+	out := flag.String("o", "table.go", "output file")
+	flag.Parse()
+
+	if *sdk == "" {
+		path, err := exec.Command("xcrun", "--show-sdk-path").Output()
+		if err != nil {
+			log.Fatalf("finding the macOS SDK: %v", err)
+		}
+		*sdk = strings.TrimSpace(string(path))
+	}
+
+	header := filepath.Join(*sdk, "System/Library/Frameworks/Security.framework/Headers/SecBase.h")
+	entries, err := parse(header)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(entries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse reads the result codes declared in header. Where a code has
+// several names, such as deprecated spellings, the first is kept.
+func parse(header string) ([]entry, error) {
+	f, err := os.Open(header)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := map[int32]bool{}
+	var entries []entry
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		m := resultCode.FindStringSubmatch(s.Text())
+		if m == nil {
+			continue
+		}
+		code, err := strconv.ParseInt(m[2], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parsing code of %s: %w", m[1], err)
+		}
+		if seen[int32(code)] {
+			continue
+		}
+		seen[int32(code)] = true
+
+		entries = append(entries, entry{
+			code:     int32(code),
+			name:     m[1],
+			message:  m[3],
+			category: category(m[1], int32(code)),
+		})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no result codes found in %s", header)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].code > entries[j].code })
+	return entries, nil
+}
+
+// trustNames are fragments of the names of result codes
+// raised when validating certificates and their chains.
+var trustNames = []string{
+	"Cert", "CRL", "OCSP", "Trust", "Timestamp", "SigningTime", "SMIME", "SSL",
+	"CodeSigning", "ResourceSign", "BasicConstraints", "PathLength", "HostName",
+	"KeyUsage", "KeyID", "IDLinkage", "InvalidRoot", "IDPFailure", "MobileMe",
+	"NotSigner", "SubjectName", "QualifiedCert", "Extension",
+}
+
+// category assigns a result code to a Category,
+// returning the name of the constant.
+func category(name string, code int32) string {
+	switch {
+	case code == 0:
+		return "CategoryGeneral"
+	case code <= -25256 && code >= -25264:
+		return "CategoryImportExport"
+	}
+	for _, n := range trustNames {
+		if strings.Contains(name, n) {
+			return "CategoryTrust"
+		}
+	}
+	switch {
+	case code <= -25240 && code >= -25320, code == -34018, code == -34020:
+		return "CategoryKeychain"
+	case code <= -67585 && code >= -67999:
+		return "CategoryCSSM"
+	}
+	return "CategoryGeneral"
+}
+
+func generate(entries []entry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen.go from SecBase.h; DO NOT EDIT.\n\n")
+	b.WriteString("package osstatus\n\n")
+	b.WriteString("var table = map[int32]Info{\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "\t%d: {Name: %q, Message: %q, Category: %s},\n", e.code, e.name, e.message, e.category)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
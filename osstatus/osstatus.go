@@ -0,0 +1,107 @@
+// Package osstatus describes the OSStatus result codes returned by the
+// Security framework.
+//
+// The table of codes is generated from the SecBase.h header of the macOS
+// SDK, and the package is pure Go so that codes found in logs can be
+// decoded on any platform.
+package osstatus
+
+//go:generate go run gen.go -o table.go
+
+import "fmt"
+
+// Category groups result codes by the part of the Security framework
+// which returns them.
+type Category int
+
+const (
+	// CategoryUnknown is the category of codes missing from the table.
+	CategoryUnknown Category = iota
+	// CategoryGeneral codes are shared with the rest of macOS,
+	// such as invalid parameters and I/O errors.
+	CategoryGeneral
+	// CategoryKeychain codes are returned by keychain item operations.
+	CategoryKeychain
+	// CategoryImportExport codes are returned when importing
+	// and exporting keys, certificates and identities.
+	CategoryImportExport
+	// CategoryTrust codes are returned when validating certificates.
+	CategoryTrust
+	// CategoryCSSM codes originate in the CDSA/CSSM layer
+	// underlying the legacy keychain and cryptographic services.
+	CategoryCSSM
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryUnknown:
+		return "unknown"
+	case CategoryGeneral:
+		return "general"
+	case CategoryKeychain:
+		return "keychain"
+	case CategoryImportExport:
+		return "import/export"
+	case CategoryTrust:
+		return "trust"
+	case CategoryCSSM:
+		return "CSSM"
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// Info describes a result code.
+type Info struct {
+	Code int32
+	// Name is the symbolic name of the code, such as "errSecItemNotFound".
+	Name string
+	// Message is the description of the code from the SDK headers.
+	Message  string
+	Category Category
+}
+
+// cssmBaseError is CSSM_BASE_ERROR from cssmerr.h. Raw CSSM error
+// codes are offsets from it, in a block of cssmModuleExtent codes
+// for each module.
+const (
+	cssmBaseError          = -0x7FFF0000
+	cssmModuleExtent       = 0x800
+	cssmCustomOffset       = 0x400
+	cssmModuleErrorsLength = cssmModuleExtent * 8
+)
+
+// cssmModules are the modules in the order of their blocks of error codes.
+var cssmModules = [...]string{"CSSM", "CSP", "DL", "CL", "TP", "KR", "AC", "MDS"}
+
+// Lookup returns a description of code. The second result is false if
+// the code is unknown, in which case Info only has Code and Category set.
+//
+// Raw CSSM error codes, which the Security framework usually translates
+// to codes in SecBase.h but sometimes returns as is, are described by
+// the module which raised them.
+func Lookup(code int32) (Info, bool) {
+	if info, ok := table[code]; ok {
+		info.Code = code
+		return info, true
+	}
+
+	if offset := int64(code) - cssmBaseError; offset >= 0 && offset < cssmModuleErrorsLength {
+		module := cssmModules[offset/cssmModuleExtent]
+		moduleOffset := offset % cssmModuleExtent
+
+		kind := "BASE"
+		if moduleOffset >= cssmCustomOffset {
+			kind = "PRIVATE"
+			moduleOffset -= cssmCustomOffset
+		}
+
+		return Info{
+			Code:     code,
+			Name:     fmt.Sprintf("CSSM_%s_%s_ERROR+%d", module, kind, moduleOffset),
+			Message:  fmt.Sprintf("%s module error %d.", module, moduleOffset),
+			Category: CategoryCSSM,
+		}, true
+	}
+
+	return Info{Code: code, Category: CategoryUnknown}, false
+}
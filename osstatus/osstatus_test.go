@@ -0,0 +1,64 @@
+package osstatus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name         string
+		code         int32
+		wantOK       bool
+		wantName     string
+		wantCategory Category
+	}{
+		{name: "param", code: -50, wantOK: true, wantName: "errSecParam", wantCategory: CategoryGeneral},
+		{name: "item_not_found", code: -25300, wantOK: true, wantName: "errSecItemNotFound", wantCategory: CategoryKeychain},
+		{name: "interaction_not_allowed", code: -25308, wantOK: true, wantName: "errSecInteractionNotAllowed", wantCategory: CategoryKeychain},
+		{name: "missing_entitlement", code: -34018, wantOK: true, wantName: "errSecMissingEntitlement", wantCategory: CategoryKeychain},
+		{name: "pkcs12_verify", code: -25264, wantOK: true, wantName: "errSecPkcs12VerifyFailure", wantCategory: CategoryImportExport},
+		{name: "cert_expired", code: -67818, wantOK: true, wantName: "errSecCertificateExpired", wantCategory: CategoryTrust},
+		{name: "verify_failed", code: -67808, wantOK: true, wantName: "errSecVerifyFailed", wantCategory: CategoryCSSM},
+		{name: "raw_cssm_csp", code: -2147418112 + 0x800 + 5, wantOK: true, wantName: "CSSM_CSP_BASE_ERROR+5", wantCategory: CategoryCSSM},
+		{name: "raw_cssm_dl_private", code: -2147418112 + 2*0x800 + 0x400 + 1, wantOK: true, wantName: "CSSM_DL_PRIVATE_ERROR+1", wantCategory: CategoryCSSM},
+		{name: "unknown", code: -1, wantOK: false, wantCategory: CategoryUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.code)
+			if ok != tt.wantOK {
+				t.Fatalf("Lookup(%d) ok = %v, want %v", tt.code, ok, tt.wantOK)
+			}
+			if got.Code != tt.code {
+				t.Errorf("Lookup(%d) Code = %d", tt.code, got.Code)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("Lookup(%d) Name = %q, want %q", tt.code, got.Name, tt.wantName)
+			}
+			if got.Category != tt.wantCategory {
+				t.Errorf("Lookup(%d) Category = %v, want %v", tt.code, got.Category, tt.wantCategory)
+			}
+			if ok && got.Message == "" {
+				t.Errorf("Lookup(%d) has an empty Message", tt.code)
+			}
+		})
+	}
+}
+
+func TestTable(t *testing.T) {
+	names := map[string]int32{}
+	for code, info := range table {
+		if !strings.HasPrefix(info.Name, "errSec") {
+			t.Errorf("code %d has name %q", code, info.Name)
+		}
+		if other, ok := names[info.Name]; ok {
+			t.Errorf("name %s is used by codes %d and %d", info.Name, code, other)
+		}
+		names[info.Name] = code
+
+		if info.Category == CategoryUnknown {
+			t.Errorf("%s has no category", info.Name)
+		}
+	}
+}
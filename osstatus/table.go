@@ -0,0 +1,386 @@
+// Code generated by gen.go from SecBase.h; DO NOT EDIT.
+
+package osstatus
+
+var table = map[int32]Info{
+	0:      {Name: "errSecSuccess", Message: "No error.", Category: CategoryGeneral},
+	-4:     {Name: "errSecUnimplemented", Message: "Function or operation not implemented.", Category: CategoryGeneral},
+	-34:    {Name: "errSecDiskFull", Message: "The disk is full.", Category: CategoryGeneral},
+	-36:    {Name: "errSecIO", Message: "I/O error.", Category: CategoryGeneral},
+	-49:    {Name: "errSecOpWr", Message: "File already open with write permission.", Category: CategoryGeneral},
+	-50:    {Name: "errSecParam", Message: "One or more parameters passed to a function were not valid.", Category: CategoryGeneral},
+	-61:    {Name: "errSecWrPerm", Message: "Write permissions error.", Category: CategoryGeneral},
+	-108:   {Name: "errSecAllocate", Message: "Failed to allocate memory.", Category: CategoryGeneral},
+	-128:   {Name: "errSecUserCanceled", Message: "User canceled the operation.", Category: CategoryGeneral},
+	-909:   {Name: "errSecBadReq", Message: "Bad parameter or invalid state for operation.", Category: CategoryGeneral},
+	-2070:  {Name: "errSecInternalComponent", Message: "An internal component failed.", Category: CategoryGeneral},
+	-4960:  {Name: "errSecCoreFoundationUnknown", Message: "An unknown Core Foundation error occurred.", Category: CategoryGeneral},
+	-25240: {Name: "errSecACLNotSimple", Message: "The specified access control list is not in standard (simple) form.", Category: CategoryKeychain},
+	-25241: {Name: "errSecPolicyNotFound", Message: "The specified policy cannot be found.", Category: CategoryKeychain},
+	-25242: {Name: "errSecInvalidTrustSetting", Message: "The specified trust setting is invalid.", Category: CategoryTrust},
+	-25243: {Name: "errSecNoAccessForItem", Message: "The specified item has no access control.", Category: CategoryKeychain},
+	-25244: {Name: "errSecInvalidOwnerEdit", Message: "Invalid attempt to change the owner of this item.", Category: CategoryKeychain},
+	-25245: {Name: "errSecTrustNotAvailable", Message: "No trust results are available.", Category: CategoryTrust},
+	-25256: {Name: "errSecUnsupportedFormat", Message: "Import/Export format unsupported.", Category: CategoryImportExport},
+	-25257: {Name: "errSecUnknownFormat", Message: "Unknown format in import.", Category: CategoryImportExport},
+	-25258: {Name: "errSecKeyIsSensitive", Message: "Key material must be wrapped for export.", Category: CategoryImportExport},
+	-25259: {Name: "errSecMultiplePrivKeys", Message: "An attempt was made to import multiple private keys.", Category: CategoryImportExport},
+	-25260: {Name: "errSecPassphraseRequired", Message: "Passphrase is required for import/export.", Category: CategoryImportExport},
+	-25261: {Name: "errSecInvalidPasswordRef", Message: "The password reference was invalid.", Category: CategoryImportExport},
+	-25262: {Name: "errSecInvalidTrustSettings", Message: "The Trust Settings Record was corrupted.", Category: CategoryImportExport},
+	-25263: {Name: "errSecNoTrustSettings", Message: "No Trust Settings were found.", Category: CategoryImportExport},
+	-25264: {Name: "errSecPkcs12VerifyFailure", Message: "MAC verification failed during PKCS12 import (wrong password?)", Category: CategoryImportExport},
+	-25291: {Name: "errSecNotAvailable", Message: "No keychain is available. You may need to restart your computer.", Category: CategoryKeychain},
+	-25292: {Name: "errSecReadOnly", Message: "This keychain cannot be modified.", Category: CategoryKeychain},
+	-25293: {Name: "errSecAuthFailed", Message: "The user name or passphrase you entered is not correct.", Category: CategoryKeychain},
+	-25294: {Name: "errSecNoSuchKeychain", Message: "The specified keychain could not be found.", Category: CategoryKeychain},
+	-25295: {Name: "errSecInvalidKeychain", Message: "The specified keychain is not a valid keychain file.", Category: CategoryKeychain},
+	-25296: {Name: "errSecDuplicateKeychain", Message: "A keychain with the same name already exists.", Category: CategoryKeychain},
+	-25297: {Name: "errSecDuplicateCallback", Message: "The specified callback function is already installed.", Category: CategoryKeychain},
+	-25298: {Name: "errSecInvalidCallback", Message: "The specified callback function is not valid.", Category: CategoryKeychain},
+	-25299: {Name: "errSecDuplicateItem", Message: "The specified item already exists in the keychain.", Category: CategoryKeychain},
+	-25300: {Name: "errSecItemNotFound", Message: "The specified item could not be found in the keychain.", Category: CategoryKeychain},
+	-25301: {Name: "errSecBufferTooSmall", Message: "There is not enough memory available to use the specified item.", Category: CategoryKeychain},
+	-25302: {Name: "errSecDataTooLarge", Message: "This item contains information which is too large or in a format that cannot be displayed.", Category: CategoryKeychain},
+	-25303: {Name: "errSecNoSuchAttr", Message: "The specified attribute does not exist.", Category: CategoryKeychain},
+	-25304: {Name: "errSecInvalidItemRef", Message: "The specified item is no longer valid. It may have been deleted from the keychain.", Category: CategoryKeychain},
+	-25305: {Name: "errSecInvalidSearchRef", Message: "Unable to search the current keychain.", Category: CategoryKeychain},
+	-25306: {Name: "errSecNoSuchClass", Message: "The specified item does not appear to be a valid keychain item.", Category: CategoryKeychain},
+	-25307: {Name: "errSecNoDefaultKeychain", Message: "A default keychain could not be found.", Category: CategoryKeychain},
+	-25308: {Name: "errSecInteractionNotAllowed", Message: "User interaction is not allowed.", Category: CategoryKeychain},
+	-25309: {Name: "errSecReadOnlyAttr", Message: "The specified attribute could not be modified.", Category: CategoryKeychain},
+	-25310: {Name: "errSecWrongSecVersion", Message: "This keychain was created by a different version of the system software and cannot be opened.", Category: CategoryKeychain},
+	-25311: {Name: "errSecKeySizeNotAllowed", Message: "This item specifies a key size which is too large or too small.", Category: CategoryKeychain},
+	-25312: {Name: "errSecNoStorageModule", Message: "A required component (data storage module) could not be loaded. You may need to restart your computer.", Category: CategoryKeychain},
+	-25313: {Name: "errSecNoCertificateModule", Message: "A required component (certificate module) could not be loaded. You may need to restart your computer.", Category: CategoryTrust},
+	-25314: {Name: "errSecNoPolicyModule", Message: "A required component (policy module) could not be loaded. You may need to restart your computer.", Category: CategoryKeychain},
+	-25315: {Name: "errSecInteractionRequired", Message: "User interaction is required, but is currently not allowed.", Category: CategoryKeychain},
+	-25316: {Name: "errSecDataNotAvailable", Message: "The contents of this item cannot be retrieved.", Category: CategoryKeychain},
+	-25317: {Name: "errSecDataNotModifiable", Message: "The contents of this item cannot be modified.", Category: CategoryKeychain},
+	-25318: {Name: "errSecCreateChainFailed", Message: "One or more certificates required to validate this certificate cannot be found.", Category: CategoryKeychain},
+	-25319: {Name: "errSecInvalidPrefsDomain", Message: "The specified preferences domain is not valid.", Category: CategoryKeychain},
+	-25320: {Name: "errSecInDarkWake", Message: "In dark wake, no UI possible", Category: CategoryKeychain},
+	-26267: {Name: "errSecNotSigner", Message: "A certificate was not signed by its proposed parent.", Category: CategoryTrust},
+	-26275: {Name: "errSecDecode", Message: "Unable to decode the provided data.", Category: CategoryGeneral},
+	-34018: {Name: "errSecMissingEntitlement", Message: "A required entitlement isn't present.", Category: CategoryKeychain},
+	-34020: {Name: "errSecRestrictedAPI", Message: "Client is restricted and is not permitted to perform this operation.", Category: CategoryKeychain},
+	-67585: {Name: "errSecServiceNotAvailable", Message: "The required service is not available.", Category: CategoryCSSM},
+	-67586: {Name: "errSecInsufficientClientID", Message: "The client ID is not correct.", Category: CategoryCSSM},
+	-67587: {Name: "errSecDeviceReset", Message: "A device reset has occurred.", Category: CategoryCSSM},
+	-67588: {Name: "errSecDeviceFailed", Message: "A device failure has occurred.", Category: CategoryCSSM},
+	-67589: {Name: "errSecAppleAddAppACLSubject", Message: "Adding an application ACL subject failed.", Category: CategoryCSSM},
+	-67590: {Name: "errSecApplePublicKeyIncomplete", Message: "The public key is incomplete.", Category: CategoryCSSM},
+	-67591: {Name: "errSecAppleSignatureMismatch", Message: "A signature mismatch has occurred.", Category: CategoryCSSM},
+	-67592: {Name: "errSecAppleInvalidKeyStartDate", Message: "The specified key has a start date in the future.", Category: CategoryCSSM},
+	-67593: {Name: "errSecAppleInvalidKeyEndDate", Message: "The specified key has passed its expiration date.", Category: CategoryCSSM},
+	-67594: {Name: "errSecConversionError", Message: "A conversion error has occurred.", Category: CategoryCSSM},
+	-67595: {Name: "errSecAppleSSLv2Rollback", Message: "A SSLv2 rollback error has occurred.", Category: CategoryTrust},
+	-67596: {Name: "errSecQuotaExceeded", Message: "The quota was exceeded.", Category: CategoryCSSM},
+	-67597: {Name: "errSecFileTooBig", Message: "The file is too big.", Category: CategoryCSSM},
+	-67598: {Name: "errSecInvalidDatabaseBlob", Message: "The specified database has an invalid blob.", Category: CategoryCSSM},
+	-67599: {Name: "errSecInvalidKeyBlob", Message: "The specified database has an invalid key blob.", Category: CategoryCSSM},
+	-67600: {Name: "errSecIncompatibleDatabaseBlob", Message: "The specified database has an incompatible blob.", Category: CategoryCSSM},
+	-67601: {Name: "errSecIncompatibleKeyBlob", Message: "The specified database has an incompatible key blob.", Category: CategoryCSSM},
+	-67602: {Name: "errSecHostNameMismatch", Message: "A host name mismatch has occurred.", Category: CategoryTrust},
+	-67603: {Name: "errSecUnknownCriticalExtensionFlag", Message: "There is an unknown critical extension flag.", Category: CategoryTrust},
+	-67604: {Name: "errSecNoBasicConstraints", Message: "No basic constraints were found.", Category: CategoryTrust},
+	-67605: {Name: "errSecNoBasicConstraintsCA", Message: "No basic CA constraints were found.", Category: CategoryTrust},
+	-67606: {Name: "errSecInvalidAuthorityKeyID", Message: "The authority key ID is not valid.", Category: CategoryTrust},
+	-67607: {Name: "errSecInvalidSubjectKeyID", Message: "The subject key ID is not valid.", Category: CategoryTrust},
+	-67608: {Name: "errSecInvalidKeyUsageForPolicy", Message: "The key usage is not valid for the specified policy.", Category: CategoryTrust},
+	-67609: {Name: "errSecInvalidExtendedKeyUsage", Message: "The extended key usage is not valid.", Category: CategoryTrust},
+	-67610: {Name: "errSecInvalidIDLinkage", Message: "The ID linkage is not valid.", Category: CategoryTrust},
+	-67611: {Name: "errSecPathLengthConstraintExceeded", Message: "The path length constraint was exceeded.", Category: CategoryTrust},
+	-67612: {Name: "errSecInvalidRoot", Message: "The root or anchor certificate is not valid.", Category: CategoryTrust},
+	-67613: {Name: "errSecCRLExpired", Message: "The CRL has expired.", Category: CategoryTrust},
+	-67614: {Name: "errSecCRLNotValidYet", Message: "The CRL is not yet valid.", Category: CategoryTrust},
+	-67615: {Name: "errSecCRLNotFound", Message: "The CRL was not found.", Category: CategoryTrust},
+	-67616: {Name: "errSecCRLServerDown", Message: "The CRL server is down.", Category: CategoryTrust},
+	-67617: {Name: "errSecCRLBadURI", Message: "The CRL has a bad Uniform Resource Identifier.", Category: CategoryTrust},
+	-67618: {Name: "errSecUnknownCertExtension", Message: "An unknown certificate extension was encountered.", Category: CategoryTrust},
+	-67619: {Name: "errSecUnknownCRLExtension", Message: "An unknown CRL extension was encountered.", Category: CategoryTrust},
+	-67620: {Name: "errSecCRLNotTrusted", Message: "The CRL is not trusted.", Category: CategoryTrust},
+	-67621: {Name: "errSecCRLPolicyFailed", Message: "The CRL policy failed.", Category: CategoryTrust},
+	-67622: {Name: "errSecIDPFailure", Message: "The issuing distribution point was not valid.", Category: CategoryTrust},
+	-67623: {Name: "errSecSMIMEEmailAddressesNotFound", Message: "An email address mismatch was encountered.", Category: CategoryTrust},
+	-67624: {Name: "errSecSMIMEBadExtendedKeyUsage", Message: "The appropriate extended key usage for SMIME was not found.", Category: CategoryTrust},
+	-67625: {Name: "errSecSMIMEBadKeyUsage", Message: "The key usage is not compatible with SMIME.", Category: CategoryTrust},
+	-67626: {Name: "errSecSMIMEKeyUsageNotCritical", Message: "The key usage extension is not marked as critical.", Category: CategoryTrust},
+	-67627: {Name: "errSecSMIMENoEmailAddress", Message: "No email address was found in the certificate.", Category: CategoryTrust},
+	-67628: {Name: "errSecSMIMESubjAltNameNotCritical", Message: "The subject alternative name extension is not marked as critical.", Category: CategoryTrust},
+	-67629: {Name: "errSecSSLBadExtendedKeyUsage", Message: "The appropriate extended key usage for SSL was not found.", Category: CategoryTrust},
+	-67630: {Name: "errSecOCSPBadResponse", Message: "The OCSP response was incorrect or could not be parsed.", Category: CategoryTrust},
+	-67631: {Name: "errSecOCSPBadRequest", Message: "The OCSP request was incorrect or could not be parsed.", Category: CategoryTrust},
+	-67632: {Name: "errSecOCSPUnavailable", Message: "OCSP service is unavailable.", Category: CategoryTrust},
+	-67633: {Name: "errSecOCSPStatusUnrecognized", Message: "The OCSP server did not recognize this certificate.", Category: CategoryTrust},
+	-67634: {Name: "errSecEndOfData", Message: "An end-of-data was detected.", Category: CategoryCSSM},
+	-67635: {Name: "errSecIncompleteCertRevocationCheck", Message: "An incomplete certificate revocation check occurred.", Category: CategoryTrust},
+	-67636: {Name: "errSecNetworkFailure", Message: "A network failure occurred.", Category: CategoryCSSM},
+	-67637: {Name: "errSecOCSPNotTrustedToAnchor", Message: "The OCSP response was not trusted to a root or anchor certificate.", Category: CategoryTrust},
+	-67638: {Name: "errSecRecordModified", Message: "The record was modified.", Category: CategoryCSSM},
+	-67639: {Name: "errSecOCSPSignatureError", Message: "The OCSP response had an invalid signature.", Category: CategoryTrust},
+	-67640: {Name: "errSecOCSPNoSigner", Message: "The OCSP response had no signer.", Category: CategoryTrust},
+	-67641: {Name: "errSecOCSPResponderMalformedReq", Message: "The OCSP responder was given a malformed request.", Category: CategoryTrust},
+	-67642: {Name: "errSecOCSPResponderInternalError", Message: "The OCSP responder encountered an internal error.", Category: CategoryTrust},
+	-67643: {Name: "errSecOCSPResponderTryLater", Message: "The OCSP responder is busy, try again later.", Category: CategoryTrust},
+	-67644: {Name: "errSecOCSPResponderSignatureRequired", Message: "The OCSP responder requires a signature.", Category: CategoryTrust},
+	-67645: {Name: "errSecOCSPResponderUnauthorized", Message: "The OCSP responder rejected this request as unauthorized.", Category: CategoryTrust},
+	-67646: {Name: "errSecOCSPResponseNonceMismatch", Message: "The OCSP response nonce did not match the request.", Category: CategoryTrust},
+	-67647: {Name: "errSecCodeSigningBadCertChainLength", Message: "Code signing encountered an incorrect certificate chain length.", Category: CategoryTrust},
+	-67648: {Name: "errSecCodeSigningNoBasicConstraints", Message: "Code signing found no basic constraints.", Category: CategoryTrust},
+	-67649: {Name: "errSecCodeSigningBadPathLengthConstraint", Message: "Code signing encountered an incorrect path length constraint.", Category: CategoryTrust},
+	-67650: {Name: "errSecCodeSigningNoExtendedKeyUsage", Message: "Code signing found no extended key usage.", Category: CategoryTrust},
+	-67651: {Name: "errSecCodeSigningDevelopment", Message: "Code signing indicated use of a development-only certificate.", Category: CategoryTrust},
+	-67652: {Name: "errSecResourceSignBadCertChainLength", Message: "Resource signing has encountered an incorrect certificate chain length.", Category: CategoryTrust},
+	-67653: {Name: "errSecResourceSignBadExtKeyUsage", Message: "Resource signing has encountered an error in the extended key usage.", Category: CategoryTrust},
+	-67654: {Name: "errSecTrustSettingDeny", Message: "The trust setting for this policy was set to Deny.", Category: CategoryTrust},
+	-67655: {Name: "errSecInvalidSubjectName", Message: "An invalid certificate subject name was encountered.", Category: CategoryTrust},
+	-67656: {Name: "errSecUnknownQualifiedCertStatement", Message: "An unknown qualified certificate statement was encountered.", Category: CategoryTrust},
+	-67657: {Name: "errSecMobileMeRequestQueued", Message: "The MobileMe request will be sent during the next connection.", Category: CategoryTrust},
+	-67658: {Name: "errSecMobileMeRequestRedirected", Message: "The MobileMe request was redirected.", Category: CategoryTrust},
+	-67659: {Name: "errSecMobileMeServerError", Message: "A MobileMe server error occurred.", Category: CategoryTrust},
+	-67660: {Name: "errSecMobileMeServerNotAvailable", Message: "The MobileMe server is not available.", Category: CategoryTrust},
+	-67661: {Name: "errSecMobileMeServerAlreadyExists", Message: "The MobileMe server reported that the item already exists.", Category: CategoryTrust},
+	-67662: {Name: "errSecMobileMeServerServiceErr", Message: "A MobileMe service error has occurred.", Category: CategoryTrust},
+	-67663: {Name: "errSecMobileMeRequestAlreadyPending", Message: "A MobileMe request is already pending.", Category: CategoryTrust},
+	-67664: {Name: "errSecMobileMeNoRequestPending", Message: "MobileMe has no request pending.", Category: CategoryTrust},
+	-67665: {Name: "errSecMobileMeCSRVerifyFailure", Message: "A MobileMe CSR verification failure has occurred.", Category: CategoryTrust},
+	-67666: {Name: "errSecMobileMeFailedConsistencyCheck", Message: "MobileMe has found a failed consistency check.", Category: CategoryTrust},
+	-67667: {Name: "errSecNotInitialized", Message: "A function was called without initializing CSSM.", Category: CategoryCSSM},
+	-67668: {Name: "errSecInvalidHandleUsage", Message: "The CSSM handle does not match with the service type.", Category: CategoryCSSM},
+	-67669: {Name: "errSecPVCReferentNotFound", Message: "A reference to the calling module was not found in the list of authorized callers.", Category: CategoryCSSM},
+	-67670: {Name: "errSecFunctionIntegrityFail", Message: "A function address was not within the verified module.", Category: CategoryCSSM},
+	-67671: {Name: "errSecInternalError", Message: "An internal error has occurred.", Category: CategoryCSSM},
+	-67672: {Name: "errSecMemoryError", Message: "A memory error has occurred.", Category: CategoryCSSM},
+	-67673: {Name: "errSecInvalidData", Message: "Invalid data was encountered.", Category: CategoryCSSM},
+	-67674: {Name: "errSecMDSError", Message: "A Module Directory Service error has occurred.", Category: CategoryCSSM},
+	-67675: {Name: "errSecInvalidPointer", Message: "An invalid pointer was encountered.", Category: CategoryCSSM},
+	-67676: {Name: "errSecSelfCheckFailed", Message: "Self-check has failed.", Category: CategoryCSSM},
+	-67677: {Name: "errSecFunctionFailed", Message: "A function has failed.", Category: CategoryCSSM},
+	-67678: {Name: "errSecModuleManifestVerifyFailed", Message: "A module manifest verification failure has occurred.", Category: CategoryCSSM},
+	-67679: {Name: "errSecInvalidGUID", Message: "An invalid GUID was encountered.", Category: CategoryCSSM},
+	-67680: {Name: "errSecInvalidHandle", Message: "An invalid handle was encountered.", Category: CategoryCSSM},
+	-67681: {Name: "errSecInvalidDBList", Message: "An invalid DB list was encountered.", Category: CategoryCSSM},
+	-67682: {Name: "errSecInvalidPassthroughID", Message: "An invalid passthrough ID was encountered.", Category: CategoryCSSM},
+	-67683: {Name: "errSecInvalidNetworkAddress", Message: "An invalid network address was encountered.", Category: CategoryCSSM},
+	-67684: {Name: "errSecCRLAlreadySigned", Message: "The certificate revocation list is already signed.", Category: CategoryTrust},
+	-67685: {Name: "errSecInvalidNumberOfFields", Message: "An invalid number of fields were encountered.", Category: CategoryCSSM},
+	-67686: {Name: "errSecVerificationFailure", Message: "A verification failure occurred.", Category: CategoryCSSM},
+	-67687: {Name: "errSecUnknownTag", Message: "An unknown tag was encountered.", Category: CategoryCSSM},
+	-67688: {Name: "errSecInvalidSignature", Message: "An invalid signature was encountered.", Category: CategoryCSSM},
+	-67689: {Name: "errSecInvalidName", Message: "An invalid name was encountered.", Category: CategoryCSSM},
+	-67690: {Name: "errSecInvalidCertificateRef", Message: "An invalid certificate reference was encountered.", Category: CategoryTrust},
+	-67691: {Name: "errSecInvalidCertificateGroup", Message: "An invalid certificate group was encountered.", Category: CategoryTrust},
+	-67692: {Name: "errSecTagNotFound", Message: "The specified tag was not found.", Category: CategoryCSSM},
+	-67693: {Name: "errSecInvalidQuery", Message: "The specified query was not valid.", Category: CategoryCSSM},
+	-67694: {Name: "errSecInvalidValue", Message: "An invalid value was detected.", Category: CategoryCSSM},
+	-67695: {Name: "errSecCallbackFailed", Message: "A callback has failed.", Category: CategoryCSSM},
+	-67696: {Name: "errSecACLDeleteFailed", Message: "An ACL delete operation has failed.", Category: CategoryCSSM},
+	-67697: {Name: "errSecACLReplaceFailed", Message: "An ACL replace operation has failed.", Category: CategoryCSSM},
+	-67698: {Name: "errSecACLAddFailed", Message: "An ACL add operation has failed.", Category: CategoryCSSM},
+	-67699: {Name: "errSecACLChangeFailed", Message: "An ACL change operation has failed.", Category: CategoryCSSM},
+	-67700: {Name: "errSecInvalidAccessCredentials", Message: "Invalid access credentials were encountered.", Category: CategoryCSSM},
+	-67701: {Name: "errSecInvalidRecord", Message: "An invalid record was encountered.", Category: CategoryCSSM},
+	-67702: {Name: "errSecInvalidACL", Message: "An invalid ACL was encountered.", Category: CategoryCSSM},
+	-67703: {Name: "errSecInvalidSampleValue", Message: "An invalid sample value was encountered.", Category: CategoryCSSM},
+	-67704: {Name: "errSecIncompatibleVersion", Message: "An incompatible version was encountered.", Category: CategoryCSSM},
+	-67705: {Name: "errSecPrivilegeNotGranted", Message: "The privilege was not granted.", Category: CategoryCSSM},
+	-67706: {Name: "errSecInvalidScope", Message: "An invalid scope was encountered.", Category: CategoryCSSM},
+	-67707: {Name: "errSecPVCAlreadyConfigured", Message: "The PVC is already configured.", Category: CategoryCSSM},
+	-67708: {Name: "errSecInvalidPVC", Message: "An invalid PVC was encountered.", Category: CategoryCSSM},
+	-67709: {Name: "errSecEMMLoadFailed", Message: "The EMM load has failed.", Category: CategoryCSSM},
+	-67710: {Name: "errSecEMMUnloadFailed", Message: "The EMM unload has failed.", Category: CategoryCSSM},
+	-67711: {Name: "errSecAddinLoadFailed", Message: "The add-in load operation has failed.", Category: CategoryCSSM},
+	-67712: {Name: "errSecInvalidKeyRef", Message: "An invalid key was encountered.", Category: CategoryCSSM},
+	-67713: {Name: "errSecInvalidKeyHierarchy", Message: "An invalid key hierarchy was encountered.", Category: CategoryCSSM},
+	-67714: {Name: "errSecAddinUnloadFailed", Message: "The add-in unload operation has failed.", Category: CategoryCSSM},
+	-67715: {Name: "errSecLibraryReferenceNotFound", Message: "A library reference was not found.", Category: CategoryCSSM},
+	-67716: {Name: "errSecInvalidAddinFunctionTable", Message: "An invalid add-in function table was encountered.", Category: CategoryCSSM},
+	-67717: {Name: "errSecInvalidServiceMask", Message: "An invalid service mask was encountered.", Category: CategoryCSSM},
+	-67718: {Name: "errSecModuleNotLoaded", Message: "A module was not loaded.", Category: CategoryCSSM},
+	-67719: {Name: "errSecInvalidSubServiceID", Message: "An invalid subservice ID was encountered.", Category: CategoryCSSM},
+	-67720: {Name: "errSecAttributeNotInContext", Message: "An attribute was not in the context.", Category: CategoryCSSM},
+	-67721: {Name: "errSecModuleManagerInitializeFailed", Message: "A module failed to initialize.", Category: CategoryCSSM},
+	-67722: {Name: "errSecModuleManagerNotFound", Message: "A module was not found.", Category: CategoryCSSM},
+	-67723: {Name: "errSecEventNotificationCallbackNotFound", Message: "An event notification callback was not found.", Category: CategoryCSSM},
+	-67724: {Name: "errSecInputLengthError", Message: "An input length error was encountered.", Category: CategoryCSSM},
+	-67725: {Name: "errSecOutputLengthError", Message: "An output length error was encountered.", Category: CategoryCSSM},
+	-67726: {Name: "errSecPrivilegeNotSupported", Message: "The privilege is not supported.", Category: CategoryCSSM},
+	-67727: {Name: "errSecDeviceError", Message: "A device error was encountered.", Category: CategoryCSSM},
+	-67728: {Name: "errSecAttachHandleBusy", Message: "The CSP handle was busy.", Category: CategoryCSSM},
+	-67729: {Name: "errSecNotLoggedIn", Message: "You are not logged in.", Category: CategoryCSSM},
+	-67730: {Name: "errSecAlgorithmMismatch", Message: "An algorithm mismatch was encountered.", Category: CategoryCSSM},
+	-67731: {Name: "errSecKeyUsageIncorrect", Message: "The key usage is incorrect.", Category: CategoryTrust},
+	-67732: {Name: "errSecKeyBlobTypeIncorrect", Message: "The key blob type is incorrect.", Category: CategoryCSSM},
+	-67733: {Name: "errSecKeyHeaderInconsistent", Message: "The key header is inconsistent.", Category: CategoryCSSM},
+	-67734: {Name: "errSecUnsupportedKeyFormat", Message: "The key header format is not supported.", Category: CategoryCSSM},
+	-67735: {Name: "errSecUnsupportedKeySize", Message: "The key size is not supported.", Category: CategoryCSSM},
+	-67736: {Name: "errSecInvalidKeyUsageMask", Message: "The key usage mask is not valid.", Category: CategoryTrust},
+	-67737: {Name: "errSecUnsupportedKeyUsageMask", Message: "The key usage mask is not supported.", Category: CategoryTrust},
+	-67738: {Name: "errSecInvalidKeyAttributeMask", Message: "The key attribute mask is not valid.", Category: CategoryCSSM},
+	-67739: {Name: "errSecUnsupportedKeyAttributeMask", Message: "The key attribute mask is not supported.", Category: CategoryCSSM},
+	-67740: {Name: "errSecInvalidKeyLabel", Message: "The key label is not valid.", Category: CategoryCSSM},
+	-67741: {Name: "errSecUnsupportedKeyLabel", Message: "The key label is not supported.", Category: CategoryCSSM},
+	-67742: {Name: "errSecInvalidKeyFormat", Message: "The key format is not valid.", Category: CategoryCSSM},
+	-67743: {Name: "errSecUnsupportedVectorOfBuffers", Message: "The vector of buffers is not supported.", Category: CategoryCSSM},
+	-67744: {Name: "errSecInvalidInputVector", Message: "The input vector is not valid.", Category: CategoryCSSM},
+	-67745: {Name: "errSecInvalidOutputVector", Message: "The output vector is not valid.", Category: CategoryCSSM},
+	-67746: {Name: "errSecInvalidContext", Message: "An invalid context was encountered.", Category: CategoryCSSM},
+	-67747: {Name: "errSecInvalidAlgorithm", Message: "An invalid algorithm was encountered.", Category: CategoryCSSM},
+	-67748: {Name: "errSecInvalidAttributeKey", Message: "A key attribute was not valid.", Category: CategoryCSSM},
+	-67749: {Name: "errSecMissingAttributeKey", Message: "A key attribute was missing.", Category: CategoryCSSM},
+	-67750: {Name: "errSecInvalidAttributeInitVector", Message: "An init vector attribute was not valid.", Category: CategoryCSSM},
+	-67751: {Name: "errSecMissingAttributeInitVector", Message: "An init vector attribute was missing.", Category: CategoryCSSM},
+	-67752: {Name: "errSecInvalidAttributeSalt", Message: "A salt attribute was not valid.", Category: CategoryCSSM},
+	-67753: {Name: "errSecMissingAttributeSalt", Message: "A salt attribute was missing.", Category: CategoryCSSM},
+	-67754: {Name: "errSecInvalidAttributePadding", Message: "A padding attribute was not valid.", Category: CategoryCSSM},
+	-67755: {Name: "errSecMissingAttributePadding", Message: "A padding attribute was missing.", Category: CategoryCSSM},
+	-67756: {Name: "errSecInvalidAttributeRandom", Message: "A random number attribute was not valid.", Category: CategoryCSSM},
+	-67757: {Name: "errSecMissingAttributeRandom", Message: "A random number attribute was missing.", Category: CategoryCSSM},
+	-67758: {Name: "errSecInvalidAttributeSeed", Message: "A seed attribute was not valid.", Category: CategoryCSSM},
+	-67759: {Name: "errSecMissingAttributeSeed", Message: "A seed attribute was missing.", Category: CategoryCSSM},
+	-67760: {Name: "errSecInvalidAttributePassphrase", Message: "A passphrase attribute was not valid.", Category: CategoryCSSM},
+	-67761: {Name: "errSecMissingAttributePassphrase", Message: "A passphrase attribute was missing.", Category: CategoryCSSM},
+	-67762: {Name: "errSecInvalidAttributeKeyLength", Message: "A key length attribute was not valid.", Category: CategoryCSSM},
+	-67763: {Name: "errSecMissingAttributeKeyLength", Message: "A key length attribute was missing.", Category: CategoryCSSM},
+	-67764: {Name: "errSecInvalidAttributeBlockSize", Message: "A block size attribute was not valid.", Category: CategoryCSSM},
+	-67765: {Name: "errSecMissingAttributeBlockSize", Message: "A block size attribute was missing.", Category: CategoryCSSM},
+	-67766: {Name: "errSecInvalidAttributeOutputSize", Message: "An output size attribute was not valid.", Category: CategoryCSSM},
+	-67767: {Name: "errSecMissingAttributeOutputSize", Message: "An output size attribute was missing.", Category: CategoryCSSM},
+	-67768: {Name: "errSecInvalidAttributeRounds", Message: "The number of rounds attribute was not valid.", Category: CategoryCSSM},
+	-67769: {Name: "errSecMissingAttributeRounds", Message: "The number of rounds attribute was missing.", Category: CategoryCSSM},
+	-67770: {Name: "errSecInvalidAlgorithmParms", Message: "An algorithm parameters attribute was not valid.", Category: CategoryCSSM},
+	-67771: {Name: "errSecMissingAlgorithmParms", Message: "An algorithm parameters attribute was missing.", Category: CategoryCSSM},
+	-67772: {Name: "errSecInvalidAttributeLabel", Message: "A label attribute was not valid.", Category: CategoryCSSM},
+	-67773: {Name: "errSecMissingAttributeLabel", Message: "A label attribute was missing.", Category: CategoryCSSM},
+	-67774: {Name: "errSecInvalidAttributeKeyType", Message: "A key type attribute was not valid.", Category: CategoryCSSM},
+	-67775: {Name: "errSecMissingAttributeKeyType", Message: "A key type attribute was missing.", Category: CategoryCSSM},
+	-67776: {Name: "errSecInvalidAttributeMode", Message: "A mode attribute was not valid.", Category: CategoryCSSM},
+	-67777: {Name: "errSecMissingAttributeMode", Message: "A mode attribute was missing.", Category: CategoryCSSM},
+	-67778: {Name: "errSecInvalidAttributeEffectiveBits", Message: "An effective bits attribute was not valid.", Category: CategoryCSSM},
+	-67779: {Name: "errSecMissingAttributeEffectiveBits", Message: "An effective bits attribute was missing.", Category: CategoryCSSM},
+	-67780: {Name: "errSecInvalidAttributeStartDate", Message: "A start date attribute was not valid.", Category: CategoryCSSM},
+	-67781: {Name: "errSecMissingAttributeStartDate", Message: "A start date attribute was missing.", Category: CategoryCSSM},
+	-67782: {Name: "errSecInvalidAttributeEndDate", Message: "An end date attribute was not valid.", Category: CategoryCSSM},
+	-67783: {Name: "errSecMissingAttributeEndDate", Message: "An end date attribute was missing.", Category: CategoryCSSM},
+	-67784: {Name: "errSecInvalidAttributeVersion", Message: "A version attribute was not valid.", Category: CategoryCSSM},
+	-67785: {Name: "errSecMissingAttributeVersion", Message: "A version attribute was missing.", Category: CategoryCSSM},
+	-67786: {Name: "errSecInvalidAttributePrime", Message: "A prime attribute was not valid.", Category: CategoryCSSM},
+	-67787: {Name: "errSecMissingAttributePrime", Message: "A prime attribute was missing.", Category: CategoryCSSM},
+	-67788: {Name: "errSecInvalidAttributeBase", Message: "A base attribute was not valid.", Category: CategoryCSSM},
+	-67789: {Name: "errSecMissingAttributeBase", Message: "A base attribute was missing.", Category: CategoryCSSM},
+	-67790: {Name: "errSecInvalidAttributeSubprime", Message: "A subprime attribute was not valid.", Category: CategoryCSSM},
+	-67791: {Name: "errSecMissingAttributeSubprime", Message: "A subprime attribute was missing.", Category: CategoryCSSM},
+	-67792: {Name: "errSecInvalidAttributeIterationCount", Message: "An iteration count attribute was not valid.", Category: CategoryCSSM},
+	-67793: {Name: "errSecMissingAttributeIterationCount", Message: "An iteration count attribute was missing.", Category: CategoryCSSM},
+	-67794: {Name: "errSecInvalidAttributeDLDBHandle", Message: "A database handle attribute was not valid.", Category: CategoryCSSM},
+	-67795: {Name: "errSecMissingAttributeDLDBHandle", Message: "A database handle attribute was missing.", Category: CategoryCSSM},
+	-67796: {Name: "errSecInvalidAttributeAccessCredentials", Message: "An access credentials attribute was not valid.", Category: CategoryCSSM},
+	-67797: {Name: "errSecMissingAttributeAccessCredentials", Message: "An access credentials attribute was missing.", Category: CategoryCSSM},
+	-67798: {Name: "errSecInvalidAttributePublicKeyFormat", Message: "A public key format attribute was not valid.", Category: CategoryCSSM},
+	-67799: {Name: "errSecMissingAttributePublicKeyFormat", Message: "A public key format attribute was missing.", Category: CategoryCSSM},
+	-67800: {Name: "errSecInvalidAttributePrivateKeyFormat", Message: "A private key format attribute was not valid.", Category: CategoryCSSM},
+	-67801: {Name: "errSecMissingAttributePrivateKeyFormat", Message: "A private key format attribute was missing.", Category: CategoryCSSM},
+	-67802: {Name: "errSecInvalidAttributeSymmetricKeyFormat", Message: "A symmetric key format attribute was not valid.", Category: CategoryCSSM},
+	-67803: {Name: "errSecMissingAttributeSymmetricKeyFormat", Message: "A symmetric key format attribute was missing.", Category: CategoryCSSM},
+	-67804: {Name: "errSecInvalidAttributeWrappedKeyFormat", Message: "A wrapped key format attribute was not valid.", Category: CategoryCSSM},
+	-67805: {Name: "errSecMissingAttributeWrappedKeyFormat", Message: "A wrapped key format attribute was missing.", Category: CategoryCSSM},
+	-67806: {Name: "errSecStagedOperationInProgress", Message: "A staged operation is in progress.", Category: CategoryCSSM},
+	-67807: {Name: "errSecStagedOperationNotStarted", Message: "A staged operation was not started.", Category: CategoryCSSM},
+	-67808: {Name: "errSecVerifyFailed", Message: "A cryptographic verification failure has occurred.", Category: CategoryCSSM},
+	-67809: {Name: "errSecQuerySizeUnknown", Message: "The query size is unknown.", Category: CategoryCSSM},
+	-67810: {Name: "errSecBlockSizeMismatch", Message: "A block size mismatch occurred.", Category: CategoryCSSM},
+	-67811: {Name: "errSecPublicKeyInconsistent", Message: "The public key was inconsistent.", Category: CategoryCSSM},
+	-67812: {Name: "errSecDeviceVerifyFailed", Message: "A device verification failure has occurred.", Category: CategoryCSSM},
+	-67813: {Name: "errSecInvalidLoginName", Message: "An invalid login name was detected.", Category: CategoryCSSM},
+	-67814: {Name: "errSecAlreadyLoggedIn", Message: "The user is already logged in.", Category: CategoryCSSM},
+	-67815: {Name: "errSecInvalidDigestAlgorithm", Message: "An invalid digest algorithm was detected.", Category: CategoryCSSM},
+	-67816: {Name: "errSecInvalidCRLGroup", Message: "An invalid CRL group was detected.", Category: CategoryTrust},
+	-67817: {Name: "errSecCertificateCannotOperate", Message: "The certificate cannot operate.", Category: CategoryTrust},
+	-67818: {Name: "errSecCertificateExpired", Message: "An expired certificate was detected.", Category: CategoryTrust},
+	-67819: {Name: "errSecCertificateNotValidYet", Message: "The certificate is not yet valid.", Category: CategoryTrust},
+	-67820: {Name: "errSecCertificateRevoked", Message: "The certificate was revoked.", Category: CategoryTrust},
+	-67821: {Name: "errSecCertificateSuspended", Message: "The certificate was suspended.", Category: CategoryTrust},
+	-67822: {Name: "errSecInsufficientCredentials", Message: "Insufficient credentials were detected.", Category: CategoryCSSM},
+	-67823: {Name: "errSecInvalidAction", Message: "The action was not valid.", Category: CategoryCSSM},
+	-67824: {Name: "errSecInvalidAuthority", Message: "The authority was not valid.", Category: CategoryCSSM},
+	-67825: {Name: "errSecVerifyActionFailed", Message: "A verify action has failed.", Category: CategoryCSSM},
+	-67826: {Name: "errSecInvalidCertAuthority", Message: "The certificate authority was not valid.", Category: CategoryTrust},
+	-67827: {Name: "errSecInvalidCRLAuthority", Message: "The CRL authority was not valid.", Category: CategoryTrust},
+	-67828: {Name: "errSecInvalidCRLEncoding", Message: "The CRL encoding was not valid.", Category: CategoryTrust},
+	-67829: {Name: "errSecInvalidCRLType", Message: "The CRL type was not valid.", Category: CategoryTrust},
+	-67830: {Name: "errSecInvalidCRL", Message: "The CRL was not valid.", Category: CategoryTrust},
+	-67831: {Name: "errSecInvalidFormType", Message: "The form type was not valid.", Category: CategoryCSSM},
+	-67832: {Name: "errSecInvalidID", Message: "The ID was not valid.", Category: CategoryCSSM},
+	-67833: {Name: "errSecInvalidIdentifier", Message: "The identifier was not valid.", Category: CategoryCSSM},
+	-67834: {Name: "errSecInvalidIndex", Message: "The index was not valid.", Category: CategoryCSSM},
+	-67835: {Name: "errSecInvalidPolicyIdentifiers", Message: "The policy identifiers are not valid.", Category: CategoryCSSM},
+	-67836: {Name: "errSecInvalidTimeString", Message: "The time specified was not valid.", Category: CategoryCSSM},
+	-67837: {Name: "errSecInvalidReason", Message: "The trust policy reason was not valid.", Category: CategoryCSSM},
+	-67838: {Name: "errSecInvalidRequestInputs", Message: "The request inputs are not valid.", Category: CategoryCSSM},
+	-67839: {Name: "errSecInvalidResponseVector", Message: "The response vector was not valid.", Category: CategoryCSSM},
+	-67840: {Name: "errSecInvalidStopOnPolicy", Message: "The stop-on policy was not valid.", Category: CategoryCSSM},
+	-67841: {Name: "errSecInvalidTuple", Message: "The tuple was not valid.", Category: CategoryCSSM},
+	-67842: {Name: "errSecMultipleValuesUnsupported", Message: "Multiple values are not supported.", Category: CategoryCSSM},
+	-67843: {Name: "errSecNotTrusted", Message: "The certificate was not trusted.", Category: CategoryTrust},
+	-67844: {Name: "errSecNoDefaultAuthority", Message: "No default authority was detected.", Category: CategoryCSSM},
+	-67845: {Name: "errSecRejectedForm", Message: "The trust policy had a rejected form.", Category: CategoryCSSM},
+	-67846: {Name: "errSecRequestLost", Message: "The request was lost.", Category: CategoryCSSM},
+	-67847: {Name: "errSecRequestRejected", Message: "The request was rejected.", Category: CategoryCSSM},
+	-67848: {Name: "errSecUnsupportedAddressType", Message: "The address type is not supported.", Category: CategoryCSSM},
+	-67849: {Name: "errSecUnsupportedService", Message: "The service is not supported.", Category: CategoryCSSM},
+	-67850: {Name: "errSecInvalidTupleGroup", Message: "The tuple group was not valid.", Category: CategoryCSSM},
+	-67851: {Name: "errSecInvalidBaseACLs", Message: "The base ACLs are not valid.", Category: CategoryCSSM},
+	-67852: {Name: "errSecInvalidTupleCredentials", Message: "The tuple credentials are not valid.", Category: CategoryCSSM},
+	-67853: {Name: "errSecInvalidEncoding", Message: "The encoding was not valid.", Category: CategoryCSSM},
+	-67854: {Name: "errSecInvalidValidityPeriod", Message: "The validity period was not valid.", Category: CategoryCSSM},
+	-67855: {Name: "errSecInvalidRequestor", Message: "The requestor was not valid.", Category: CategoryCSSM},
+	-67856: {Name: "errSecRequestDescriptor", Message: "The request descriptor was not valid.", Category: CategoryCSSM},
+	-67857: {Name: "errSecInvalidBundleInfo", Message: "The bundle information was not valid.", Category: CategoryCSSM},
+	-67858: {Name: "errSecInvalidCRLIndex", Message: "The CRL index was not valid.", Category: CategoryTrust},
+	-67859: {Name: "errSecNoFieldValues", Message: "No field values were detected.", Category: CategoryCSSM},
+	-67860: {Name: "errSecUnsupportedFieldFormat", Message: "The field format is not supported.", Category: CategoryCSSM},
+	-67861: {Name: "errSecUnsupportedIndexInfo", Message: "The index information is not supported.", Category: CategoryCSSM},
+	-67862: {Name: "errSecUnsupportedLocality", Message: "The locality is not supported.", Category: CategoryCSSM},
+	-67863: {Name: "errSecUnsupportedNumAttributes", Message: "The number of attributes is not supported.", Category: CategoryCSSM},
+	-67864: {Name: "errSecUnsupportedNumIndexes", Message: "The number of indexes is not supported.", Category: CategoryCSSM},
+	-67865: {Name: "errSecUnsupportedNumRecordTypes", Message: "The number of record types is not supported.", Category: CategoryCSSM},
+	-67866: {Name: "errSecFieldSpecifiedMultiple", Message: "Too many fields were specified.", Category: CategoryCSSM},
+	-67867: {Name: "errSecIncompatibleFieldFormat", Message: "The field format was incompatible.", Category: CategoryCSSM},
+	-67868: {Name: "errSecInvalidParsingModule", Message: "The parsing module was not valid.", Category: CategoryCSSM},
+	-67869: {Name: "errSecDatabaseLocked", Message: "The database is locked.", Category: CategoryCSSM},
+	-67870: {Name: "errSecDatastoreIsOpen", Message: "The data store is open.", Category: CategoryCSSM},
+	-67871: {Name: "errSecMissingValue", Message: "A missing value was detected.", Category: CategoryCSSM},
+	-67872: {Name: "errSecUnsupportedQueryLimits", Message: "The query limits are not supported.", Category: CategoryCSSM},
+	-67873: {Name: "errSecUnsupportedNumSelectionPreds", Message: "The number of selection predicates is not supported.", Category: CategoryCSSM},
+	-67874: {Name: "errSecUnsupportedOperator", Message: "The operator is not supported.", Category: CategoryCSSM},
+	-67875: {Name: "errSecInvalidDBLocation", Message: "The database location is not valid.", Category: CategoryCSSM},
+	-67876: {Name: "errSecInvalidAccessRequest", Message: "The access request is not valid.", Category: CategoryCSSM},
+	-67877: {Name: "errSecInvalidIndexInfo", Message: "The index information is not valid.", Category: CategoryCSSM},
+	-67878: {Name: "errSecInvalidNewOwner", Message: "The new owner is not valid.", Category: CategoryCSSM},
+	-67879: {Name: "errSecInvalidModifyMode", Message: "The modify mode is not valid.", Category: CategoryCSSM},
+	-67880: {Name: "errSecMissingRequiredExtension", Message: "A required certificate extension is missing.", Category: CategoryTrust},
+	-67881: {Name: "errSecExtendedKeyUsageNotCritical", Message: "The extended key usage extension was not marked critical.", Category: CategoryTrust},
+	-67882: {Name: "errSecTimestampMissing", Message: "A timestamp was expected but was not found.", Category: CategoryTrust},
+	-67883: {Name: "errSecTimestampInvalid", Message: "The timestamp was not valid.", Category: CategoryTrust},
+	-67884: {Name: "errSecTimestampNotTrusted", Message: "The timestamp was not trusted.", Category: CategoryTrust},
+	-67885: {Name: "errSecTimestampServiceNotAvailable", Message: "The timestamp service is not available.", Category: CategoryTrust},
+	-67886: {Name: "errSecTimestampBadAlg", Message: "An unrecognized or unsupported Algorithm Identifier in timestamp.", Category: CategoryTrust},
+	-67887: {Name: "errSecTimestampBadRequest", Message: "The timestamp transaction is not permitted or supported.", Category: CategoryTrust},
+	-67888: {Name: "errSecTimestampBadDataFormat", Message: "The timestamp data submitted has the wrong format.", Category: CategoryTrust},
+	-67889: {Name: "errSecTimestampTimeNotAvailable", Message: "The time source for the Timestamp Authority is not available.", Category: CategoryTrust},
+	-67890: {Name: "errSecTimestampUnacceptedPolicy", Message: "The requested policy is not supported by the Timestamp Authority.", Category: CategoryTrust},
+	-67891: {Name: "errSecTimestampUnacceptedExtension", Message: "The requested extension is not supported by the Timestamp Authority.", Category: CategoryTrust},
+	-67892: {Name: "errSecTimestampAddInfoNotAvailable", Message: "The additional information requested is not available.", Category: CategoryTrust},
+	-67893: {Name: "errSecTimestampSystemFailure", Message: "The timestamp request cannot be handled due to system failure.", Category: CategoryTrust},
+	-67894: {Name: "errSecSigningTimeMissing", Message: "A signing time was expected but was not found.", Category: CategoryTrust},
+	-67895: {Name: "errSecTimestampRejection", Message: "A timestamp transaction was rejected.", Category: CategoryTrust},
+	-67896: {Name: "errSecTimestampWaiting", Message: "A timestamp transaction is waiting.", Category: CategoryTrust},
+	-67897: {Name: "errSecTimestampRevocationWarning", Message: "A timestamp authority revocation warning was issued.", Category: CategoryTrust},
+	-67898: {Name: "errSecTimestampRevocationNotification", Message: "A timestamp authority revocation notification was issued.", Category: CategoryTrust},
+	-67899: {Name: "errSecCertificatePolicyNotAllowed", Message: "The requested policy is not allowed for this certificate.", Category: CategoryTrust},
+	-67900: {Name: "errSecCertificateNameNotAllowed", Message: "The requested name is not allowed for this certificate.", Category: CategoryTrust},
+	-67901: {Name: "errSecCertificateValidityPeriodTooLong", Message: "The validity period in the certificate exceeds the maximum allowed.", Category: CategoryTrust},
+	-67902: {Name: "errSecCertificateIsCA", Message: "The verified certificate is a CA rather than an end-entity", Category: CategoryTrust},
+	-67903: {Name: "errSecCertificateDuplicateExtension", Message: "The certificate contains multiple extensions with the same extension ID.", Category: CategoryTrust},
+}
@@ -0,0 +1,60 @@
+package applesecurity
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+// PersistentRef is a persistent reference to exactly one keychain
+// item, as returned by kSecReturnPersistentRef.
+//
+// Unlike labels and tags, which many items may share, a PersistentRef
+// identifies a single item and remains valid across processes and
+// reboots until the item is deleted. It is opaque, and can be stored
+// in configuration files through its text or binary encoding.
+//
+// See: https://developer.apple.com/documentation/security/ksecreturnpersistentref
+type PersistentRef struct {
+	data []byte
+}
+
+// IsZero reports whether r does not refer to an item.
+func (r PersistentRef) IsZero() bool {
+	return len(r.data) == 0
+}
+
+// Equal reports whether r and other refer to the same item.
+func (r PersistentRef) Equal(other PersistentRef) bool {
+	return string(r.data) == string(other.data)
+}
+
+// String returns the text encoding of the reference.
+func (r PersistentRef) String() string {
+	return base64.RawURLEncoding.EncodeToString(r.data)
+}
+
+// MarshalText encodes the reference as unpadded base64url.
+func (r PersistentRef) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a reference encoded by MarshalText.
+func (r *PersistentRef) UnmarshalText(text []byte) error {
+	data, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return errors.New("invalid persistent reference encoding")
+	}
+	r.data = data
+	return nil
+}
+
+// MarshalBinary returns the reference as returned by the keychain.
+func (r PersistentRef) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), r.data...), nil
+}
+
+// UnmarshalBinary sets the reference to data returned by MarshalBinary.
+func (r *PersistentRef) UnmarshalBinary(data []byte) error {
+	r.data = append([]byte(nil), data...)
+	return nil
+}
@@ -0,0 +1,167 @@
+package pkcs12
+
+import "errors"
+
+// maxBERDepth bounds the nesting accepted by berToDER.
+const maxBERDepth = 32
+
+var errBERTruncated = errors.New("pkcs12: BER data truncated")
+
+// berElement is a parsed BER tag-length-value.
+type berElement struct {
+	tag         []byte
+	constructed bool
+	content     []byte
+	children    []berElement
+}
+
+// berToDER re-encodes BER input as DER, so that it can be parsed with
+// encoding/asn1. Some writers (notably Java's keytool) emit indefinite
+// lengths and constructed OCTET STRINGs; both are normalised here.
+func berToDER(ber []byte) ([]byte, error) {
+	e, rest, err := parseBER(ber, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("pkcs12: trailing data after PFX")
+	}
+	return e.appendDER(nil), nil
+}
+
+func parseBER(b []byte, depth int) (berElement, []byte, error) {
+	if depth > maxBERDepth {
+		return berElement{}, nil, errors.New("pkcs12: BER data nested too deeply")
+	}
+	if len(b) < 2 {
+		return berElement{}, nil, errBERTruncated
+	}
+
+	e := berElement{constructed: b[0]&0x20 != 0}
+	i := 1
+	if b[0]&0x1f == 0x1f {
+		// high tag number form
+		for {
+			if i >= len(b) {
+				return berElement{}, nil, errBERTruncated
+			}
+			i++
+			if b[i-1]&0x80 == 0 {
+				break
+			}
+		}
+	}
+	e.tag = b[:i]
+
+	if i >= len(b) {
+		return berElement{}, nil, errBERTruncated
+	}
+	l := b[i]
+	i++
+
+	if l == 0x80 {
+		// indefinite length, terminated by an end-of-contents marker
+		if !e.constructed {
+			return berElement{}, nil, errors.New("pkcs12: indefinite length on primitive BER element")
+		}
+		rest := b[i:]
+		for {
+			if len(rest) < 2 {
+				return berElement{}, nil, errBERTruncated
+			}
+			if rest[0] == 0 && rest[1] == 0 {
+				return e, rest[2:], nil
+			}
+			child, r, err := parseBER(rest, depth+1)
+			if err != nil {
+				return berElement{}, nil, err
+			}
+			e.children = append(e.children, child)
+			rest = r
+		}
+	}
+
+	n := int(l)
+	if l&0x80 != 0 {
+		numBytes := int(l & 0x7f)
+		if numBytes > 4 {
+			return berElement{}, nil, errors.New("pkcs12: BER length too large")
+		}
+		n = 0
+		for k := 0; k < numBytes; k++ {
+			if i >= len(b) {
+				return berElement{}, nil, errBERTruncated
+			}
+			n = n<<8 | int(b[i])
+			i++
+		}
+	}
+	if n < 0 || n > len(b)-i {
+		return berElement{}, nil, errBERTruncated
+	}
+
+	content := b[i : i+n]
+	if !e.constructed {
+		e.content = content
+		return e, b[i+n:], nil
+	}
+	for len(content) > 0 {
+		child, r, err := parseBER(content, depth+1)
+		if err != nil {
+			return berElement{}, nil, err
+		}
+		e.children = append(e.children, child)
+		content = r
+	}
+	return e, b[i+n:], nil
+}
+
+// isConstructedOctetString reports whether e is the segmented
+// form of an OCTET STRING, which DER does not allow.
+func (e berElement) isConstructedOctetString() bool {
+	return len(e.tag) == 1 && e.tag[0] == 0x24
+}
+
+// octets joins the segments of an OCTET STRING.
+func (e berElement) octets() []byte {
+	if !e.constructed {
+		return e.content
+	}
+	var out []byte
+	for _, c := range e.children {
+		out = append(out, c.octets()...)
+	}
+	return out
+}
+
+func (e berElement) appendDER(out []byte) []byte {
+	if e.isConstructedOctetString() {
+		return appendTLV(out, []byte{0x04}, e.octets())
+	}
+	if !e.constructed {
+		return appendTLV(out, e.tag, e.content)
+	}
+	var body []byte
+	for _, c := range e.children {
+		body = c.appendDER(body)
+	}
+	return appendTLV(out, e.tag, body)
+}
+
+func appendTLV(out, tag, body []byte) []byte {
+	out = append(out, tag...)
+	n := len(body)
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	case n <= 0xffff:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	case n <= 0xffffff:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, body...)
+}
@@ -0,0 +1,41 @@
+package pkcs12
+
+import (
+	"errors"
+	"unicode/utf16"
+)
+
+// bmpString encodes s as a big-endian UTF-16 BMPString.
+func bmpString(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(u)+2)
+	for _, c := range u {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	return b
+}
+
+// bmpStringZeroTerminated encodes s as a null terminated BMPString,
+// which is how PKCS#12 passwords are fed to its key derivation function.
+//
+// See: https://datatracker.ietf.org/doc/html/rfc7292#appendix-B.1
+func bmpStringZeroTerminated(s string) []byte {
+	return append(bmpString(s), 0, 0)
+}
+
+// decodeBMPString decodes a big-endian UTF-16 string, dropping
+// a trailing null terminator if present.
+func decodeBMPString(b []byte) (string, error) {
+	if len(b)%2 != 0 {
+		return "", errors.New("pkcs12: odd-length BMP string")
+	}
+	if l := len(b); l >= 2 && b[l-1] == 0 && b[l-2] == 0 {
+		b = b[:l-2]
+	}
+
+	s := make([]uint16, 0, len(b)/2)
+	for i := 0; i < len(b); i += 2 {
+		s = append(s, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(s)), nil
+}
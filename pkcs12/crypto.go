@@ -0,0 +1,295 @@
+package pkcs12
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+var (
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd128BitRC2CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHAAnd40BitRC2CBC      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSHA1                  = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSHA256                = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHmacWithSHA384                = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHmacWithSHA512                = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC                     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+)
+
+// maxIterations bounds the work a file can ask us to do
+// before the password has been checked.
+const maxIterations = 10_000_000
+
+// pbeParams are the parameters of the PKCS#12 password based encryption schemes.
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+// pbes2Params are the parameters of PBES2 from RFC 8018 Appendix A.4.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the parameters of PBKDF2 from RFC 8018 Appendix A.2.
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// hashForDigest returns the hash function for a digest algorithm OID.
+func hashForDigest(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidSHA512):
+		return sha512.New, nil
+	}
+	return nil, NotImplementedError(fmt.Sprintf("digest algorithm %v is not supported", oid))
+}
+
+// hashForHMAC returns the hash function for a PBKDF2 PRF OID.
+// An absent PRF defaults to HMAC-SHA-1.
+func hashForHMAC(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHmacWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHmacWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHmacWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHmacWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, NotImplementedError(fmt.Sprintf("PBKDF2 PRF %v is not supported", oid))
+}
+
+// pbeCipher derives the block cipher and IV described by algorithm.
+//
+// password is the null terminated BMPString form of the password;
+// PBES2 is keyed with its UTF-8 form instead.
+func pbeCipher(algorithm pkix.AlgorithmIdentifier, password []byte) (cipher.Block, []byte, error) {
+	switch {
+	case algorithm.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC),
+		algorithm.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC),
+		algorithm.Algorithm.Equal(oidPBEWithSHAAnd40BitRC2CBC):
+
+		var params pbeParams
+		if err := unmarshalParams(algorithm, &params); err != nil {
+			return nil, nil, err
+		}
+		if params.Iterations < 1 || params.Iterations > maxIterations {
+			return nil, nil, fmt.Errorf("pkcs12: invalid iteration count %d", params.Iterations)
+		}
+
+		var keyLen int
+		switch {
+		case algorithm.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+			keyLen = 24
+		case algorithm.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC):
+			keyLen = 16
+		default:
+			keyLen = 5
+		}
+
+		key := pbkdf(sha1.New, params.Salt, password, params.Iterations, kdfKeyID, keyLen)
+		iv := pbkdf(sha1.New, params.Salt, password, params.Iterations, kdfIVID, 8)
+
+		if keyLen == 24 {
+			block, err := des.NewTripleDESCipher(key)
+			if err != nil {
+				return nil, nil, err
+			}
+			return block, iv, nil
+		}
+		return newRC2Cipher(key, keyLen*8), iv, nil
+
+	case algorithm.Algorithm.Equal(oidPBES2):
+		var params pbes2Params
+		if err := unmarshalParams(algorithm, &params); err != nil {
+			return nil, nil, err
+		}
+		if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+			return nil, nil, NotImplementedError(fmt.Sprintf("PBES2 key derivation function %v is not supported", params.KeyDerivationFunc.Algorithm))
+		}
+
+		var kdfParams pbkdf2Params
+		if err := unmarshalParams(params.KeyDerivationFunc, &kdfParams); err != nil {
+			return nil, nil, err
+		}
+		if kdfParams.Iterations < 1 || kdfParams.Iterations > maxIterations {
+			return nil, nil, fmt.Errorf("pkcs12: invalid iteration count %d", kdfParams.Iterations)
+		}
+		prf, err := hashForHMAC(kdfParams.PRF.Algorithm)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var keyLen int
+		switch {
+		case params.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+			keyLen = 16
+		case params.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+			keyLen = 24
+		case params.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+			keyLen = 32
+		default:
+			return nil, nil, NotImplementedError(fmt.Sprintf("PBES2 encryption scheme %v is not supported", params.EncryptionScheme.Algorithm))
+		}
+		if kdfParams.KeyLength != 0 && kdfParams.KeyLength != keyLen {
+			return nil, nil, errors.New("pkcs12: PBKDF2 key length does not match encryption scheme")
+		}
+
+		var iv []byte
+		if err := unmarshalParams(params.EncryptionScheme, &iv); err != nil {
+			return nil, nil, err
+		}
+		if len(iv) != aes.BlockSize {
+			return nil, nil, errors.New("pkcs12: invalid AES-CBC IV length")
+		}
+
+		utf8Password, err := decodeBMPString(password)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := pbkdf2(prf, []byte(utf8Password), kdfParams.Salt, kdfParams.Iterations, keyLen)
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return block, iv, nil
+	}
+
+	return nil, nil, NotImplementedError(fmt.Sprintf("encryption algorithm %v is not supported", algorithm.Algorithm))
+}
+
+func unmarshalParams(algorithm pkix.AlgorithmIdentifier, params interface{}) error {
+	rest, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, params)
+	if err != nil {
+		return fmt.Errorf("pkcs12: invalid parameters for %v: %w", algorithm.Algorithm, err)
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("pkcs12: trailing data after parameters for %v", algorithm.Algorithm)
+	}
+	return nil
+}
+
+// pbDecrypt decrypts and unpads data encrypted with a password based scheme.
+func pbDecrypt(algorithm pkix.AlgorithmIdentifier, encrypted, password []byte) ([]byte, error) {
+	block, iv, err := pbeCipher(algorithm, password)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := block.BlockSize()
+	if len(encrypted) == 0 || len(encrypted)%bs != 0 {
+		return nil, errors.New("pkcs12: encrypted data is not a multiple of the block size")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	// A bad password is the most likely cause of invalid padding.
+	padLen := int(decrypted[len(decrypted)-1])
+	if padLen == 0 || padLen > bs {
+		return nil, ErrIncorrectPassword
+	}
+	if !bytes.Equal(decrypted[len(decrypted)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, ErrIncorrectPassword
+	}
+	return decrypted[:len(decrypted)-padLen], nil
+}
+
+// pbEncrypt pads and encrypts data with a password based scheme.
+func pbEncrypt(algorithm pkix.AlgorithmIdentifier, plaintext, password []byte) ([]byte, error) {
+	block, iv, err := pbeCipher(algorithm, password)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := block.BlockSize()
+	padLen := bs - len(plaintext)%bs
+	padded := make([]byte, len(plaintext), len(plaintext)+padLen)
+	copy(padded, plaintext)
+	padded = append(padded, bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return padded, nil
+}
+
+// newPBEAlgorithm returns a randomly salted AlgorithmIdentifier for a
+// password based encryption scheme.
+func newPBEAlgorithm(rand io.Reader, scheme asn1.ObjectIdentifier, iterations int) (pkix.AlgorithmIdentifier, error) {
+	switch {
+	case scheme.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		salt := make([]byte, 8)
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		params, err := asn1.Marshal(pbeParams{Salt: salt, Iterations: iterations})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		return pkix.AlgorithmIdentifier{Algorithm: scheme, Parameters: asn1.RawValue{FullBytes: params}}, nil
+
+	case scheme.Equal(oidPBES2):
+		salt := make([]byte, 16)
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand, iv); err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+
+		kdfParams, err := asn1.Marshal(pbkdf2Params{
+			Salt:       salt,
+			Iterations: iterations,
+			PRF:        pkix.AlgorithmIdentifier{Algorithm: oidHmacWithSHA256, Parameters: asn1.NullRawValue},
+		})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		ivParams, err := asn1.Marshal(iv)
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		params, err := asn1.Marshal(pbes2Params{
+			KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+			EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+		})
+		if err != nil {
+			return pkix.AlgorithmIdentifier{}, err
+		}
+		return pkix.AlgorithmIdentifier{Algorithm: scheme, Parameters: asn1.RawValue{FullBytes: params}}, nil
+	}
+
+	return pkix.AlgorithmIdentifier{}, NotImplementedError(fmt.Sprintf("encryption algorithm %v is not supported", scheme))
+}
@@ -0,0 +1,8 @@
+// Package pkcs12 decodes and encodes PKCS#12 (.p12/.pfx) files.
+//
+// It is implemented in pure Go so that bundles can be produced and
+// inspected on any platform. The identity package uses it to import
+// bundles into, and export them from, the keychain.
+//
+// See: https://datatracker.ietf.org/doc/html/rfc7292
+package pkcs12
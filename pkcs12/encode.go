@@ -0,0 +1,266 @@
+package pkcs12
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"io"
+)
+
+// Encoder writes PKCS#12 files using a fixed set of algorithms.
+type Encoder struct {
+	macAlgorithm  asn1.ObjectIdentifier
+	certAlgorithm asn1.ObjectIdentifier
+	keyAlgorithm  asn1.ObjectIdentifier
+	macSaltLen    int
+	iterations    int
+}
+
+var (
+	// Modern encrypts with AES-256-CBC keyed by PBKDF2-HMAC-SHA-256
+	// and authenticates with HMAC-SHA-256, like OpenSSL 3.
+	Modern = &Encoder{
+		macAlgorithm:  oidSHA256,
+		certAlgorithm: oidPBES2,
+		keyAlgorithm:  oidPBES2,
+		macSaltLen:    16,
+		iterations:    2048,
+	}
+
+	// Legacy encrypts with 3DES and authenticates with HMAC-SHA-1.
+	// Use it for readers which predate PBES2, such as older
+	// releases of macOS and Windows.
+	Legacy = &Encoder{
+		macAlgorithm:  oidSHA1,
+		certAlgorithm: oidPBEWithSHAAnd3KeyTripleDESCBC,
+		keyAlgorithm:  oidPBEWithSHAAnd3KeyTripleDESCBC,
+		macSaltLen:    8,
+		iterations:    2048,
+	}
+)
+
+// Encode produces PKCS#12 data containing the bundle, protected by
+// password. It uses the Modern encoder.
+func Encode(rand io.Reader, bundle *Bundle, password string) ([]byte, error) {
+	return Modern.Encode(rand, bundle, password)
+}
+
+// Encode produces PKCS#12 data containing the bundle, protected by password.
+//
+// The private key and certificate are linked with a localKeyId attribute
+// holding the SHA-1 hash of the certificate.
+func (enc *Encoder) Encode(rand io.Reader, bundle *Bundle, password string) ([]byte, error) {
+	if bundle == nil || bundle.PrivateKey == nil {
+		return nil, errors.New("pkcs12: a private key is required")
+	}
+	if bundle.Certificate == nil {
+		return nil, errors.New("pkcs12: a certificate is required")
+	}
+
+	bmpPassword := bmpStringZeroTerminated(password)
+	localKeyID := sha1.Sum(bundle.Certificate.Raw)
+
+	attrs, err := newBagAttributes(localKeyID[:], bundle.FriendlyName)
+	if err != nil {
+		return nil, err
+	}
+
+	certBags := make([]safeBag, 0, 1+len(bundle.CACertificates))
+	bag, err := newCertBag(bundle.Certificate, attrs)
+	if err != nil {
+		return nil, err
+	}
+	certBags = append(certBags, bag)
+	for _, cert := range bundle.CACertificates {
+		bag, err := newCertBag(cert, nil)
+		if err != nil {
+			return nil, err
+		}
+		certBags = append(certBags, bag)
+	}
+
+	keyBag, err := enc.newShroudedKeyBag(rand, bundle, attrs, bmpPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	certsContent, err := enc.newEncryptedDataContentInfo(rand, certBags, bmpPassword)
+	if err != nil {
+		return nil, err
+	}
+	keyContent, err := newDataContentInfo([]safeBag{keyBag})
+	if err != nil {
+		return nil, err
+	}
+
+	authSafeData, err := asn1.Marshal([]contentInfo{certsContent, keyContent})
+	if err != nil {
+		return nil, err
+	}
+
+	pfx := pfxPdu{Version: 3}
+	pfx.MacData, err = enc.newMACData(rand, authSafeData, bmpPassword)
+	if err != nil {
+		return nil, err
+	}
+	pfx.AuthSafe, err = newOctetStringContentInfo(oidDataContentType, authSafeData)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(pfx)
+}
+
+func (enc *Encoder) newMACData(rand io.Reader, message, password []byte) (macData, error) {
+	h, err := hashForDigest(enc.macAlgorithm)
+	if err != nil {
+		return macData{}, err
+	}
+
+	salt := make([]byte, enc.macSaltLen)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return macData{}, err
+	}
+
+	key := pbkdf(h, salt, password, enc.iterations, kdfMACKey, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(message)
+
+	return macData{
+		Mac: digestInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: enc.macAlgorithm, Parameters: asn1.NullRawValue},
+			Digest:    mac.Sum(nil),
+		},
+		MacSalt:    salt,
+		Iterations: enc.iterations,
+	}, nil
+}
+
+func (enc *Encoder) newShroudedKeyBag(rand io.Reader, bundle *Bundle, attrs []pkcs12Attribute, password []byte) (safeBag, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(bundle.PrivateKey)
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	algorithm, err := newPBEAlgorithm(rand, enc.keyAlgorithm, enc.iterations)
+	if err != nil {
+		return safeBag{}, err
+	}
+	encrypted, err := pbEncrypt(algorithm, der, password)
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	value, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		AlgorithmIdentifier: algorithm,
+		EncryptedData:       encrypted,
+	})
+	if err != nil {
+		return safeBag{}, err
+	}
+
+	return safeBag{
+		ID:         oidPKCS8ShroudedKeyBag,
+		Value:      explicitTag0(value),
+		Attributes: attrs,
+	}, nil
+}
+
+func (enc *Encoder) newEncryptedDataContentInfo(rand io.Reader, bags []safeBag, password []byte) (contentInfo, error) {
+	data, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	algorithm, err := newPBEAlgorithm(rand, enc.certAlgorithm, enc.iterations)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	encrypted, err := pbEncrypt(algorithm, data, password)
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	ed, err := asn1.Marshal(encryptedData{
+		Version: 0,
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType:                oidDataContentType,
+			ContentEncryptionAlgorithm: algorithm,
+			EncryptedContent:           asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: encrypted},
+		},
+	})
+	if err != nil {
+		return contentInfo{}, err
+	}
+
+	return contentInfo{
+		ContentType: oidEncryptedDataContentType,
+		Content:     explicitTag0(ed),
+	}, nil
+}
+
+func newDataContentInfo(bags []safeBag) (contentInfo, error) {
+	data, err := asn1.Marshal(bags)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	return newOctetStringContentInfo(oidDataContentType, data)
+}
+
+func newOctetStringContentInfo(contentType asn1.ObjectIdentifier, data []byte) (contentInfo, error) {
+	octets, err := asn1.Marshal(data)
+	if err != nil {
+		return contentInfo{}, err
+	}
+	return contentInfo{
+		ContentType: contentType,
+		Content:     explicitTag0(octets),
+	}, nil
+}
+
+func newCertBag(cert *x509.Certificate, attrs []pkcs12Attribute) (safeBag, error) {
+	value, err := asn1.Marshal(certBag{
+		ID:   oidCertTypeX509Certificate,
+		Data: cert.Raw,
+	})
+	if err != nil {
+		return safeBag{}, err
+	}
+	return safeBag{
+		ID:         oidCertBag,
+		Value:      explicitTag0(value),
+		Attributes: attrs,
+	}, nil
+}
+
+func newBagAttributes(localKeyID []byte, friendlyName string) ([]pkcs12Attribute, error) {
+	id, err := asn1.Marshal(localKeyID)
+	if err != nil {
+		return nil, err
+	}
+	attrs := []pkcs12Attribute{{ID: oidLocalKeyID, Value: setOf(id)}}
+
+	if friendlyName != "" {
+		name, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: bmpString(friendlyName)})
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, pkcs12Attribute{ID: oidFriendlyName, Value: setOf(name)})
+	}
+
+	return attrs, nil
+}
+
+// explicitTag0 wraps a DER value in a [0] EXPLICIT tag. RawValues are
+// marshalled verbatim, so struct tags cannot do this for us.
+func explicitTag0(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// setOf wraps a DER value in a SET.
+func setOf(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: der}
+}
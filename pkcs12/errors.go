@@ -0,0 +1,15 @@
+package pkcs12
+
+import "errors"
+
+// ErrIncorrectPassword is returned when the password does not
+// match the one the file was protected with.
+var ErrIncorrectPassword = errors.New("pkcs12: decryption password incorrect")
+
+// NotImplementedError is returned for valid PKCS#12 files which
+// use features this package does not support.
+type NotImplementedError string
+
+func (e NotImplementedError) Error() string {
+	return "pkcs12: " + string(e)
+}
@@ -0,0 +1,101 @@
+package pkcs12
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/binary"
+	"hash"
+)
+
+// Diversifier IDs for the PKCS#12 key derivation function.
+const (
+	kdfKeyID  = 1
+	kdfIVID   = 2
+	kdfMACKey = 3
+)
+
+// pbkdf is the password based key derivation function
+// from RFC 7292 Appendix B.2.
+func pbkdf(h func() hash.Hash, salt, password []byte, iterations int, id byte, size int) []byte {
+	u := h().Size()
+	v := h().BlockSize()
+
+	d := bytes.Repeat([]byte{id}, v)
+	s := fillWithRepeats(salt, v)
+	p := fillWithRepeats(password, v)
+	i := append(s, p...)
+
+	c := (size + u - 1) / u
+	out := make([]byte, 0, c*u)
+	for n := 0; n < c; n++ {
+		hh := h()
+		hh.Write(d)
+		hh.Write(i)
+		a := hh.Sum(nil)
+		for r := 1; r < iterations; r++ {
+			hh = h()
+			hh.Write(a)
+			a = hh.Sum(a[:0])
+		}
+		out = append(out, a...)
+
+		if n < c-1 {
+			b := fillWithRepeats(a, v)[:v]
+			for j := 0; j < len(i); j += v {
+				addWithCarry(i[j:j+v], b)
+			}
+		}
+	}
+	return out[:size]
+}
+
+// fillWithRepeats concatenates copies of pattern until the result is
+// a multiple of v bytes long and at least as long as pattern.
+func fillWithRepeats(pattern []byte, v int) []byte {
+	if len(pattern) == 0 {
+		return nil
+	}
+	n := v * ((len(pattern) + v - 1) / v)
+	return bytes.Repeat(pattern, (n+len(pattern)-1)/len(pattern))[:n]
+}
+
+// addWithCarry sets block to block + b + 1, treating both
+// as big-endian integers and discarding any overflow.
+func addWithCarry(block, b []byte) {
+	carry := uint16(1)
+	for k := len(block) - 1; k >= 0; k-- {
+		carry += uint16(block[k]) + uint16(b[k])
+		block[k] = byte(carry)
+		carry >>= 8
+	}
+}
+
+// pbkdf2 is PBKDF2 from RFC 8018 Section 5.2, used by PBES2.
+func pbkdf2(h func() hash.Hash, password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
@@ -0,0 +1,375 @@
+package pkcs12
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+
+	oidCertTypeX509Certificate = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidLocalKeyID              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+)
+
+// Bundle is the content of a PKCS#12 file: a private key,
+// the certificate it belongs to and the rest of its chain.
+type Bundle struct {
+	PrivateKey  crypto.PrivateKey
+	Certificate *x509.Certificate
+	// CACertificates are the other certificates in the file,
+	// usually the intermediates and root of the chain.
+	CACertificates []*x509.Certificate
+	// FriendlyName is the display name stored alongside the
+	// key or certificate, if any.
+	FriendlyName string
+}
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	AlgorithmIdentifier pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+// Decode extracts the private key and certificates from PKCS#12 data.
+//
+// The file must contain exactly one private key. Its certificate is
+// matched by the localKeyId attribute, or failing that by public key.
+//
+// Returns [ErrIncorrectPassword] if the password is wrong.
+func Decode(data []byte, password string) (*Bundle, error) {
+	bags, bmpPassword, err := decodeSafeBags(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	type certEntry struct {
+		cert         *x509.Certificate
+		localKeyID   []byte
+		friendlyName string
+	}
+
+	var (
+		certs        []certEntry
+		keys         []crypto.PrivateKey
+		keyID        []byte
+		friendlyName string
+	)
+
+	for _, bag := range bags {
+		switch {
+		case bag.ID.Equal(oidCertBag):
+			var cb certBag
+			if err := unmarshal(bag.Value.Bytes, &cb); err != nil {
+				return nil, err
+			}
+			if !cb.ID.Equal(oidCertTypeX509Certificate) {
+				// SDSI certificates and the like are not supported.
+				continue
+			}
+			cert, err := x509.ParseCertificate(cb.Data)
+			if err != nil {
+				return nil, fmt.Errorf("pkcs12: parsing certificate: %w", err)
+			}
+			id, name, err := bagAttributes(bag)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, certEntry{cert: cert, localKeyID: id, friendlyName: name})
+
+		case bag.ID.Equal(oidPKCS8ShroudedKeyBag), bag.ID.Equal(oidKeyBag):
+			der := bag.Value.Bytes
+			if bag.ID.Equal(oidPKCS8ShroudedKeyBag) {
+				var info encryptedPrivateKeyInfo
+				if err := unmarshal(bag.Value.Bytes, &info); err != nil {
+					return nil, err
+				}
+				der, err = pbDecrypt(info.AlgorithmIdentifier, info.EncryptedData, bmpPassword)
+				if err != nil {
+					return nil, err
+				}
+			}
+			key, err := x509.ParsePKCS8PrivateKey(der)
+			if err != nil {
+				return nil, fmt.Errorf("pkcs12: parsing private key: %w", err)
+			}
+			id, name, err := bagAttributes(bag)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			keyID = id
+			friendlyName = name
+		}
+	}
+
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("pkcs12: expected exactly one private key, found %d", len(keys))
+	}
+
+	bundle := Bundle{
+		PrivateKey:   keys[0],
+		FriendlyName: friendlyName,
+	}
+
+	leaf := -1
+	if keyID != nil {
+		for i, c := range certs {
+			if bytes.Equal(c.localKeyID, keyID) {
+				leaf = i
+				break
+			}
+		}
+	}
+	if leaf == -1 {
+		signer, ok := bundle.PrivateKey.(crypto.Signer)
+		if ok {
+			pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+			for i, c := range certs {
+				if ok && pub.Equal(c.cert.PublicKey) {
+					leaf = i
+					break
+				}
+			}
+		}
+	}
+	if leaf == -1 {
+		return nil, errors.New("pkcs12: no certificate found for the private key")
+	}
+
+	for i, c := range certs {
+		if i == leaf {
+			bundle.Certificate = c.cert
+			if bundle.FriendlyName == "" {
+				bundle.FriendlyName = c.friendlyName
+			}
+			continue
+		}
+		bundle.CACertificates = append(bundle.CACertificates, c.cert)
+	}
+
+	return &bundle, nil
+}
+
+// decodeSafeBags verifies the MAC of a PFX and returns the safe bags
+// it contains, decrypting any encrypted content. It also returns the
+// form of the password which verified, for decrypting key bags.
+func decodeSafeBags(data []byte, password string) ([]safeBag, []byte, error) {
+	data, err := berToDER(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pfx pfxPdu
+	if err := unmarshal(data, &pfx); err != nil {
+		return nil, nil, err
+	}
+	if pfx.Version != 3 {
+		return nil, nil, NotImplementedError("only version 3 PFX PDUs are supported")
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, nil, NotImplementedError("only password-protected PFX PDUs are supported")
+	}
+
+	var authSafeData []byte
+	if err := unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeData); err != nil {
+		return nil, nil, err
+	}
+
+	bmpPassword := bmpStringZeroTerminated(password)
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) == 0 {
+		// a PFX without a MAC is only accepted without a password, as
+		// otherwise its contents would be trusted unauthenticated.
+		if password != "" {
+			return nil, nil, errors.New("pkcs12: no MAC in data")
+		}
+	} else {
+		err := verifyMAC(&pfx.MacData, authSafeData, bmpPassword)
+		if err == ErrIncorrectPassword && password == "" {
+			// Some writers encode an empty password as zero
+			// bytes rather than as a lone null terminator.
+			bmpPassword = nil
+			err = verifyMAC(&pfx.MacData, authSafeData, bmpPassword)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var authSafe []contentInfo
+	if err := unmarshal(authSafeData, &authSafe); err != nil {
+		return nil, nil, err
+	}
+
+	var bags []safeBag
+	for _, ci := range authSafe {
+		var contents []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContentType):
+			if err := unmarshal(ci.Content.Bytes, &contents); err != nil {
+				return nil, nil, err
+			}
+
+		case ci.ContentType.Equal(oidEncryptedDataContentType):
+			var ed encryptedData
+			if err := unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, nil, err
+			}
+			encrypted, err := encryptedContentBytes(ed.EncryptedContentInfo.EncryptedContent)
+			if err != nil {
+				return nil, nil, err
+			}
+			contents, err = pbDecrypt(ed.EncryptedContentInfo.ContentEncryptionAlgorithm, encrypted, bmpPassword)
+			if err != nil {
+				return nil, nil, err
+			}
+
+		default:
+			return nil, nil, NotImplementedError("only data and encryptedData content types are supported in the authenticated safe")
+		}
+
+		var safeContents []safeBag
+		if err := unmarshal(contents, &safeContents); err != nil {
+			return nil, nil, err
+		}
+		bags = append(bags, safeContents...)
+	}
+
+	return bags, bmpPassword, nil
+}
+
+// encryptedContentBytes returns the ciphertext of an EncryptedContentInfo,
+// joining the segments of a constructed encoding if necessary.
+func encryptedContentBytes(v asn1.RawValue) ([]byte, error) {
+	if !v.IsCompound {
+		return v.Bytes, nil
+	}
+	var out []byte
+	rest := v.Bytes
+	for len(rest) > 0 {
+		var segment []byte
+		var err error
+		rest, err = asn1.Unmarshal(rest, &segment)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs12: invalid encrypted content: %w", err)
+		}
+		out = append(out, segment...)
+	}
+	return out, nil
+}
+
+func verifyMAC(md *macData, message, password []byte) error {
+	h, err := hashForDigest(md.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	if md.Iterations < 1 || md.Iterations > maxIterations {
+		return fmt.Errorf("pkcs12: invalid MAC iteration count %d", md.Iterations)
+	}
+
+	key := pbkdf(h, md.MacSalt, password, md.Iterations, kdfMACKey, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(message)
+
+	if !hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
+// bagAttributes returns the localKeyId and friendlyName attributes of a bag.
+func bagAttributes(bag safeBag) (localKeyID []byte, friendlyName string, err error) {
+	for _, attr := range bag.Attributes {
+		switch {
+		case attr.ID.Equal(oidLocalKeyID):
+			if err := unmarshal(attr.Value.Bytes, &localKeyID); err != nil {
+				return nil, "", err
+			}
+		case attr.ID.Equal(oidFriendlyName):
+			var raw asn1.RawValue
+			if err := unmarshal(attr.Value.Bytes, &raw); err != nil {
+				return nil, "", err
+			}
+			if raw.Tag != asn1.TagBMPString {
+				return nil, "", errors.New("pkcs12: friendlyName is not a BMPString")
+			}
+			friendlyName, err = decodeBMPString(raw.Bytes)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return localKeyID, friendlyName, nil
+}
+
+// unmarshal parses a single DER value, rejecting trailing data.
+func unmarshal(in []byte, out interface{}) error {
+	rest, err := asn1.Unmarshal(in, out)
+	if err != nil {
+		return fmt.Errorf("pkcs12: %w", err)
+	}
+	if len(rest) != 0 {
+		return errors.New("pkcs12: trailing data found")
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package pkcs12
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name             string
+		file             string
+		password         string
+		wantCommonName   string
+		wantCACommonName string
+		wantFriendlyName string
+		wantErr          error
+	}{
+		{
+			name:             "openssl_aes",
+			file:             "testdata/ec-aes.p12",
+			password:         "correct-horse",
+			wantCommonName:   "leaf.example.com",
+			wantCACommonName: "Test CA",
+			wantFriendlyName: "leaf friendly",
+		},
+		{
+			name:             "openssl_legacy_rc2",
+			file:             "testdata/ec-legacy.p12",
+			password:         "correct-horse",
+			wantCommonName:   "leaf.example.com",
+			wantCACommonName: "Test CA",
+			wantFriendlyName: "leaf friendly",
+		},
+		{
+			name:           "empty_password",
+			file:           "testdata/rsa-empty-password.p12",
+			wantCommonName: "rsa.example.com",
+		},
+		{
+			name:     "wrong_password",
+			file:     "testdata/ec-aes.p12",
+			password: "battery-staple",
+			wantErr:  ErrIncorrectPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Decode(data, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Certificate.Subject.CommonName != tt.wantCommonName {
+				t.Errorf("got certificate CN = %q, want %q", got.Certificate.Subject.CommonName, tt.wantCommonName)
+			}
+			if got.FriendlyName != tt.wantFriendlyName {
+				t.Errorf("got FriendlyName = %q, want %q", got.FriendlyName, tt.wantFriendlyName)
+			}
+
+			if tt.wantCACommonName == "" {
+				if len(got.CACertificates) != 0 {
+					t.Errorf("got %d CA certificates, want 0", len(got.CACertificates))
+				}
+			} else {
+				if len(got.CACertificates) != 1 {
+					t.Fatalf("got %d CA certificates, want 1", len(got.CACertificates))
+				}
+				if cn := got.CACertificates[0].Subject.CommonName; cn != tt.wantCACommonName {
+					t.Errorf("got CA certificate CN = %q, want %q", cn, tt.wantCACommonName)
+				}
+			}
+
+			signer, ok := got.PrivateKey.(crypto.Signer)
+			if !ok {
+				t.Fatalf("private key %T is not a crypto.Signer", got.PrivateKey)
+			}
+			if !signer.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(got.Certificate.PublicKey) {
+				t.Errorf("private key does not match certificate")
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		encoder *Encoder
+		key     crypto.Signer
+	}{
+		{name: "modern_ec", encoder: Modern, key: ecKey},
+		{name: "modern_rsa", encoder: Modern, key: rsaKey},
+		{name: "legacy_ec", encoder: Legacy, key: ecKey},
+		{name: "legacy_rsa", encoder: Legacy, key: rsaKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ca := newTestCertificate(t, "Test CA", caKey.Public(), nil, caKey)
+			leaf := newTestCertificate(t, "leaf", tt.key.Public(), ca, caKey)
+
+			in := &Bundle{
+				PrivateKey:     tt.key,
+				Certificate:    leaf,
+				CACertificates: []*x509.Certificate{ca},
+				FriendlyName:   "my identity ✓",
+			}
+
+			data, err := tt.encoder.Encode(rand.Reader, in, "p4ssw0rd")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			got, err := Decode(data, "p4ssw0rd")
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if !got.Certificate.Equal(leaf) {
+				t.Errorf("decoded certificate does not match")
+			}
+			if len(got.CACertificates) != 1 || !got.CACertificates[0].Equal(ca) {
+				t.Errorf("decoded CA certificates do not match")
+			}
+			if got.FriendlyName != in.FriendlyName {
+				t.Errorf("got FriendlyName = %q, want %q", got.FriendlyName, in.FriendlyName)
+			}
+			if !tt.key.(interface{ Equal(crypto.PrivateKey) bool }).Equal(got.PrivateKey) {
+				t.Errorf("decoded private key does not match")
+			}
+
+			if _, err := Decode(data, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+				t.Errorf("Decode() with wrong password error = %v, want %v", err, ErrIncorrectPassword)
+			}
+		})
+	}
+}
+
+func TestDecode_NoMAC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &Bundle{PrivateKey: key, Certificate: newTestCertificate(t, "leaf", key.Public(), nil, key)}
+
+	// withoutMAC returns the PFX encoded with password, with its MAC removed.
+	withoutMAC := func(password string) []byte {
+		data, err := Modern.Encode(rand.Reader, in, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pfx pfxPdu
+		if err := unmarshal(data, &pfx); err != nil {
+			t.Fatal(err)
+		}
+		pfx.MacData = macData{}
+		data, err = asn1.Marshal(pfx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	if _, err := Decode(withoutMAC(""), ""); err != nil {
+		t.Errorf("Decode() without a MAC or password error = %v", err)
+	}
+	if _, err := Decode(withoutMAC("p4ssw0rd"), "p4ssw0rd"); err == nil {
+		t.Error("Decode() with a password of a PFX without a MAC succeeded")
+	}
+}
+
+func TestBERToDER(t *testing.T) {
+	// SEQUENCE (indefinite) { OCTET STRING (constructed, indefinite) { "ab", "c" }, INTEGER 5 }
+	ber := []byte{
+		0x30, 0x80,
+		0x24, 0x80, 0x04, 0x02, 'a', 'b', 0x04, 0x01, 'c', 0x00, 0x00,
+		0x02, 0x01, 0x05,
+		0x00, 0x00,
+	}
+	want := []byte{0x30, 0x08, 0x04, 0x03, 'a', 'b', 'c', 0x02, 0x01, 0x05}
+
+	got, err := berToDER(ber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("berToDER() = %x, want %x", got, want)
+	}
+
+	if _, err := berToDER(ber[:len(ber)-1]); err == nil {
+		t.Errorf("expected error for truncated input")
+	}
+}
+
+func newTestCertificate(t *testing.T, cn string, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
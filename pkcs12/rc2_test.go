@@ -0,0 +1,43 @@
+package pkcs12
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestRC2(t *testing.T) {
+	// Test vectors from RFC 2268 Section 5.
+	tests := []struct {
+		key           string
+		effectiveBits int
+		plaintext     string
+		ciphertext    string
+	}{
+		{"0000000000000000", 63, "0000000000000000", "ebb773f993278eff"},
+		{"ffffffffffffffff", 64, "ffffffffffffffff", "278b27e42e2f0d49"},
+		{"3000000000000000", 64, "1000000000000001", "30649edf9be7d2c2"},
+		{"88", 64, "0000000000000000", "61a8a244adacccf0"},
+		{"88bca90e90875a", 64, "0000000000000000", "6ccf4308974c267f"},
+		{"88bca90e90875a7f0f79c384627bafb2", 64, "0000000000000000", "1a807d272bbe5db1"},
+		{"88bca90e90875a7f0f79c384627bafb2", 128, "0000000000000000", "2269552ab0f85ca6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.key)
+			plaintext, _ := hex.DecodeString(tt.plaintext)
+
+			c := newRC2Cipher(key, tt.effectiveBits)
+
+			got := make([]byte, rc2BlockSize)
+			c.Encrypt(got, plaintext)
+			if hex.EncodeToString(got) != tt.ciphertext {
+				t.Errorf("Encrypt() = %x, want %s", got, tt.ciphertext)
+			}
+
+			c.Decrypt(got, got)
+			if hex.EncodeToString(got) != tt.plaintext {
+				t.Errorf("Decrypt() = %x, want %s", got, tt.plaintext)
+			}
+		})
+	}
+}
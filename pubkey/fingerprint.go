@@ -0,0 +1,53 @@
+package pubkey
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+)
+
+// ApplicationLabel returns the kSecAttrApplicationLabel which the Security
+// framework assigns to a key: the SHA-1 digest of the external
+// representation of its public key, which is the X9.63 point of an
+// elliptic curve key and the PKCS#1 encoding of an RSA key.
+//
+// It identifies exactly one key, unlike its tag and label.
+func ApplicationLabel(pub crypto.PublicKey) ([]byte, error) {
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+	data, _, err := keyrep.MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum(data)
+	return sum[:], nil
+}
+
+// fingerprintPrefix precedes the digest in SHA-256 fingerprints.
+const fingerprintPrefix = "SHA256:"
+
+// Fingerprint returns the SHA-256 fingerprint of pub as OpenSSH displays
+// it, such as "SHA256:m54VEXjwa0KXFQ+kHMehe3Ry/bPm1FfHOwC8pz7oOkI".
+func Fingerprint(pub crypto.PublicKey) (string, error) {
+	_, blob, err := marshalSSH(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(blob)
+	return fingerprintPrefix + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+// FingerprintMatches reports whether fingerprint, with or without
+// its "SHA256:" prefix, is the fingerprint of pub.
+func FingerprintMatches(pub crypto.PublicKey, fingerprint string) bool {
+	got, err := Fingerprint(pub)
+	if err != nil {
+		return false
+	}
+	return strings.TrimPrefix(got, fingerprintPrefix) == strings.TrimPrefix(fingerprint, fingerprintPrefix)
+}
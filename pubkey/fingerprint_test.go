@@ -0,0 +1,73 @@
+package pubkey
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"os"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	// fingerprints as printed by ssh-keygen -l.
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "testdata/ecdsa.pub", want: "SHA256:m54VEXjwa0KXFQ+kHMehe3Ry/bPm1FfHOwC8pz7oOkI"},
+		{file: "testdata/rsa.pub", want: "SHA256:M8EG2Y9pB63oZdDwbX+5towMxIGTJUQ45TdLx0dLJww"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			line, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pub, _, err := ParseAuthorizedKey(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Fingerprint(pub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Fingerprint() = %s, want %s", got, tt.want)
+			}
+
+			if !FingerprintMatches(pub, tt.want[len("SHA256:"):]) {
+				t.Errorf("FingerprintMatches() without prefix = false")
+			}
+			if FingerprintMatches(pub, "SHA256:AAAA") {
+				t.Errorf("FingerprintMatches() of another fingerprint = true")
+			}
+		})
+	}
+}
+
+func TestApplicationLabel(t *testing.T) {
+	pemData, err := os.ReadFile("testdata/ecdsa.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePEM(pemData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ApplicationLabel(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the label of an EC key is the SHA-1 of its X9.63 point, which
+	// is the final 65 bytes of the PKIX encoding of a P-256 key.
+	der, err := MarshalPKIX(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sha1.Sum(der[len(der)-65:])
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("ApplicationLabel() = %x, want %x", got, want)
+	}
+}
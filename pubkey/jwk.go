@@ -0,0 +1,170 @@
+package pubkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the JSON Web Key representation of a public key, with
+// the members defined for EC and RSA keys by RFC 7518 section 6.
+//
+// See: https://www.rfc-editor.org/rfc/rfc7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+
+	// Crv, X and Y are set for EC keys.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// N and E are set for RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK returns the JWK of pub, with Kid set to its RFC 7638 thumbprint.
+func NewJWK(pub crypto.PublicKey) (*JWK, error) {
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+
+	var jwk JWK
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk = JWK{
+			Kty: "EC",
+			Crv: k.Curve.Params().Name,
+			X:   b64.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:   b64.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}
+	case *rsa.PublicKey:
+		jwk = JWK{
+			Kty: "RSA",
+			N:   b64.EncodeToString(k.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	jwk.Kid = b64.EncodeToString(thumbprint)
+
+	return &jwk, nil
+}
+
+// MarshalJWK returns the JSON encoding of the JWK of pub.
+func MarshalJWK(pub crypto.PublicKey) ([]byte, error) {
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jwk)
+}
+
+// ParseJWK parses a JSON Web Key holding an EC or RSA public key.
+func ParseJWK(data []byte) (crypto.PublicKey, error) {
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, err
+	}
+	return jwk.PublicKey()
+}
+
+// PublicKey returns the public key held by the JWK.
+func (j *JWK) PublicKey() (crypto.PublicKey, error) {
+	var pub crypto.PublicKey
+	switch j.Kty {
+	case "EC":
+		curve, err := curveByName(j.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := b64.DecodeString(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK x: %w", err)
+		}
+		y, err := b64.DecodeString(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("JWK coordinates must be %d bytes for %s", size, j.Crv)
+		}
+		pub = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+	case "RSA":
+		n, err := b64.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK n: %w", err)
+		}
+		e, err := b64.DecodeString(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK e: %w", err)
+		}
+		if len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("unsupported JWK exponent")
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", j.Kty)
+	}
+
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// Thumbprint returns the SHA-256 JWK thumbprint of the key, computed
+// over its required members in lexicographic order as RFC 7638 specifies.
+func (j *JWK) Thumbprint() ([]byte, error) {
+	var members string
+	switch j.Kty {
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Crv, j.X, j.Y)
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", j.Kty)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return sum[:], nil
+}
+
+// Thumbprint returns the base64url encoded RFC 7638 SHA-256
+// JWK thumbprint of pub, commonly used as a key ID.
+func Thumbprint(pub crypto.PublicKey) (string, error) {
+	jwk, err := NewJWK(pub)
+	if err != nil {
+		return "", err
+	}
+	return jwk.Kid, nil
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	}
+	return nil, fmt.Errorf("unsupported JWK curve %q", name)
+}
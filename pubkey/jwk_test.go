@@ -0,0 +1,86 @@
+package pubkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+)
+
+func TestThumbprint_RFC7638(t *testing.T) {
+	// the example key and thumbprint of RFC 7638 section 3.1.
+	jwk := JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	const want = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+
+	sum, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := b64.EncodeToString(sum); got != want {
+		t.Errorf("Thumbprint() = %s, want %s", got, want)
+	}
+
+	pub, err := jwk.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Thumbprint(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Thumbprint() of parsed key = %s, want %s", got, want)
+	}
+}
+
+func TestParseJWK(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := NewJWK(&p256.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validRSA, err := NewJWK(&r.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(j JWK) JWK
+		base    *JWK
+		wantErr bool
+	}{
+		{name: "ec", base: valid, modify: func(j JWK) JWK { return j }},
+		{name: "rsa", base: validRSA, modify: func(j JWK) JWK { return j }},
+		{name: "wrong_curve", base: valid, modify: func(j JWK) JWK { j.Crv = "P-384"; return j }, wantErr: true},
+		{name: "off_curve", base: valid, modify: func(j JWK) JWK { j.Y = j.X; return j }, wantErr: true},
+		{name: "short_coordinate", base: valid, modify: func(j JWK) JWK { j.X = j.X[:10]; return j }, wantErr: true},
+		{name: "unknown_kty", base: valid, modify: func(j JWK) JWK { j.Kty = "OKP"; return j }, wantErr: true},
+		{name: "even_exponent", base: validRSA, modify: func(j JWK) JWK { j.E = "AQAA"; return j }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.modify(*tt.base))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ParseJWK(data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseJWK() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
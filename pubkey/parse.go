@@ -0,0 +1,35 @@
+package pubkey
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+)
+
+// Parse parses a public key in any of the formats of this package,
+// detecting which from its content: PEM, a JSON Web Key, an OpenSSH
+// authorized_keys line, PKIX DER or an uncompressed X9.63 point.
+func Parse(data []byte) (crypto.PublicKey, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, errors.New("empty public key")
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN")):
+		return ParsePEM(trimmed)
+	case trimmed[0] == '{':
+		return ParseJWK(trimmed)
+	case bytes.Contains(trimmed, []byte("ssh-rsa")) || bytes.Contains(trimmed, []byte("ecdsa-sha2-")):
+		pub, _, err := ParseAuthorizedKey(trimmed)
+		return pub, err
+	}
+
+	// binary formats must not be trimmed, as
+	// whitespace bytes may be part of the key.
+	switch data[0] {
+	case 0x04:
+		return ParseX963(data)
+	case 0x30:
+		return ParsePKIX(data)
+	}
+	return nil, errors.New("unrecognised public key format")
+}
@@ -0,0 +1,48 @@
+package pubkey
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for keyName, pub := range testKeys(t) {
+		t.Run(keyName, func(t *testing.T) {
+			encodings := map[string]func() ([]byte, error){
+				"pkix": func() ([]byte, error) { return MarshalPKIX(pub) },
+				"pem":  func() ([]byte, error) { return MarshalPEM(pub) },
+				"jwk":  func() ([]byte, error) { return MarshalJWK(pub) },
+				"ssh":  func() ([]byte, error) { return MarshalAuthorizedKey(pub, "comment") },
+			}
+			for name, marshal := range encodings {
+				data, err := marshal()
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				got, err := Parse(data)
+				if err != nil {
+					t.Fatalf("Parse(%s) error = %v", name, err)
+				}
+				if !got.(equaler).Equal(pub) {
+					t.Errorf("Parse(%s) returned another key", name)
+				}
+			}
+		})
+	}
+
+	for _, file := range []string{"testdata/ecdsa.pub", "testdata/rsa.pem"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Parse(data); err != nil {
+			t.Errorf("Parse(%s) error = %v", file, err)
+		}
+	}
+
+	for _, data := range []string{"", "  \n", "not a key", "\x05abc"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded", data)
+		}
+	}
+}
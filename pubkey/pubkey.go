@@ -0,0 +1,102 @@
+// Package pubkey converts RSA and ECDSA public keys to and from the
+// formats used to share them: PKIX (SubjectPublicKeyInfo) DER and PEM,
+// JSON Web Keys, OpenSSH authorized_keys lines and raw X9.63 points.
+//
+// Every parser validates the key, so elliptic curve points which are not
+// on their curve are rejected rather than producing a key which fails later.
+package pubkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+)
+
+// pemType is the type of PEM blocks holding a PKIX public key.
+const pemType = "PUBLIC KEY"
+
+// MarshalPKIX returns the PKIX, ASN.1 DER SubjectPublicKeyInfo encoding of pub.
+func MarshalPKIX(pub crypto.PublicKey) ([]byte, error) {
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKIXPublicKey(pub)
+}
+
+// ParsePKIX parses a PKIX, ASN.1 DER SubjectPublicKeyInfo public key.
+func ParsePKIX(der []byte) (crypto.PublicKey, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// MarshalPEM returns pub as a PEM "PUBLIC KEY" block.
+func MarshalPEM(pub crypto.PublicKey) ([]byte, error) {
+	der, err := MarshalPKIX(pub)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der}), nil
+}
+
+// ParsePEM parses the first PEM "PUBLIC KEY" block in data.
+func ParsePEM(data []byte) (crypto.PublicKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no PEM public key found")
+		}
+		if block.Type == pemType {
+			return ParsePKIX(block.Bytes)
+		}
+	}
+}
+
+// MarshalX963 returns the uncompressed X9.63 point 04 || X || Y of an
+// elliptic curve public key, the representation used by the Security framework.
+func MarshalX963(pub *ecdsa.PublicKey) ([]byte, error) {
+	e, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// ParseX963 parses an uncompressed X9.63 point on the P-256, P-384 or
+// P-521 curve, which is inferred from its length.
+func ParseX963(data []byte) (*ecdsa.PublicKey, error) {
+	return keyrep.ParseECPublicKey(data)
+}
+
+// check returns an error if pub is not a supported, valid public key.
+func check(pub crypto.PublicKey) error {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if k == nil || k.Curve == nil || k.X == nil || k.Y == nil {
+			return errors.New("incomplete ECDSA public key")
+		}
+		// crypto/ecdh rejects unsupported curves and invalid points.
+		if _, err := k.ECDH(); err != nil {
+			return fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+	case *rsa.PublicKey:
+		if k == nil || k.N == nil || k.N.Sign() <= 0 || k.E < 3 || k.E&1 == 0 {
+			return errors.New("invalid RSA public key")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return nil
+}
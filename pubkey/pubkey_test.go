@@ -0,0 +1,117 @@
+package pubkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+type equaler interface {
+	Equal(crypto.PublicKey) bool
+}
+
+func testKeys(t *testing.T) map[string]crypto.PublicKey {
+	t.Helper()
+
+	keys := map[string]crypto.PublicKey{}
+	for name, curve := range map[string]elliptic.Curve{"p256": elliptic.P256(), "p384": elliptic.P384(), "p521": elliptic.P521()} {
+		k, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[name] = &k.PublicKey
+	}
+	r, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys["rsa"] = &r.PublicKey
+	return keys
+}
+
+func TestRoundTrip(t *testing.T) {
+	formats := []struct {
+		name      string
+		marshal   func(crypto.PublicKey) ([]byte, error)
+		parse     func([]byte) (crypto.PublicKey, error)
+		ecdsaOnly bool
+	}{
+		{name: "pkix", marshal: MarshalPKIX, parse: ParsePKIX},
+		{name: "pem", marshal: MarshalPEM, parse: ParsePEM},
+		{name: "jwk", marshal: MarshalJWK, parse: ParseJWK},
+		{
+			name:    "authorized_key",
+			marshal: func(pub crypto.PublicKey) ([]byte, error) { return MarshalAuthorizedKey(pub, "me@host") },
+			parse: func(data []byte) (crypto.PublicKey, error) {
+				pub, comment, err := ParseAuthorizedKey(data)
+				if err == nil && comment != "me@host" {
+					t.Errorf("got comment %q, want %q", comment, "me@host")
+				}
+				return pub, err
+			},
+		},
+		{
+			name:      "x963",
+			marshal:   func(pub crypto.PublicKey) ([]byte, error) { return MarshalX963(pub.(*ecdsa.PublicKey)) },
+			parse:     func(data []byte) (crypto.PublicKey, error) { return ParseX963(data) },
+			ecdsaOnly: true,
+		},
+	}
+
+	for keyName, pub := range testKeys(t) {
+		for _, f := range formats {
+			if _, isRSA := pub.(*rsa.PublicKey); isRSA && f.ecdsaOnly {
+				continue
+			}
+			t.Run(f.name+"_"+keyName, func(t *testing.T) {
+				data, err := f.marshal(pub)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := f.parse(data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !pub.(equaler).Equal(got) {
+					t.Errorf("round trip changed the key")
+				}
+			})
+		}
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	point, err := MarshalX963(&p256.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := bytes.Clone(point)
+	offCurve[len(offCurve)-1] ^= 1
+
+	if _, err := ParseX963(offCurve); err == nil {
+		t.Errorf("ParseX963() accepted a point which is not on the curve")
+	}
+	if _, err := ParseX963(point[:40]); err == nil {
+		t.Errorf("ParseX963() accepted a truncated point")
+	}
+
+	invalid := &ecdsa.PublicKey{Curve: elliptic.P256(), X: p256.X, Y: new(big.Int).Add(p256.Y, big.NewInt(1))}
+	if _, err := MarshalPKIX(invalid); err == nil {
+		t.Errorf("MarshalPKIX() accepted a point which is not on the curve")
+	}
+	if _, err := MarshalPKIX(&ecdsa.PublicKey{Curve: elliptic.P256()}); err == nil {
+		t.Errorf("MarshalPKIX() accepted a key without coordinates")
+	}
+	if _, err := ParsePEM([]byte("not pem")); err == nil {
+		t.Errorf("ParsePEM() accepted invalid input")
+	}
+}
@@ -0,0 +1,185 @@
+package pubkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// sshCurves maps the curve names of OpenSSH ECDSA keys to their size in bits.
+var sshCurves = map[string]int{"nistp256": 256, "nistp384": 384, "nistp521": 521}
+
+// MarshalAuthorizedKey returns pub as a line of an OpenSSH authorized_keys
+// file, such as "ecdsa-sha2-nistp256 AAAA... comment", ending in a newline.
+// The comment is omitted if it is empty.
+func MarshalAuthorizedKey(pub crypto.PublicKey, comment string) ([]byte, error) {
+	keyType, blob, err := marshalSSH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	line := keyType + " " + base64.StdEncoding.EncodeToString(blob)
+	if comment != "" {
+		line += " " + comment
+	}
+	return []byte(line + "\n"), nil
+}
+
+// ParseAuthorizedKey parses a public key from a line of an OpenSSH
+// authorized_keys file, returning it and its comment. Options
+// preceding the key type are skipped.
+func ParseAuthorizedKey(line []byte) (crypto.PublicKey, string, error) {
+	fields := strings.Fields(string(line))
+	for i, f := range fields {
+		if f != "ssh-rsa" && !strings.HasPrefix(f, "ecdsa-sha2-") {
+			continue
+		}
+		if i+1 >= len(fields) {
+			return nil, "", errors.New("authorized key has no key data")
+		}
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid authorized key data: %w", err)
+		}
+		pub, err := parseSSH(f, blob)
+		if err != nil {
+			return nil, "", err
+		}
+		return pub, strings.Join(fields[i+2:], " "), nil
+	}
+	return nil, "", errors.New("no supported OpenSSH public key found")
+}
+
+// marshalSSH returns the key type and wire encoding of pub,
+// as specified by RFC 4253 section 6.6 and RFC 5656 section 3.1.
+func marshalSSH(pub crypto.PublicKey) (string, []byte, error) {
+	if err := check(pub); err != nil {
+		return "", nil, err
+	}
+
+	var b bytes.Buffer
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		curve := fmt.Sprintf("nistp%d", k.Curve.Params().BitSize)
+		if _, ok := sshCurves[curve]; !ok {
+			return "", nil, fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+		}
+		point, err := MarshalX963(k)
+		if err != nil {
+			return "", nil, err
+		}
+		keyType := "ecdsa-sha2-" + curve
+		writeString(&b, []byte(keyType))
+		writeString(&b, []byte(curve))
+		writeString(&b, point)
+		return keyType, b.Bytes(), nil
+
+	case *rsa.PublicKey:
+		writeString(&b, []byte("ssh-rsa"))
+		writeString(&b, mpint(big.NewInt(int64(k.E))))
+		writeString(&b, mpint(k.N))
+		return "ssh-rsa", b.Bytes(), nil
+	}
+	return "", nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// parseSSH parses the wire encoding of a public key of keyType.
+func parseSSH(keyType string, blob []byte) (crypto.PublicKey, error) {
+	r := bytes.NewReader(blob)
+
+	name, err := readString(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(name) != keyType {
+		return nil, fmt.Errorf("key data of type %q does not match %q", name, keyType)
+	}
+
+	var pub crypto.PublicKey
+	switch {
+	case keyType == "ssh-rsa":
+		e, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		n, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(e) > 4 {
+			return nil, errors.New("unsupported RSA exponent")
+		}
+		pub = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	case strings.HasPrefix(keyType, "ecdsa-sha2-"):
+		curve, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		if "ecdsa-sha2-"+string(curve) != keyType {
+			return nil, fmt.Errorf("curve %q does not match key type %q", curve, keyType)
+		}
+		bits, ok := sshCurves[string(curve)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", curve)
+		}
+		point, err := readString(r)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ParseX963(point)
+		if err != nil {
+			return nil, err
+		}
+		if k.Curve.Params().BitSize != bits {
+			return nil, fmt.Errorf("point does not match curve %q", curve)
+		}
+		pub = k
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+
+	if r.Len() != 0 {
+		return nil, errors.New("trailing data after public key")
+	}
+	if err := check(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func writeString(b *bytes.Buffer, s []byte) {
+	_ = binary.Write(b, binary.BigEndian, uint32(len(s)))
+	b.Write(s)
+}
+
+func readString(r *bytes.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, errors.New("truncated public key data")
+	}
+	if int64(n) > int64(r.Len()) {
+		return nil, errors.New("truncated public key data")
+	}
+	s := make([]byte, n)
+	_, _ = r.Read(s)
+	return s, nil
+}
+
+// mpint returns the SSH encoding of a non-negative integer: big-endian,
+// with a leading zero byte if the most significant bit is set.
+func mpint(n *big.Int) []byte {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return b
+}
@@ -0,0 +1,68 @@
+package pubkey
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// The testdata keys were generated by ssh-keygen, and their
+// PEM encodings exported with ssh-keygen -e -m PKCS8.
+func TestParseAuthorizedKey_OpenSSH(t *testing.T) {
+	for _, name := range []string{"ecdsa", "rsa"} {
+		t.Run(name, func(t *testing.T) {
+			line, err := os.ReadFile("testdata/" + name + ".pub")
+			if err != nil {
+				t.Fatal(err)
+			}
+			pemData, err := os.ReadFile("testdata/" + name + ".pem")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pub, comment, err := ParseAuthorizedKey(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comment != "test@example.com" {
+				t.Errorf("got comment %q", comment)
+			}
+
+			want, err := ParsePEM(pemData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !want.(equaler).Equal(pub) {
+				t.Errorf("authorized key does not match the PEM key")
+			}
+
+			got, err := MarshalAuthorizedKey(want, comment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, line) {
+				t.Errorf("MarshalAuthorizedKey() = %q, want %q", got, line)
+			}
+		})
+	}
+}
+
+func TestParseAuthorizedKey_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "empty", line: ""},
+		{name: "no_data", line: "ecdsa-sha2-nistp256"},
+		{name: "bad_base64", line: "ecdsa-sha2-nistp256 !!!"},
+		{name: "type_mismatch", line: "ssh-rsa AAAAE2VjZHNhLXNoYTItbmlzdHAyNTY="},
+		{name: "unsupported", line: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIA=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ParseAuthorizedKey([]byte(tt.line)); err == nil {
+				t.Errorf("ParseAuthorizedKey(%q) returned no error", tt.line)
+			}
+		})
+	}
+}
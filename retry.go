@@ -0,0 +1,64 @@
+package applesecurity
+
+import (
+	"context"
+	"time"
+
+	"github.com/common-fate/go-apple-security/internal/retry"
+)
+
+// RetryPolicy retries keychain operations which fail with errors
+// reported by [IsTransient], waiting with exponential backoff between
+// attempts. Other errors are returned immediately.
+//
+// The zero value makes DefaultRetryAttempts attempts, starting with a
+// delay of DefaultRetryDelay and doubling it up to DefaultRetryMaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first.
+	MaxAttempts int
+
+	// Delay is the wait after the first failed attempt.
+	// It doubles after each subsequent attempt up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+
+	// WaitForUnlock retries errors reported by [IsLockedDevice] until
+	// ctx is done, without counting them against MaxAttempts. It suits
+	// background processes which should resume once the user unlocks
+	// their device rather than fail.
+	WaitForUnlock bool
+}
+
+// Defaults for the zero fields of a RetryPolicy.
+const (
+	DefaultRetryAttempts = retry.DefaultAttempts
+	DefaultRetryDelay    = retry.DefaultDelay
+	DefaultRetryMaxDelay = retry.DefaultMaxDelay
+)
+
+// Do calls fn until it succeeds, fails with an error which is not
+// transient, the attempts are exhausted or ctx is done. It returns
+// the last error from fn, or the error of ctx if it was done first.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	_, err := Retry(ctx, p, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Retry calls fn as [RetryPolicy.Do] does, returning its result:
+//
+//	p, err := applesecurity.Retry(ctx, policy, func() (*keychain.GenericPassword, error) {
+//		return keychain.GetGenericPassword(input)
+//	})
+func Retry[T any](ctx context.Context, p RetryPolicy, fn func() (T, error)) (T, error) {
+	return retry.Do(ctx, retry.Policy{
+		MaxAttempts: p.MaxAttempts,
+		Delay:       p.Delay,
+		MaxDelay:    p.MaxDelay,
+		Retryable:   IsTransient,
+		Uncounted: func(err error) bool {
+			return p.WaitForUnlock && IsLockedDevice(err)
+		},
+	}, fn)
+}
@@ -0,0 +1,93 @@
+package applesecurity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/common-fate/go-apple-security/localauth"
+)
+
+func TestClassify(t *testing.T) {
+	locked := &OpError{Op: "GetGenericPassword", Service: "svc", Err: ErrInteractionNotAllowed}
+
+	tests := []struct {
+		name                                                 string
+		err                                                  error
+		notFound, locked, userAction, entitlement, transient bool
+	}{
+		{name: "not_found", err: &OpError{Op: "Get", Err: ErrItemNotFound}, notFound: true},
+		{name: "locked", err: locked, locked: true, userAction: true, transient: true},
+		{name: "lockout", err: &CFError{Domain: localauth.Domain, Code: -8, Err: localauth.ErrBiometryLockout}, userAction: true},
+		{name: "entitlement", err: fmt.Errorf("adding: %w", ErrMissingEntitlement), entitlement: true},
+		{name: "other", err: errors.New("other")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.notFound)
+			}
+			if got := IsLockedDevice(tt.err); got != tt.locked {
+				t.Errorf("IsLockedDevice() = %v, want %v", got, tt.locked)
+			}
+			if got := IsUserActionRequired(tt.err); got != tt.userAction {
+				t.Errorf("IsUserActionRequired() = %v, want %v", got, tt.userAction)
+			}
+			if got := IsEntitlementProblem(tt.err); got != tt.entitlement {
+				t.Errorf("IsEntitlementProblem() = %v, want %v", got, tt.entitlement)
+			}
+			if got := IsTransient(tt.err); got != tt.transient {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.transient)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	fast := RetryPolicy{MaxAttempts: 3, Delay: time.Millisecond}
+	locked := &OpError{Op: "GetGenericPassword", Err: ErrInteractionNotAllowed}
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		failures     int
+		failWith     error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "succeeds_after_transient", policy: fast, failures: 2, failWith: locked, wantAttempts: 3},
+		{name: "gives_up", policy: fast, failures: 5, failWith: locked, wantErr: ErrInteractionNotAllowed, wantAttempts: 3},
+		{name: "not_transient", policy: fast, failures: 5, failWith: ErrItemNotFound, wantErr: ErrItemNotFound, wantAttempts: 1},
+		{name: "wait_for_unlock", policy: RetryPolicy{MaxAttempts: 1, Delay: time.Millisecond, MaxDelay: time.Millisecond, WaitForUnlock: true}, failures: 5, failWith: locked, wantAttempts: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			got, err := Retry(context.Background(), tt.policy, func() (int, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return 0, tt.failWith
+				}
+				return 42, nil
+			})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != 42 {
+				t.Errorf("Retry() = %v, want 42", got)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RetryPolicy{WaitForUnlock: true}.Do(ctx, func() error { return locked })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,112 @@
+package applesecurity
+
+import "fmt"
+
+// Synchronizable controls whether keychain items are synchronised
+// to the user's other devices with iCloud Keychain.
+//
+// See: https://developer.apple.com/documentation/security/ksecattrsynchronizable
+type Synchronizable int
+
+const (
+	// SynchronizableUnspecified leaves kSecAttrSynchronizable unset.
+	// New items are not synchronised, and queries only match
+	// items which are not synchronised.
+	SynchronizableUnspecified Synchronizable = iota
+	// SynchronizableYes sets kSecAttrSynchronizable to true.
+	SynchronizableYes
+	// SynchronizableNo sets kSecAttrSynchronizable to false.
+	SynchronizableNo
+	// SynchronizableAny matches both synchronised and local items.
+	// It corresponds to kSecAttrSynchronizableAny and is only valid in queries.
+	SynchronizableAny
+)
+
+func (s Synchronizable) String() string {
+	switch s {
+	case SynchronizableUnspecified:
+		return "unspecified"
+	case SynchronizableYes:
+		return "yes"
+	case SynchronizableNo:
+		return "no"
+	case SynchronizableAny:
+		return "any"
+	}
+	return fmt.Sprintf("Synchronizable(%d)", int(s))
+}
+
+// Keychain selects which macOS keychain items are stored in.
+type Keychain int
+
+const (
+	// DataProtectionKeychain is the iOS-style keychain, selected with
+	// kSecUseDataProtectionKeychain. It supports access groups and
+	// iCloud Keychain, but the binary must be codesigned with
+	// keychain entitlements or operations fail with [ErrMissingEntitlement].
+	DataProtectionKeychain Keychain = iota
+	// LegacyKeychain is the file-based login keychain. It can be used
+	// by unsigned binaries, but has no access groups or iCloud sync.
+	// Items are protected by access control lists which trust the
+	// binary that created them: other binaries, including rebuilds
+	// of an unsigned binary, cause the user to be prompted for access.
+	LegacyKeychain
+	// AutomaticKeychain uses the data protection keychain if
+	// [DataProtectionKeychainAvailable] reports it is usable,
+	// and the legacy keychain otherwise.
+	AutomaticKeychain
+)
+
+func (k Keychain) String() string {
+	switch k {
+	case DataProtectionKeychain:
+		return "data protection"
+	case LegacyKeychain:
+		return "legacy"
+	case AutomaticKeychain:
+		return "automatic"
+	}
+	return fmt.Sprintf("Keychain(%d)", int(k))
+}
+
+// Resolve returns the keychain used for k, which is either
+// DataProtectionKeychain or LegacyKeychain.
+func (k Keychain) Resolve() Keychain {
+	if k != AutomaticKeychain {
+		return k
+	}
+	if DataProtectionKeychainAvailable() {
+		return DataProtectionKeychain
+	}
+	return LegacyKeychain
+}
+
+// Scope selects the keychain and access group of items, and whether
+// they are synchronised with iCloud Keychain. It is embedded in the
+// inputs of operations which add or query items.
+//
+// The zero value uses the data protection keychain with the default
+// access group of the application, which is the first group in its
+// keychain-access-groups entitlement, and does not synchronise.
+// Queries with an empty AccessGroup search every group the application
+// has access to.
+//
+// Separate binaries, such as a CLI and its helper daemon, share items
+// by being signed with a common access group and using a Scope naming it:
+//
+//	shared := applesecurity.Scope{AccessGroup: "TEAMID.com.example.shared"}
+//	keychain.AddGenericPassword(keychain.GenericPassword{Scope: shared, ...})
+type Scope struct {
+	// Keychain selects the keychain to use. Unsigned tools should
+	// use AutomaticKeychain so that they work without entitlements.
+	Keychain Keychain
+
+	// AccessGroup corresponds to kSecAttrAccessGroup. It must be
+	// listed in the keychain-access-groups entitlement of the binary.
+	// It is not supported by the legacy keychain.
+	//
+	// See: https://developer.apple.com/documentation/security/sharing-access-to-keychain-items-among-a-collection-of-apps
+	AccessGroup string
+
+	Synchronizable Synchronizable
+}
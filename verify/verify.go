@@ -0,0 +1,127 @@
+// Package verify checks signatures made by the keys of this module,
+// such as enclavekey.Key and keychainkey.Key, against their public keys.
+//
+// On macOS it uses SecKeyVerifySignature. Elsewhere, such as on servers
+// receiving signatures from a Mac, it is implemented in pure Go.
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/ecdsasig"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+// ErrInvalidSignature is returned when a signature is not valid.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Verify checks that sig is a signature of digest by pub.
+//
+// ECDSA signatures may be ASN.1 DER or IEEE P1363 encoded. RSA signatures
+// use PKCS #1 v1.5 padding, or PSS when opts is a *rsa.PSSOptions.
+//
+// The hash function is taken from opts. If opts is nil it is inferred
+// from the length of digest, which must be a SHA-256, SHA-384 or
+// SHA-512 hash.
+func Verify(pub crypto.PublicKey, digest, sig []byte, opts crypto.SignerOpts) error {
+	hash, err := hashFunc(digest, opts)
+	if err != nil {
+		return err
+	}
+	pssOpts, pss := opts.(*rsa.PSSOptions)
+
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if pss {
+			return errors.New("RSA PSS options cannot be used with an ECDSA key")
+		}
+		candidates, err := derCandidates(sig, k)
+		if err != nil {
+			return err
+		}
+		for _, der := range candidates {
+			if err = verifyDER(pub, hash, false, digest, der); err == nil {
+				return nil
+			}
+		}
+		return err
+
+	case *rsa.PublicKey:
+		// The Security framework always uses a salt as long as the hash.
+		if pss && pssOpts.SaltLength != rsa.PSSSaltLengthAuto && pssOpts.SaltLength != rsa.PSSSaltLengthEqualsHash && pssOpts.SaltLength != hash.Size() {
+			return fmt.Errorf("unsupported PSS salt length %d", pssOpts.SaltLength)
+		}
+		return verifyDER(pub, hash, pss, digest, sig)
+	}
+	return fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// VerifyMessage hashes message with hash and checks that
+// sig is a signature of it by pub, as Verify does.
+func VerifyMessage(pub crypto.PublicKey, message, sig []byte, hash crypto.Hash) error {
+	if !hash.Available() {
+		return fmt.Errorf("hash function %v is not available", hash)
+	}
+	h := hash.New()
+	h.Write(message)
+	return Verify(pub, h.Sum(nil), sig, hash)
+}
+
+// VerifyEncoded parses an exported public key in any format supported
+// by pubkey.Parse, such as PEM, a JWK, an OpenSSH authorized_keys line
+// or an X9.63 point, and checks sig with it as Verify does.
+func VerifyEncoded(publicKey, digest, sig []byte, opts crypto.SignerOpts) error {
+	pub, err := pubkey.Parse(publicKey)
+	if err != nil {
+		return err
+	}
+	return Verify(pub, digest, sig, opts)
+}
+
+// hashFunc returns the hash function of opts, or the one
+// producing digests of the length of digest if opts is nil.
+func hashFunc(digest []byte, opts crypto.SignerOpts) (crypto.Hash, error) {
+	if opts == nil {
+		switch len(digest) {
+		case crypto.SHA256.Size():
+			return crypto.SHA256, nil
+		case crypto.SHA384.Size():
+			return crypto.SHA384, nil
+		case crypto.SHA512.Size():
+			return crypto.SHA512, nil
+		}
+		return 0, fmt.Errorf("cannot infer the hash function of a %d byte digest", len(digest))
+	}
+
+	hash := opts.HashFunc()
+	switch hash {
+	case crypto.SHA1, crypto.SHA224, crypto.SHA256, crypto.SHA384, crypto.SHA512:
+	default:
+		return 0, fmt.Errorf("unsupported hash function %v", hash)
+	}
+	if len(digest) != hash.Size() {
+		return 0, fmt.Errorf("digest length %d does not match hash function %v", len(digest), hash)
+	}
+	return hash, nil
+}
+
+// derCandidates returns the DER encodings sig may represent: itself if
+// it is DER, and its conversion if it has the length of a P1363 signature.
+// A P1363 signature could, rarely, also be valid DER, so both are tried.
+func derCandidates(sig []byte, pub *ecdsa.PublicKey) ([][]byte, error) {
+	var candidates [][]byte
+	if _, _, err := ecdsasig.ParseDER(sig, pub.Curve); err == nil {
+		candidates = append(candidates, sig)
+	}
+	if der, err := ecdsasig.ToDER(sig, pub.Curve, nil); err == nil {
+		candidates = append(candidates, der)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: not a DER or P1363 encoded %s signature", ErrInvalidSignature, pub.Curve.Params().Name)
+	}
+	return candidates, nil
+}
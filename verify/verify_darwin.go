@@ -0,0 +1,140 @@
+//go:build darwin && cgo
+
+package verify
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework Security
+
+#include <CoreFoundation/CoreFoundation.h>
+#include <Security/Security.h>
+*/
+import "C"
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/common-fate/go-apple-security/corefoundation"
+	"github.com/common-fate/go-apple-security/internal/keyrep"
+	"github.com/common-fate/go-apple-security/internal/secerror"
+)
+
+const nilSecKey C.SecKeyRef = 0
+
+// verifyDER checks a DER encoded ECDSA or an RSA signature
+// with SecKeyVerifySignature.
+func verifyDER(pub crypto.PublicKey, hash crypto.Hash, pss bool, digest, sig []byte) error {
+	keyData, keyType, err := keyrep.MarshalPublicKey(pub)
+	if err != nil {
+		return err
+	}
+
+	algorithm, err := signatureAlgorithm(keyType, hash, pss)
+	if err != nil {
+		return err
+	}
+
+	secType := C.kSecAttrKeyTypeRSA
+	if keyType == keyrep.EC {
+		secType = C.kSecAttrKeyTypeECSECPrimeRandom
+	}
+
+	cfKeyData, err := corefoundation.NewCFData(keyData)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfKeyData))
+
+	keyAttrs, err := corefoundation.NewCFDictionary(corefoundation.Dictionary{
+		corefoundation.TypeRef(C.kSecAttrKeyType):  corefoundation.TypeRef(secType),
+		corefoundation.TypeRef(C.kSecAttrKeyClass): corefoundation.TypeRef(C.kSecAttrKeyClassPublic),
+	})
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(keyAttrs))
+
+	var eref C.CFErrorRef
+	secKey := C.SecKeyCreateWithData(C.CFDataRef(cfKeyData), C.CFDictionaryRef(keyAttrs), &eref)
+	if eref != 0 {
+		defer C.CFRelease(C.CFTypeRef(eref))
+		return secerror.FromCFError(corefoundation.ErrorRef(eref))
+	}
+	if secKey == nilSecKey {
+		return errors.New("error creating public key")
+	}
+	defer C.CFRelease(C.CFTypeRef(secKey))
+
+	cfDigest, err := corefoundation.NewCFData(digest)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfDigest))
+
+	cfSig, err := corefoundation.NewCFData(sig)
+	if err != nil {
+		return err
+	}
+	defer C.CFRelease(C.CFTypeRef(cfSig))
+
+	if C.SecKeyVerifySignature(secKey, algorithm, C.CFDataRef(cfDigest), C.CFDataRef(cfSig), &eref) != 0 {
+		return nil
+	}
+	if eref != 0 {
+		defer C.CFRelease(C.CFTypeRef(eref))
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, secerror.FromCFError(corefoundation.ErrorRef(eref)))
+	}
+	return ErrInvalidSignature
+}
+
+// signatureAlgorithm returns the SecKeyAlgorithm for verifying a digest.
+func signatureAlgorithm(keyType keyrep.KeyType, hash crypto.Hash, pss bool) (C.SecKeyAlgorithm, error) {
+	var algorithm C.SecKeyAlgorithm
+
+	switch {
+	case keyType == keyrep.EC:
+		switch hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmECDSASignatureDigestX962SHA512, nil
+		}
+
+	case pss:
+		switch hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmRSASignatureDigestPSSSHA512, nil
+		}
+
+	default:
+		switch hash {
+		case crypto.SHA1:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA1, nil
+		case crypto.SHA224:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA224, nil
+		case crypto.SHA256:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA256, nil
+		case crypto.SHA384:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA384, nil
+		case crypto.SHA512:
+			return C.kSecKeyAlgorithmRSASignatureDigestPKCS1v15SHA512, nil
+		}
+	}
+
+	return algorithm, fmt.Errorf("unsupported hash function %v for %v keys", hash, keyType)
+}
@@ -0,0 +1,34 @@
+//go:build !darwin || !cgo
+
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+)
+
+// verifyDER checks a DER encoded ECDSA or an RSA signature in pure Go.
+func verifyDER(pub crypto.PublicKey, hash crypto.Hash, pss bool, digest, sig []byte) error {
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		var err error
+		if pss {
+			err = rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(k, hash, digest, sig)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported public key type %T", pub)
+}
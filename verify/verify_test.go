@@ -0,0 +1,130 @@
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/common-fate/go-apple-security/ecdsasig"
+	"github.com/common-fate/go-apple-security/pubkey"
+)
+
+func TestVerify(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest256 := sha256.Sum256([]byte("hello"))
+	digest384 := sha512.Sum384([]byte("hello"))
+
+	der256, err := ecdsa.SignASN1(rand.Reader, p256, digest256[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1363, err := ecdsasig.ToP1363(der256, elliptic.P256(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der384, err := ecdsa.SignASN1(rand.Reader, p384, digest384[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest256[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	pss, err := rsa.SignPSS(rand.Reader, rsaKey, crypto.SHA256, digest256[:], pssOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte{}, der256...)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		pub     crypto.PublicKey
+		digest  []byte
+		sig     []byte
+		opts    crypto.SignerOpts
+		wantErr bool
+	}{
+		{name: "ecdsa_der", pub: &p256.PublicKey, digest: digest256[:], sig: der256, opts: crypto.SHA256},
+		{name: "ecdsa_der_nil_opts", pub: &p256.PublicKey, digest: digest256[:], sig: der256},
+		{name: "ecdsa_p1363", pub: &p256.PublicKey, digest: digest256[:], sig: p1363, opts: crypto.SHA256},
+		{name: "ecdsa_p384", pub: &p384.PublicKey, digest: digest384[:], sig: der384},
+		{name: "rsa_pkcs1", pub: &rsaKey.PublicKey, digest: digest256[:], sig: pkcs1, opts: crypto.SHA256},
+		{name: "rsa_pss", pub: &rsaKey.PublicKey, digest: digest256[:], sig: pss, opts: pssOpts},
+		{name: "tampered", pub: &p256.PublicKey, digest: digest256[:], sig: tampered, wantErr: true},
+		{name: "wrong_key", pub: &p384.PublicKey, digest: digest256[:], sig: der256, wantErr: true},
+		{name: "wrong_digest", pub: &p256.PublicKey, digest: digest384[:32], sig: der256, wantErr: true},
+		{name: "hash_mismatch", pub: &p256.PublicKey, digest: digest256[:], sig: der256, opts: crypto.SHA384, wantErr: true},
+		{name: "garbage", pub: &p256.PublicKey, digest: digest256[:], sig: []byte("garbage"), wantErr: true},
+		{name: "rsa_pss_as_pkcs1", pub: &rsaKey.PublicKey, digest: digest256[:], sig: pss, opts: crypto.SHA256, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.pub, tt.digest, tt.sig, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Verify(&p256.PublicKey, digest256[:], tampered, nil); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() of a tampered signature error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyEncoded(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("hello")
+	digest := sha256.Sum256(message)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodings := map[string]func(crypto.PublicKey) ([]byte, error){
+		"pem": pubkey.MarshalPEM,
+		"jwk": pubkey.MarshalJWK,
+		"ssh": func(pub crypto.PublicKey) ([]byte, error) { return pubkey.MarshalAuthorizedKey(pub, "") },
+		"x963": func(pub crypto.PublicKey) ([]byte, error) {
+			return pubkey.MarshalX963(pub.(*ecdsa.PublicKey))
+		},
+	}
+	for name, marshal := range encodings {
+		t.Run(name, func(t *testing.T) {
+			data, err := marshal(&key.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyEncoded(data, digest[:], sig, nil); err != nil {
+				t.Errorf("VerifyEncoded() error = %v", err)
+			}
+		})
+	}
+
+	if err := VerifyMessage(&key.PublicKey, message, sig, crypto.SHA256); err != nil {
+		t.Errorf("VerifyMessage() error = %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package x963kdf implements the ANSI X9.63 key derivation function,
+// as used by the kSecKeyAlgorithmECDHKeyExchange*X963SHA* algorithms of
+// the Security framework and SEC 1 ECIES.
+//
+// It is pure Go, so keys derived by a Secure Enclave key can be
+// derived by the other party on any platform.
+package x963kdf
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Key derives a key of length bytes from the shared secret and the
+// optional sharedInfo: the concatenation of hash(secret || counter ||
+// sharedInfo) for a 32-bit big-endian counter starting at 1.
+func Key(hash crypto.Hash, secret, sharedInfo []byte, length int) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", hash)
+	}
+	if length < 0 {
+		return nil, errors.New("negative key length")
+	}
+	if uint64(length) > uint64(hash.Size())*(1<<32-1) {
+		return nil, fmt.Errorf("key length %d is too long for %v", length, hash)
+	}
+
+	h := hash.New()
+	key := make([]byte, 0, length+hash.Size())
+	var counter [4]byte
+	for i := uint32(1); len(key) < length; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Reset()
+		h.Write(secret)
+		h.Write(counter[:])
+		h.Write(sharedInfo)
+		key = h.Sum(key)
+	}
+	return key[:length], nil
+}
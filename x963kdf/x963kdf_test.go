@@ -0,0 +1,102 @@
+package x963kdf
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	// from the NIST CAVS 12.0 ANSI X9.63 KDF test vectors.
+	tests := []struct {
+		name       string
+		hash       crypto.Hash
+		secret     string
+		sharedInfo string
+		want       string
+	}{
+		{
+			name:   "sha256_no_shared_info",
+			hash:   crypto.SHA256,
+			secret: "96c05619d56c328ab95fe84b18264b08725b85e33fd34f08",
+			want:   "443024c3dae66b95e6f5670601558f71",
+		},
+		{
+			name:       "sha256_shared_info",
+			hash:       crypto.SHA256,
+			secret:     "22518b10e70f2a3f243810ae3254139efbee04aa57c7af7d",
+			sharedInfo: "75eef81aa3041e33b80971203d2c0c52",
+			want:       "c498af77161cc59f2962b9a713e2b215152d139766ce34a776df11866a69bf2e52a13d9c7c6fc878c50c5ea0bc7b00e0da2447cfd874f6cf92f30d0097111485500c90c3af8b487872d04685d14c8d1dc8d7fa08beb0ce0ababc11f0bd496269142d43525a78e5bc79a17f59676a5706dc54d54d4d1f0bd7e386128ec26afc21",
+		},
+		{
+			name:   "sha384_no_shared_info",
+			hash:   crypto.SHA384,
+			secret: "d8554db1b392cd55c3fe957bed76af09c13ac2a9392f88f6",
+			want:   "671a46aada145162f8ddf1ca586a1cda",
+		},
+		{
+			name:   "sha512_no_shared_info",
+			hash:   crypto.SHA512,
+			secret: "87fc0d8c4477485bb574f5fcea264b30885dc8d90ad82782",
+			want:   "947665fbb9152153ef460238506a0245",
+		},
+		{
+			name:       "sha512_shared_info",
+			hash:       crypto.SHA512,
+			secret:     "00aa5bb79b33e389fa58ceadc047197f14e73712f452caa9fc4c9adb369348b81507392f1a86ddfdb7c4ff8231c4bd0f44e44a1b55b1404747a9e2e753f55ef05a2d",
+			sharedInfo: "e3b5b4c1b0d5cf1d2b3a2f9937895d31",
+			want:       "4463f869f3cc18769b52264b0112b5858f7ad32a5a2d96d8cffabf7fa733633d6e4dd2a599acceb3ea54a6217ce0b50eef4f6b40a5c30250a5a8eeee208002267089dbf351f3f5022aa9638bf1ee419dea9c4ff745a25ac27bda33ca08bd56dd1a59b4106cf2dbbc0ab2aa8e2efa7b17902d34276951ceccab87f9661c3e8816",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, _ := hex.DecodeString(tt.secret)
+			sharedInfo, _ := hex.DecodeString(tt.sharedInfo)
+			want, _ := hex.DecodeString(tt.want)
+
+			got, err := Key(tt.hash, secret, sharedInfo, len(want))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Key() = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+func TestKey_Blocks(t *testing.T) {
+	secret := []byte("secret")
+	sharedInfo := []byte("info")
+
+	long, err := Key(crypto.SHA256, secret, sharedInfo, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := Key(crypto.SHA256, secret, sharedInfo, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(long[:20], short) {
+		t.Errorf("shorter keys are not a prefix of longer ones")
+	}
+
+	// the third block is hashed with the counter 3.
+	h := crypto.SHA256.New()
+	h.Write(secret)
+	h.Write([]byte{0, 0, 0, 3})
+	h.Write(sharedInfo)
+	if want := h.Sum(nil)[:16]; !bytes.Equal(long[64:], want) {
+		t.Errorf("third block = %x, want %x", long[64:], want)
+	}
+
+	if _, err := Key(crypto.SHA256, secret, nil, -1); err == nil {
+		t.Errorf("Key() with a negative length succeeded")
+	}
+	if _, err := Key(crypto.MD4, secret, nil, 16); err == nil {
+		t.Errorf("Key() with an unavailable hash succeeded")
+	}
+}